@@ -0,0 +1,200 @@
+// Package category: カテゴリの木構造と、自由入力のカテゴリ名を既存のカテゴリに対応付ける処理
+package category
+
+import (
+	_ "embed"
+	"encoding/json"
+	"hackathon-backend/model"
+	"hackathon-backend/recommend"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed seed.json
+var seedJSON []byte
+
+// FallbackSlug: どのカテゴリにも近くない場合のカテゴリ
+const FallbackSlug = "other"
+
+// matchThreshold: これ未満の類似度では対応付けない
+const matchThreshold = 0.34
+
+// Seed: 初期カテゴリ（seed.json の順に SortOrder を振る）
+func Seed() ([]*model.Category, error) {
+	var list []*model.Category
+	if err := json.Unmarshal(seedJSON, &list); err != nil {
+		return nil, err
+	}
+	for i, c := range list {
+		c.SortOrder = i
+	}
+	return list, nil
+}
+
+// Tree: カテゴリの木
+type Tree struct {
+	bySlug map[string]*model.Category
+	roots  []*model.Category
+}
+
+// NewTree: 一覧から木を作る（親が見つからない・循環しているカテゴリは最上位として扱う）
+// 渡したカテゴリは変更しない
+func NewTree(list []*model.Category) *Tree {
+	t := &Tree{bySlug: map[string]*model.Category{}}
+	for _, c := range list {
+		copied := *c
+		copied.Children = nil
+		t.bySlug[c.Slug] = &copied
+	}
+	for _, c := range sortedCategories(t.bySlug) {
+		parent := t.bySlug[c.ParentSlug]
+		if parent == nil || t.isAncestor(c.Slug, parent.Slug) {
+			c.ParentSlug = ""
+			t.roots = append(t.roots, c)
+			continue
+		}
+		parent.Children = append(parent.Children, c)
+	}
+	return t
+}
+
+func sortedCategories(m map[string]*model.Category) []*model.Category {
+	list := make([]*model.Category, 0, len(m))
+	for _, c := range m {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].SortOrder != list[j].SortOrder {
+			return list[i].SortOrder < list[j].SortOrder
+		}
+		return list[i].Slug < list[j].Slug
+	})
+	return list
+}
+
+// isAncestor: slug が other の祖先（または同じ）か（親をたどって循環を見つける）
+func (t *Tree) isAncestor(slug, other string) bool {
+	seen := map[string]bool{}
+	for cur := other; cur != "" && !seen[cur]; {
+		if cur == slug {
+			return true
+		}
+		seen[cur] = true
+		c := t.bySlug[cur]
+		if c == nil {
+			return false
+		}
+		cur = c.ParentSlug
+	}
+	return false
+}
+
+// Roots: 最上位のカテゴリ（Children に子孫を含む）
+func (t *Tree) Roots() []*model.Category {
+	return t.roots
+}
+
+// Get: slug のカテゴリ（なければ nil）
+func (t *Tree) Get(slug string) *model.Category {
+	return t.bySlug[slug]
+}
+
+// Descendants: slug とその子孫の slug（slug がなければ空）
+func (t *Tree) Descendants(slug string) []string {
+	root := t.bySlug[slug]
+	if root == nil {
+		return nil
+	}
+	var slugs []string
+	var walk func(c *model.Category)
+	walk = func(c *model.Category) {
+		slugs = append(slugs, c.Slug)
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return slugs
+}
+
+// Path: 最上位から slug までのカテゴリ
+func (t *Tree) Path(slug string) []*model.Category {
+	var path []*model.Category
+	for c := t.bySlug[slug]; c != nil; c = t.bySlug[c.ParentSlug] {
+		path = append([]*model.Category{c}, path...)
+		if c.ParentSlug == "" {
+			break
+		}
+	}
+	return path
+}
+
+// Match: 自由入力のカテゴリ名（AI の提案など）に最も近いカテゴリの slug
+// slug・名前・別名の完全一致を優先し、なければ文字 bigram の類似度で選ぶ（同点なら深いカテゴリ）
+// "家電 > スマホ" のような階層表記は、最後の段から順に試す
+func (t *Tree) Match(text string) string {
+	if t.bySlug[text] != nil {
+		return text
+	}
+	parts := strings.FieldsFunc(text, func(r rune) bool { return strings.ContainsRune(">/／＞|", r) })
+	for i := len(parts) - 1; i >= 0; i-- {
+		if slug := t.exactMatch(normalize(parts[i])); slug != "" {
+			return slug
+		}
+	}
+
+	query := normalize(text)
+	best, bestScore, bestDepth := "", 0.0, -1
+	for _, c := range sortedCategories(t.bySlug) {
+		score := 0.0
+		for _, name := range candidates(c) {
+			if s := recommend.TextSimilarity(query, normalize(name)); s > score {
+				score = s
+			}
+		}
+		depth := len(t.Path(c.Slug))
+		if score > bestScore || (score == bestScore && score > 0 && depth > bestDepth) {
+			best, bestScore, bestDepth = c.Slug, score, depth
+		}
+	}
+	if bestScore < matchThreshold {
+		if t.bySlug[FallbackSlug] != nil {
+			return FallbackSlug
+		}
+		return ""
+	}
+	return best
+}
+
+func (t *Tree) exactMatch(query string) string {
+	if query == "" {
+		return ""
+	}
+	for _, c := range sortedCategories(t.bySlug) {
+		for _, name := range candidates(c) {
+			if normalize(name) == query {
+				return c.Slug
+			}
+		}
+	}
+	return ""
+}
+
+func candidates(c *model.Category) []string {
+	return append([]string{c.Slug, c.NameJa, c.NameEn}, c.Aliases...)
+}
+
+// normalize: 大文字小文字・全角英数・空白と記号の違いを無視する
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'Ａ' && r <= 'ｚ' || r >= '０' && r <= '９' {
+			r = unicode.ToLower(r - 0xFEE0)
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package category
+
+import (
+	"hackathon-backend/model"
+	"reflect"
+	"testing"
+)
+
+func seedTree(t *testing.T) *Tree {
+	t.Helper()
+	list, err := Seed()
+	if err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	for _, c := range list {
+		if err := c.Validate(); err != nil {
+			t.Fatalf("seed %s: %v", c.Slug, err)
+		}
+	}
+	return NewTree(list)
+}
+
+// TestMatch は AI のカテゴリ提案を既存のカテゴリに対応付けるテスト
+func TestMatch(t *testing.T) {
+	tree := seedTree(t)
+
+	testCases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "slug そのもの", text: "lenses", want: "lenses"},
+		{name: "日本語名の完全一致", text: "カメラ", want: "cameras"},
+		{name: "英語名は大文字小文字を区別しない", text: "power tools", want: "power-tools"},
+		{name: "別名", text: "スマホ", want: "smartphones"},
+		{name: "階層表記は最後の段を使う", text: "家電 > スマートフォン・携帯電話", want: "smartphones"},
+		{name: "全角英字", text: "ｉＰｈｏｎｅ", want: "smartphones"},
+		{name: "部分的に近い名前", text: "ミラーレス一眼カメラ", want: "cameras"},
+		{name: "近いものがなければ その他", text: "宇宙船", want: "other"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tree.Match(tc.text); got != tc.want {
+				t.Errorf("Match(%q) = %s, want %s", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestDescendants は子孫を含む絞り込みと、壊れた親子関係の扱いのテスト
+func TestDescendants(t *testing.T) {
+	tree := seedTree(t)
+	if got, want := tree.Descendants("cameras"), []string{"cameras", "lenses"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Descendants(cameras) = %v, want %v", got, want)
+	}
+	if got := tree.Descendants("electronics"); len(got) != 9 {
+		t.Errorf("Descendants(electronics) = %v, want 9 categories", got)
+	}
+	if got := tree.Descendants("unknown"); got != nil {
+		t.Errorf("Descendants(unknown) = %v, want nil", got)
+	}
+
+	cyclic := NewTree([]*model.Category{
+		{Slug: "a", ParentSlug: "b", SortOrder: 0},
+		{Slug: "b", ParentSlug: "a", SortOrder: 1},
+		{Slug: "c", ParentSlug: "missing", SortOrder: 2},
+	})
+	if roots := cyclic.Roots(); len(roots) != 2 || roots[0].Slug != "a" || roots[1].Slug != "c" {
+		t.Errorf("循環・親なしのカテゴリが最上位にならない: %v", roots)
+	}
+	if got := cyclic.Path("b"); len(got) != 2 {
+		t.Errorf("Path(b) = %v, want [a b]", got)
+	}
+}
@@ -0,0 +1,107 @@
+// Package chathub はチャットのイベントを購読者に配信する
+//
+// 配信はサーバー内の Hub が行い、サーバー間の中継は Backend に任せる。
+// 1台なら LocalBackend、複数台なら共有 DB をポーリングする SQLBackend を使う。
+package chathub
+
+import (
+	"context"
+	"hackathon-backend/model"
+	"sync"
+)
+
+// subscriberBuffer: 購読者ごとに溜めておけるイベント数（溢れたら購読を切り、再接続で追いつかせる）
+const subscriberBuffer = 64
+
+// Backend: サーバー間でイベントを中継する仕組み
+type Backend interface {
+	// Publish: イベントをすべてのサーバーに送る
+	Publish(ev model.ChatEvent) error
+	// Run: ctx が終わるまで、届いたイベントを deliver に渡し続ける
+	Run(ctx context.Context, deliver func(model.ChatEvent)) error
+}
+
+// Hub: トピック（チャット）ごとの購読者にイベントを配信する
+type Hub struct {
+	backend Backend
+
+	mu   sync.Mutex
+	subs map[string]map[*Subscription]struct{}
+}
+
+func NewHub(backend Backend) *Hub {
+	return &Hub{backend: backend, subs: map[string]map[*Subscription]struct{}{}}
+}
+
+// Run: バックエンドからイベントを受け取り、購読者に配信する
+func (h *Hub) Run(ctx context.Context) error {
+	return h.backend.Run(ctx, h.deliver)
+}
+
+// Publish: イベントを配信する（自分のサーバーの購読者にもバックエンド経由で届く）
+func (h *Hub) Publish(ev model.ChatEvent) error {
+	return h.backend.Publish(ev)
+}
+
+// Subscribe: topic のイベントを購読する（使い終わったら Close する）
+func (h *Hub) Subscribe(topic string) *Subscription {
+	s := &Subscription{hub: h, topic: topic, ch: make(chan model.ChatEvent, subscriberBuffer)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = map[*Subscription]struct{}{}
+	}
+	h.subs[topic][s] = struct{}{}
+	return s
+}
+
+// Subscribers: topic の購読者数
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[topic])
+}
+
+func (h *Hub) deliver(ev model.ChatEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs[ev.Topic] {
+		select {
+		case s.ch <- ev:
+		default:
+			// 受け取りが追いつかない購読者は切り、再接続時に Last-Event-ID から取り直させる
+			h.remove(s)
+		}
+	}
+}
+
+// remove: 購読を外してチャネルを閉じる（h.mu を持った状態で呼ぶ）
+func (h *Hub) remove(s *Subscription) {
+	if _, ok := h.subs[s.topic][s]; !ok {
+		return
+	}
+	delete(h.subs[s.topic], s)
+	if len(h.subs[s.topic]) == 0 {
+		delete(h.subs, s.topic)
+	}
+	close(s.ch)
+}
+
+// Subscription: 1つのトピックの購読
+type Subscription struct {
+	hub   *Hub
+	topic string
+	ch    chan model.ChatEvent
+}
+
+// Events: 届いたイベント（購読が切られたら閉じる）
+func (s *Subscription) Events() <-chan model.ChatEvent {
+	return s.ch
+}
+
+// Close: 購読をやめる
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
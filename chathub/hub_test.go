@@ -0,0 +1,117 @@
+package chathub
+
+import (
+	"context"
+	"hackathon-backend/model"
+	"testing"
+	"time"
+)
+
+// TestHub は購読しているトピックにだけ配信し、閉じた購読を外すことを確認する
+func TestHub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := NewHub(NewLocalBackend())
+	go hub.Run(ctx)
+
+	a := hub.Subscribe("item1")
+	b := hub.Subscribe("item2")
+	defer b.Close()
+
+	if err := hub.Publish(model.ChatEvent{Topic: "item1", ID: "m1", Type: model.ChatEventMessage}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-a.Events():
+		if ev.ID != "m1" {
+			t.Errorf("got event %q, want m1", ev.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+	select {
+	case ev := <-b.Events():
+		t.Errorf("other topic received %q", ev.ID)
+	default:
+	}
+
+	a.Close()
+	a.Close() // 2回閉じても問題ない
+	if n := hub.Subscribers("item1"); n != 0 {
+		t.Errorf("Subscribers() = %d after Close, want 0", n)
+	}
+}
+
+// TestHub_SlowSubscriberIsDropped は受け取りが追いつかない購読を切ることを確認する
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub(NewLocalBackend())
+	s := hub.Subscribe("item1")
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.deliver(model.ChatEvent{Topic: "item1"})
+	}
+
+	n := 0
+	for range s.Events() {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d events before close, want %d", n, subscriberBuffer)
+	}
+	if hub.Subscribers("item1") != 0 {
+		t.Error("slow subscriber was not removed")
+	}
+}
+
+// fakeLog: メモリ上のイベントログ
+type fakeLog struct {
+	events []model.ChatEvent
+}
+
+func (l *fakeLog) Append(ev model.ChatEvent) error {
+	ev.Seq = int64(len(l.events) + 1)
+	l.events = append(l.events, ev)
+	return nil
+}
+
+func (l *fakeLog) LastSeq() (int64, error) { return int64(len(l.events)), nil }
+
+func (l *fakeLog) After(seq int64, limit int) ([]model.ChatEvent, error) {
+	var out []model.ChatEvent
+	for _, ev := range l.events {
+		if ev.Seq > seq && len(out) < limit {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (l *fakeLog) DeleteBefore(time.Time) (int64, error) { return 0, nil }
+
+// TestSQLBackend_Poll は連番の抜けをしばらく待ち、順番どおりに配信することを確認する
+func TestSQLBackend_Poll(t *testing.T) {
+	log := &fakeLog{events: []model.ChatEvent{{Seq: 1, ID: "a"}, {Seq: 2, ID: "b"}, {Seq: 4, ID: "d"}}}
+	b := NewSQLBackend(log, time.Millisecond, time.Hour)
+
+	var got []string
+	deliver := func(ev model.ChatEvent) { got = append(got, ev.ID) }
+
+	// 3 が抜けているので、4 はまだ配信しない
+	seq, gapSince := b.poll(0, time.Time{}, deliver)
+	if seq != 2 || gapSince.IsZero() || len(got) != 2 {
+		t.Fatalf("poll() = seq %d, gap %v, delivered %v", seq, gapSince, got)
+	}
+
+	// 待っている間に 3 がコミットされた
+	log.events = []model.ChatEvent{{Seq: 1, ID: "a"}, {Seq: 2, ID: "b"}, {Seq: 3, ID: "c"}, {Seq: 4, ID: "d"}}
+	seq, gapSince = b.poll(seq, gapSince, deliver)
+	if seq != 4 || !gapSince.IsZero() || len(got) != 4 || got[2] != "c" {
+		t.Fatalf("poll() = seq %d, gap %v, delivered %v", seq, gapSince, got)
+	}
+
+	// 抜けが gapWait より長く続いたら飛ばす
+	log.events = append(log.events, model.ChatEvent{Seq: 6, ID: "f"})
+	seq, _ = b.poll(seq, time.Now().Add(-gapWait), deliver)
+	if seq != 6 || got[len(got)-1] != "f" {
+		t.Fatalf("poll() = seq %d, delivered %v", seq, got)
+	}
+}
@@ -0,0 +1,31 @@
+package chathub
+
+import (
+	"context"
+	"hackathon-backend/model"
+)
+
+// LocalBackend: サーバー1台の中だけで配信する
+type LocalBackend struct {
+	ch chan model.ChatEvent
+}
+
+func NewLocalBackend() *LocalBackend {
+	return &LocalBackend{ch: make(chan model.ChatEvent, 256)}
+}
+
+func (b *LocalBackend) Publish(ev model.ChatEvent) error {
+	b.ch <- ev
+	return nil
+}
+
+func (b *LocalBackend) Run(ctx context.Context, deliver func(model.ChatEvent)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev := <-b.ch:
+			deliver(ev)
+		}
+	}
+}
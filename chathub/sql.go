@@ -0,0 +1,96 @@
+package chathub
+
+import (
+	"context"
+	"hackathon-backend/model"
+	"log"
+	"time"
+)
+
+// EventLog: サーバー間で共有するイベントの記録（dao.ChatEventDAO）
+type EventLog interface {
+	Append(ev model.ChatEvent) error
+	// LastSeq: 記録済みの最後の通し番号
+	LastSeq() (int64, error)
+	// After: seq より後のイベントを通し番号の順に最大 limit 件
+	After(seq int64, limit int) ([]model.ChatEvent, error)
+	// DeleteBefore: t より前に記録したイベントを消す
+	DeleteBefore(t time.Time) (int64, error)
+}
+
+// gapWait: 通し番号の抜けを、後からコミットされるのを待つ時間（過ぎたらロールバックされた番号とみなす）
+const gapWait = 2 * time.Second
+
+// SQLBackend: 共有 DB のイベントログをポーリングして、複数サーバーに配信する
+type SQLBackend struct {
+	Log EventLog
+	// Interval: ポーリング間隔
+	Interval time.Duration
+	// Retention: イベントログに残しておく期間
+	Retention time.Duration
+}
+
+func NewSQLBackend(eventLog EventLog, interval, retention time.Duration) *SQLBackend {
+	return &SQLBackend{Log: eventLog, Interval: interval, Retention: retention}
+}
+
+func (b *SQLBackend) Publish(ev model.ChatEvent) error {
+	return b.Log.Append(ev)
+}
+
+// Run: 起動時点より後のイベントを配信する（それ以前の分は再接続時に DB から取り直す）
+func (b *SQLBackend) Run(ctx context.Context, deliver func(model.ChatEvent)) error {
+	seq, err := b.Log.LastSeq()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(b.Interval)
+	defer ticker.Stop()
+	lastCleanup := time.Now()
+	var gapSince time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		seq, gapSince = b.poll(seq, gapSince, deliver)
+
+		if time.Since(lastCleanup) > b.Retention {
+			if _, err := b.Log.DeleteBefore(time.Now().Add(-b.Retention)); err != nil {
+				log.Printf("fail: clean up chat events, %v\n", err)
+			}
+			lastCleanup = time.Now()
+		}
+	}
+}
+
+// poll: seq より後のイベントを配信し、配信済みの最後の通し番号を返す
+// 同時に書き込まれたイベントはコミット順と番号順が前後するので、番号が抜けていたら少し待つ
+func (b *SQLBackend) poll(seq int64, gapSince time.Time, deliver func(model.ChatEvent)) (int64, time.Time) {
+	for {
+		events, err := b.Log.After(seq, 500)
+		if err != nil {
+			log.Printf("fail: poll chat events, %v\n", err)
+			return seq, gapSince
+		}
+		for _, ev := range events {
+			if ev.Seq != seq+1 {
+				if gapSince.IsZero() {
+					gapSince = time.Now()
+				}
+				if time.Since(gapSince) < gapWait {
+					return seq, gapSince
+				}
+			}
+			gapSince = time.Time{}
+			deliver(ev)
+			seq = ev.Seq
+		}
+		if len(events) < 500 {
+			return seq, gapSince
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"os"
+	"strings"
+)
+
+// isAdmin: ADMIN_USER_IDS（カンマ区切り）に含まれるユーザーを管理者とみなす
+func isAdmin(userID string) bool {
+	if userID == "" {
+		return false
+	}
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if strings.TrimSpace(id) == userID {
+			return true
+		}
+	}
+	return false
+}
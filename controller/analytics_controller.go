@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type AnalyticsController struct {
+	Usecase *usecase.AnalyticsUsecase
+}
+
+func NewAnalyticsController(uc *usecase.AnalyticsUsecase) *AnalyticsController {
+	return &AnalyticsController{Usecase: uc}
+}
+
+// HandleRecordView: 商品の閲覧を記録する (POST /items/{id}/views)
+func (c *AnalyticsController) HandleRecordView(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID    string `json:"user_id"`
+		SessionID string `json:"session_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	counted, err := c.Usecase.RecordView(r.PathValue("id"), req.UserID, req.SessionID)
+	switch {
+	case errors.Is(err, usecase.ErrViewerRequired):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrItemNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		log.Printf("fail: record view, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"counted": counted})
+}
+
+// HandleGetAnalytics: 出品者向けの分析 (GET /me/analytics?user_id=xxx&days=30)
+func (c *AnalyticsController) HandleGetAnalytics(w http.ResponseWriter, r *http.Request) {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 || days > 365 {
+		days = 30
+	}
+
+	analytics, err := c.Usecase.SellerAnalytics(r.URL.Query().Get("user_id"), days)
+	if errors.Is(err, usecase.ErrNotAllowed) {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("fail: seller analytics, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics)
+}
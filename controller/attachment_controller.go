@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/upload"
+	"hackathon-backend/usecase"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type AttachmentController struct {
+	Usecase *usecase.AttachmentUsecase
+}
+
+func NewAttachmentController(uc *usecase.AttachmentUsecase) *AttachmentController {
+	return &AttachmentController{Usecase: uc}
+}
+
+// HandleUpload: やりとりに添付する画像のアップロード (POST /conversations/{id}/attachments)
+// multipart の "file" に画像、"user_id" に送信者を指定する
+func (c *AttachmentController) HandleUpload(w http.ResponseWriter, r *http.Request) {
+	// multipart の区切りなどの分だけ余裕を持たせる
+	r.Body = http.MaxBytesReader(w, r.Body, int64(c.Usecase.MaxBytes)+1<<20)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, upload.ErrTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// 上限を1バイト超えて読めたら大きすぎる
+	data, err := io.ReadAll(io.LimitReader(file, int64(c.Usecase.MaxBytes)+1))
+	if err != nil {
+		http.Error(w, "Failed to read file", http.StatusBadRequest)
+		return
+	}
+
+	a, err := c.Usecase.Upload(r.PathValue("id"), r.FormValue("user_id"), data)
+	if err != nil {
+		log.Printf("fail: upload attachment, %v\n", err)
+		http.Error(w, err.Error(), attachmentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+// HandleGet: 添付画像の取得 (GET /attachments/{id}?user_id=xxx)
+func (c *AttachmentController) HandleGet(w http.ResponseWriter, r *http.Request) {
+	c.serve(w, r, false)
+}
+
+// HandleGetThumbnail: 添付画像のサムネイルの取得 (GET /attachments/{id}/thumbnail?user_id=xxx)
+func (c *AttachmentController) HandleGetThumbnail(w http.ResponseWriter, r *http.Request) {
+	c.serve(w, r, true)
+}
+
+func (c *AttachmentController) serve(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	data, contentType, err := c.Usecase.Open(r.PathValue("id"), r.URL.Query().Get("user_id"), thumbnail)
+	if err != nil {
+		http.Error(w, err.Error(), attachmentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	// 当事者だけが見られる画像なので共有キャッシュには置かせず、中身の形式の推測もさせない
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", "inline")
+	w.Write(data)
+}
+
+// attachmentErrorStatus: 添付のエラーを HTTP ステータスに変換する
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrAttachmentNotFound), errors.Is(err, usecase.ErrConversationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, upload.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, upload.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+)
+
+type BlockController struct {
+	Usecase *usecase.BlockUsecase
+}
+
+func NewBlockController(uc *usecase.BlockUsecase) *BlockController {
+	return &BlockController{Usecase: uc}
+}
+
+// HandleBlock: ユーザーをブロックする (POST /blocks)
+func (c *BlockController) HandleBlock(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		TargetID string `json:"target_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Usecase.Block(req.UserID, req.TargetID); err != nil {
+		log.Printf("fail: block user, %v\n", err)
+		http.Error(w, err.Error(), blockErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"blocked": true})
+}
+
+// HandleUnblock: ブロックを解除する (DELETE /blocks/{id}?user_id=xxx)
+func (c *BlockController) HandleUnblock(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if err := c.Usecase.Unblock(userID, r.PathValue("id")); err != nil {
+		log.Printf("fail: unblock user, %v\n", err)
+		http.Error(w, err.Error(), blockErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"blocked": false})
+}
+
+// HandleGetBlocks: ブロックしているユーザーの一覧 (GET /blocks?user_id=xxx)
+func (c *BlockController) HandleGetBlocks(w http.ResponseWriter, r *http.Request) {
+	blocks, err := c.Usecase.List(r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: list blocks, %v\n", err)
+		http.Error(w, err.Error(), blockErrorStatus(err))
+		return
+	}
+	if blocks == nil {
+		blocks = []*model.Block{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+// blockErrorStatus: ブロックのエラーを HTTP ステータスに変換する
+func blockErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed), errors.Is(err, usecase.ErrCannotBlockSelf):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+)
+
+type CategoryController struct {
+	Usecase *usecase.CategoryUsecase
+}
+
+func NewCategoryController(uc *usecase.CategoryUsecase) *CategoryController {
+	return &CategoryController{Usecase: uc}
+}
+
+// HandleList: カテゴリの木 (GET /categories)
+func (c *CategoryController) HandleList(w http.ResponseWriter, r *http.Request) {
+	roots := c.Usecase.Tree().Roots()
+	if roots == nil {
+		roots = []*model.Category{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(roots)
+}
+
+// HandleMatch: 自由入力のカテゴリ名に最も近いカテゴリ (GET /categories/match?q=スマホ)
+func (c *CategoryController) HandleMatch(w http.ResponseWriter, r *http.Request) {
+	slug := c.Usecase.Match(r.URL.Query().Get("q"))
+	if slug == "" {
+		http.Error(w, "No matching category", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"slug": slug,
+		"path": categoryPath(c.Usecase.Tree().Path(slug)),
+	})
+}
+
+// categoryPath: 最上位からのカテゴリ（子カテゴリは含めない）
+func categoryPath(path []*model.Category) []map[string]string {
+	out := []map[string]string{}
+	for _, p := range path {
+		out = append(out, map[string]string{"slug": p.Slug, "name_ja": p.NameJa, "name_en": p.NameEn})
+	}
+	return out
+}
+
+// categoryRequest: 管理者によるカテゴリの追加・更新
+type categoryRequest struct {
+	UserID string `json:"user_id"`
+	model.Category
+}
+
+// HandleCreate: カテゴリを追加する（管理者のみ） (POST /admin/categories)
+func (c *CategoryController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !isAdmin(req.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	req.Category.Children = nil
+	if err := c.Usecase.Create(&req.Category); err != nil {
+		log.Printf("fail: create category, %v\n", err)
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(req.Category)
+}
+
+// HandleUpdate: カテゴリを更新する（管理者のみ） (PUT /admin/categories/{slug})
+func (c *CategoryController) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !isAdmin(req.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	req.Category.Slug = r.PathValue("slug")
+	req.Category.Children = nil
+	if err := c.Usecase.Update(&req.Category); err != nil {
+		log.Printf("fail: update category, %v\n", err)
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(req.Category)
+}
+
+// HandleDelete: カテゴリを削除する（管理者のみ） (DELETE /admin/categories/{slug}?user_id=xxx)
+func (c *CategoryController) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r.URL.Query().Get("user_id")) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err := c.Usecase.Delete(r.PathValue("slug")); err != nil {
+		log.Printf("fail: delete category, %v\n", err)
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// categoryErrorStatus: カテゴリ管理のエラーを HTTP ステータスに変換する
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dao.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dao.ErrCategoryExists), errors.Is(err, usecase.ErrCannotDeleteOther):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrInvalidCategorySlug), errors.Is(err, model.ErrCategoryNameMissing),
+		errors.Is(err, usecase.ErrParentNotFound), errors.Is(err, usecase.ErrCategoryHasCycle):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -1,74 +1,223 @@
 package controller
 
 import (
-	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hackathon-backend/dao"
 	"hackathon-backend/model"
+	"hackathon-backend/moderation"
+	"hackathon-backend/usecase"
+	"log"
 	"net/http"
 	"time"
 )
 
+// streamHeartbeat: 接続を維持するためのコメント行を送る間隔（プロキシのアイドル切断対策）
+const streamHeartbeat = 15 * time.Second
+
 type ChatController struct {
-	MessageDAO *dao.MessageDAO // ポインタとして扱う
+	Usecase *usecase.ChatUsecase
 }
 
 // コンストラクタ
-func NewChatController(messageDAO *dao.MessageDAO) *ChatController {
-	return &ChatController{MessageDAO: messageDAO}
+func NewChatController(uc *usecase.ChatUsecase) *ChatController {
+	return &ChatController{Usecase: uc}
 }
 
-// HandleGetMessages: メッセージ取得 (GET /messages?item_id=xxx)
-func (c *ChatController) HandleGetMessages(w http.ResponseWriter, r *http.Request) {
-	itemID := r.URL.Query().Get("item_id")
-	if itemID == "" {
-		http.Error(w, "item_id is required", http.StatusBadRequest)
+// HandleGetConversations: 受信箱 (GET /conversations?user_id=xxx&limit=20)
+func (c *ChatController) HandleGetConversations(w http.ResponseWriter, r *http.Request) {
+	list, err := c.Usecase.Inbox(r.URL.Query().Get("user_id"), pageLimit(r))
+	if err != nil {
+		log.Printf("fail: list conversations, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
 		return
 	}
+	if list == nil {
+		list = []*model.Conversation{}
+	}
 
-	msgs, err := c.MessageDAO.GetByItemID(itemID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HandleStartConversation: 商品の出品者とのやりとりを始める（既にあればそれを返す） (POST /conversations)
+func (c *ChatController) HandleStartConversation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+		ItemID string `json:"item_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	conv, err := c.Usecase.StartConversation(req.ItemID, req.UserID)
 	if err != nil {
-		// エラーログはサーバー側に出すとデバッグしやすい
-		fmt.Printf("DB Error: %v\n", err)
-		http.Error(w, "DB Error", http.StatusInternalServerError)
+		log.Printf("fail: start conversation, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
 		return
 	}
 
-	if msgs == nil {
-		msgs = []*model.Message{}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conv)
+}
+
+// HandleGetConversation: やりとりの詳細 (GET /conversations/{id}?user_id=xxx)
+func (c *ChatController) HandleGetConversation(w http.ResponseWriter, r *http.Request) {
+	conv, err := c.Usecase.Conversation(r.PathValue("id"), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(msgs)
+	json.NewEncoder(w).Encode(conv)
+}
+
+// HandleMarkRead: やりとりを既読にする (POST /conversations/{id}/read)
+// seq を省略するとやりとりのすべてを既読にする
+func (c *ChatController) HandleMarkRead(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+		Seq    int    `json:"seq"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	conv, err := c.Usecase.MarkRead(r.PathValue("id"), req.UserID, req.Seq)
+	if err != nil {
+		log.Printf("fail: mark conversation read, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conv)
+}
+
+// HandleGetUnread: 未読数の合計 (GET /conversations/unread?user_id=xxx)
+func (c *ChatController) HandleGetUnread(w http.ResponseWriter, r *http.Request) {
+	summary, err := c.Usecase.Unread(r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: count unread messages, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// HandleGetMessages: メッセージ取得 (GET /messages?conversation_id=xxx&user_id=yyy&before=&after=&limit=20)
+// 購入希望者は conversation_id の代わりに item_id でも自分のやりとりを取得できる。
+// before・after にはメッセージ ID を指定し、それより古い／新しいメッセージを古い順に返す
+func (c *ChatController) HandleGetMessages(w http.ResponseWriter, r *http.Request) {
+	conversationID, userID, err := c.resolveConversation(r)
+	if err != nil {
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
+	}
+
+	page := &model.MessagePage{Messages: []*model.Message{}}
+	if conversationID != "" {
+		q := r.URL.Query()
+		page, err = c.Usecase.Messages(conversationID, userID, q.Get("before"), q.Get("after"), pageLimit(r))
+		if err != nil {
+			log.Printf("fail: get messages, %v\n", err)
+			http.Error(w, err.Error(), chatErrorStatus(err))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// resolveConversation: クエリの conversation_id（なければ購入希望者としての item_id のやりとり）と user_id
+// まだやりとりがなければ conversation_id は空
+func (c *ChatController) resolveConversation(r *http.Request) (string, string, error) {
+	q := r.URL.Query()
+	userID := q.Get("user_id")
+	if userID == "" {
+		return "", "", usecase.ErrNotAllowed
+	}
+	if id := q.Get("conversation_id"); id != "" {
+		return id, userID, nil
+	}
+	if q.Get("item_id") == "" {
+		return "", "", usecase.ErrConversationRequired
+	}
+	conv, err := c.Usecase.ConversationDAO.GetByItemAndBuyer(q.Get("item_id"), userID)
+	if err != nil || conv == nil {
+		return "", userID, err
+	}
+	return conv.ID, userID, nil
 }
 
 // HandlePostMessage: メッセージ送信 (POST /messages)
+// conversation_id がなければ、送信者を購入希望者として item_id のやりとりに送る。
+// 画像は先に POST /conversations/{id}/attachments でアップロードし、attachment_ids で指定する
 func (c *ChatController) HandlePostMessage(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		ItemID   string `json:"item_id"`
-		SenderID string `json:"sender_id"`
-		Content  string `json:"content"`
+		ConversationID string   `json:"conversation_id"`
+		ItemID         string   `json:"item_id"`
+		SenderID       string   `json:"sender_id"`
+		Content        string   `json:"content"`
+		AttachmentIDs  []string `json:"attachment_ids"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	id, _ := generateID()
+	msg, err := c.Usecase.Post(req.ConversationID, req.ItemID, req.SenderID, req.Content, req.AttachmentIDs)
+	if writeRejected(w, err) {
+		return
+	}
+	if err != nil {
+		log.Printf("fail: post message, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msg)
+}
 
-	msg := &model.Message{
-		ID:       id,
-		ItemID:   req.ItemID,
-		SenderID: req.SenderID,
-		Content:  req.Content,
-		// ★修正: 日付も含めたフォーマットに変更（並び順のため重要！）
-		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+// HandleEditMessage: 送信したメッセージの本文を書き換える (PUT /messages/{id})
+func (c *ChatController) HandleEditMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID  string `json:"user_id"`
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	msg, err := c.Usecase.Edit(r.PathValue("id"), req.UserID, req.Content)
+	if writeRejected(w, err) {
+		return
+	}
+	if err != nil {
+		log.Printf("fail: edit message, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
 	}
 
-	if err := c.MessageDAO.Insert(msg); err != nil {
-		fmt.Printf("Save Error: %v\n", err)
-		http.Error(w, "Save Error", http.StatusInternalServerError)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msg)
+}
+
+// HandleUnsendMessage: メッセージの送信を取り消す (DELETE /messages/{id}?user_id=xxx)
+func (c *ChatController) HandleUnsendMessage(w http.ResponseWriter, r *http.Request) {
+	msg, err := c.Usecase.Unsend(r.PathValue("id"), r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: unsend message, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
 		return
 	}
 
@@ -76,11 +225,158 @@ func (c *ChatController) HandlePostMessage(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(msg)
 }
 
-func generateID() (string, error) {
-	b := make([]byte, 8)
-	_, err := rand.Read(b)
+// HandleGetMessageHistory: メッセージの編集・送信取り消しの履歴（管理者のみ） (GET /admin/messages/{id}/revisions?user_id=xxx)
+func (c *ChatController) HandleGetMessageHistory(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r.URL.Query().Get("user_id")) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	history, err := c.Usecase.History(r.PathValue("id"))
 	if err != nil {
-		return "", err
+		log.Printf("fail: get message history, %v\n", err)
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// writeRejected: 審査で拒否したときは、理由をクライアントが表示できるように JSON で返す（返したら true）
+func writeRejected(w http.ResponseWriter, err error) bool {
+	var rejected *usecase.MessageRejectedError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   "message_rejected",
+		"reason":  rejected.Result.Reason,
+		"message": "This message cannot be sent because it may violate the community guidelines",
+	})
+	return true
+}
+
+// HandleStream: やりとりの新しいメッセージを Server-Sent Events で配信する (GET /messages/stream?conversation_id=xxx&user_id=yyy)
+// 再接続時は Last-Event-ID ヘッダ（または last_event_id パラメータ）のメッセージより後から送り直す
+func (c *ChatController) HandleStream(w http.ResponseWriter, r *http.Request) {
+	conversationID := r.URL.Query().Get("conversation_id")
+	if conversationID == "" {
+		http.Error(w, "conversation_id is required", http.StatusBadRequest)
+		return
+	}
+	conv, err := c.Usecase.Conversation(conversationID, r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), chatErrorStatus(err))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// 取りこぼさないように、送り直す分を読む前に購読を始める
+	sub := c.Usecase.Hub.Subscribe(conversationID)
+	defer sub.Close()
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var missed []*model.Message
+	if lastID != "" {
+		if missed, err = c.Usecase.MissedMessages(conversationID, lastID); err != nil {
+			log.Printf("fail: list missed messages, %v\n", err)
+			http.Error(w, "DB Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	sent := map[string]bool{}
+	for _, m := range missed {
+		data, _ := json.Marshal(m)
+		writeEvent(w, model.ChatEvent{ID: m.ID, Type: model.ChatEventMessage, Data: data})
+		sent[m.ID] = true
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			// ブロックの通知を取りこぼしても、ハートビートごとに確かめ直して閉じる
+			if !c.streamOpen(conv) {
+				return
+			}
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case ev, ok := <-sub.Events():
+			if !ok {
+				// 受け取りが追いつかず購読が切られた。クライアントは Last-Event-ID で再接続する
+				return
+			}
+			if ev.Type == model.ChatEventClosed {
+				// 配信中にブロックされた。確かめてから、次のイベントを待たずに閉じる
+				if !c.streamOpen(conv) {
+					return
+				}
+				continue
+			}
+			if sent[ev.ID] {
+				continue
+			}
+			writeEvent(w, ev)
+			flusher.Flush()
+		}
+	}
+}
+
+// streamOpen: 配信を続けてよいか（ブロックされた・確認できないときは false）
+func (c *ChatController) streamOpen(conv *model.Conversation) bool {
+	err := c.Usecase.CheckOpen(conv)
+	if err != nil && !errors.Is(err, usecase.ErrConversationNotFound) {
+		log.Printf("fail: check chat stream, %v\n", err)
+	}
+	return err == nil
+}
+
+// writeEvent: SSE の1イベントを書き出す（data は1行の JSON）
+// 既読などの ID のないイベントでは id を送らない（空の id はクライアントの再開位置を消してしまう）
+func writeEvent(w http.ResponseWriter, ev model.ChatEvent) {
+	if ev.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", ev.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, ev.Data)
+}
+
+// chatErrorStatus: やりとり・メッセージのエラーを HTTP ステータスに変換する
+func chatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrConversationNotFound), errors.Is(err, usecase.ErrItemNotFound), errors.Is(err, usecase.ErrMessageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed), errors.Is(err, usecase.ErrBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, moderation.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, model.ErrEditWindowClosed), errors.Is(err, model.ErrMessageUnsent):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrConversationRequired), errors.Is(err, usecase.ErrCannotMessageSelf),
+		errors.Is(err, model.ErrInvalidMessage), errors.Is(err, model.ErrInvalidCursor),
+		errors.Is(err, model.ErrTooManyAttachments), errors.Is(err, dao.ErrAttachmentUnavailable):
+		return http.StatusBadRequest
 	}
-	return fmt.Sprintf("%x", b), nil
+	return http.StatusInternalServerError
 }
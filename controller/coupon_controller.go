@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+)
+
+// CouponController: 管理者によるクーポンの登録・変更
+type CouponController struct {
+	Usecase *usecase.CouponUsecase
+}
+
+func NewCouponController(uc *usecase.CouponUsecase) *CouponController {
+	return &CouponController{Usecase: uc}
+}
+
+// couponRequest: 管理者によるクーポンの登録・変更
+type couponRequest struct {
+	UserID string `json:"user_id"`
+	model.Coupon
+}
+
+// HandleList: クーポンの一覧と利用数（管理者のみ） (GET /admin/coupons?user_id=xxx)
+func (c *CouponController) HandleList(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r.URL.Query().Get("user_id")) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	coupons, err := c.Usecase.CouponDAO.List()
+	if err != nil {
+		log.Printf("fail: list coupons, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if coupons == nil {
+		coupons = []*model.Coupon{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coupons)
+}
+
+// HandleCreate: クーポンを登録する（管理者のみ） (POST /admin/coupons)
+func (c *CouponController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req couponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !isAdmin(req.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if err := c.Usecase.Create(&req.Coupon); err != nil {
+		log.Printf("fail: create coupon, %v\n", err)
+		http.Error(w, err.Error(), couponErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(req.Coupon)
+}
+
+// HandleUpdate: クーポンの条件を変更・停止する（管理者のみ） (PUT /admin/coupons/{code})
+func (c *CouponController) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req couponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !isAdmin(req.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	req.Coupon.Code = r.PathValue("code")
+	coupon, err := c.Usecase.Update(&req.Coupon)
+	if err != nil {
+		log.Printf("fail: update coupon, %v\n", err)
+		http.Error(w, err.Error(), couponErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coupon)
+}
+
+// couponErrorStatus: クーポン管理のエラーを HTTP ステータスに変換する
+func couponErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dao.ErrCouponNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dao.ErrCouponExists):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrInvalidCoupon):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
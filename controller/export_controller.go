@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"hackathon-backend/dao"
+	"hackathon-backend/feed"
+	"hackathon-backend/model"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ExportController struct {
+	ItemDAO *dao.ItemDAO
+	// SiteURL: フィードに載せる商品ページの URL の起点（フロントエンドの URL）
+	SiteURL string
+}
+
+func NewExportController(itemDAO *dao.ItemDAO, siteURL string) *ExportController {
+	return &ExportController{ItemDAO: itemDAO, SiteURL: siteURL}
+}
+
+// HandleExportCSV: 商品の CSV (GET /export/items.csv?seller_id=xxx&user_id=yyy)
+// seller_id を指定すると、その出品者の商品に絞る。出品者本人（user_id が seller_id と同じ）なら
+// 下書き・取り下げ・非表示の商品も含めたバックアップになり、それ以外の人には公開中の商品だけを返す
+func (c *ExportController) HandleExportCSV(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sellerID := q.Get("seller_id")
+	isOwner := sellerID != "" && q.Get("user_id") == sellerID
+	filter := dao.ItemStreamFilter{SellerID: sellerID, PublishedOnly: !isOwner}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="items.csv"`)
+	c.stream(w, "csv", filter, func() (feed.Writer, error) { return feed.NewCSV(w) })
+}
+
+// HandleAtom: 新着商品の Atom フィード (GET /feeds/atom.xml?limit=50)
+func (c *ExportController) HandleAtom(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	filter := dao.ItemStreamFilter{PublishedOnly: true, Limit: limit}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	c.stream(w, "atom", filter, func() (feed.Writer, error) { return feed.NewAtom(w, c.meta()) })
+}
+
+// HandleProductFeed: 価格比較サイト向けの商品フィード (GET /feeds/products.xml)
+func (c *ExportController) HandleProductFeed(w http.ResponseWriter, r *http.Request) {
+	filter := dao.ItemStreamFilter{PublishedOnly: true}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.stream(w, "product feed", filter, func() (feed.Writer, error) { return feed.NewProductFeed(w, c.meta()) })
+}
+
+func (c *ExportController) meta() feed.Meta {
+	return feed.Meta{Title: "Hackathon Market 新着商品", SiteURL: c.SiteURL, Updated: time.Now()}
+}
+
+// stream: DB から1件ずつ読みながら書き出す
+// 書き出しを始めた後はステータスを変えられないので、途中のエラーはログに残すだけ
+func (c *ExportController) stream(w http.ResponseWriter, name string, filter dao.ItemStreamFilter, open func() (feed.Writer, error)) {
+	fw, err := open()
+	if err != nil {
+		log.Printf("fail: export %s, %v\n", name, err)
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	count := 0
+	err = c.ItemDAO.Stream(filter, func(item *model.Item) error {
+		if err := fw.WriteItem(item); err != nil {
+			return err
+		}
+		if count++; flusher != nil && count%100 == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("fail: export %s after %d items, %v\n", name, count, err)
+		return
+	}
+	if err := fw.Close(); err != nil {
+		log.Printf("fail: export %s, %v\n", name, err)
+	}
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type FollowController struct {
+	Usecase *usecase.FollowUsecase
+}
+
+func NewFollowController(uc *usecase.FollowUsecase) *FollowController {
+	return &FollowController{Usecase: uc}
+}
+
+// HandleToggleFollow: 出品者のフォローの切り替え (POST /follows)
+func (c *FollowController) HandleToggleFollow(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		SellerID string `json:"seller_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	following, err := c.Usecase.Toggle(req.UserID, req.SellerID)
+	if err != nil {
+		log.Printf("fail: toggle follow, %v\n", err)
+		http.Error(w, err.Error(), followErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"following": following})
+}
+
+// HandleGetFollowing: フォロー中の出品者のID一覧 (GET /follows?user_id=xxx)
+func (c *FollowController) HandleGetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id required", http.StatusBadRequest)
+		return
+	}
+
+	ids, err := c.Usecase.FollowDAO.GetFollowingIDs(userID)
+	if err != nil {
+		log.Printf("fail: get following, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ids)
+}
+
+// HandleStorefront: 出品者のショップ (GET /users/{id}/items?tab=active|sold&cursor=xxx&limit=20&user_id=閲覧者)
+func (c *FollowController) HandleStorefront(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	shop, err := c.Usecase.Storefront(r.PathValue("id"), q.Get("user_id"), usecase.ShopTab(q.Get("tab")), q.Get("cursor"), pageLimit(r))
+	if err != nil {
+		log.Printf("fail: get storefront, %v\n", err)
+		http.Error(w, err.Error(), followErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shop)
+}
+
+// HandleFeed: フォロー中の出品者の新着 (GET /feed?user_id=xxx&cursor=xxx&limit=20)
+func (c *FollowController) HandleFeed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := c.Usecase.Feed(q.Get("user_id"), q.Get("cursor"), pageLimit(r))
+	if err != nil {
+		log.Printf("fail: get feed, %v\n", err)
+		http.Error(w, err.Error(), followErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// pageLimit: ページ送りする一覧の1ページの件数（既定 20、最大 100）
+func pageLimit(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return limit
+}
+
+// followErrorStatus: ショップ・フォロー・フィードのエラーを HTTP ステータスに変換する
+func followErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed), errors.Is(err, usecase.ErrCannotFollowSelf), errors.Is(err, usecase.ErrInvalidShopTab), errors.Is(err, model.ErrInvalidCursor):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
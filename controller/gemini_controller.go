@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"hackathon-backend/dao"
+	"hackathon-backend/moderation"
+	"hackathon-backend/usecase"
 	"io"
 	"log"
 	"net/http"
@@ -15,11 +17,14 @@ import (
 )
 
 type GeminiController struct {
-	ItemDAO *dao.ItemDAO
+	ItemDAO    *dao.ItemDAO
+	Categories *usecase.CategoryUsecase
+	// Moderator: メッセージ送信時と同じ審査で事前チェックする
+	Moderator *moderation.Moderator
 }
 
-func NewGeminiController(itemDAO *dao.ItemDAO) *GeminiController {
-	return &GeminiController{ItemDAO: itemDAO}
+func NewGeminiController(itemDAO *dao.ItemDAO, categories *usecase.CategoryUsecase, moderator *moderation.Moderator) *GeminiController {
+	return &GeminiController{ItemDAO: itemDAO, Categories: categories, Moderator: moderator}
 }
 
 // リクエスト構造体
@@ -246,11 +251,36 @@ func (c *GeminiController) analyzeImageCommon(w http.ResponseWriter, r *http.Req
 	}
 	cleanTxt := strings.ReplaceAll(result, "```json", "")
 	cleanTxt = strings.ReplaceAll(cleanTxt, "```", "")
+	if mode != "repair" {
+		cleanTxt = c.withCategorySlug(cleanTxt)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(cleanTxt))
 }
 
-// チャットチェック
+// withCategorySlug: AI が返した自由入力のカテゴリ名を、既存のカテゴリに対応付けて category_slug を追加する
+// JSON として読めない場合はそのまま返す
+func (c *GeminiController) withCategorySlug(txt string) string {
+	var listing map[string]interface{}
+	if err := json.Unmarshal([]byte(txt), &listing); err != nil {
+		return txt
+	}
+	name, _ := listing["category"].(string)
+	slug := c.Categories.Match(name)
+	if slug == "" {
+		return txt
+	}
+	listing["category_slug"] = slug
+	listing["category_path"] = categoryPath(c.Categories.Tree().Path(slug))
+
+	b, err := json.Marshal(listing)
+	if err != nil {
+		return txt
+	}
+	return string(b)
+}
+
+// チャットチェック: 送信前の確認用（送信時にも同じ審査を行う）
 func (c *GeminiController) HandleCheckContent(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Content string `json:"content"`
@@ -259,16 +289,18 @@ func (c *GeminiController) HandleCheckContent(w http.ResponseWriter, r *http.Req
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	prompt := fmt.Sprintf(`以下のメッセージが「攻撃的」「暴力的」か判定してください。問題あれば "UNSAFE"、なければ "SAFE" とJSONの {"result": "SAFE"} 形式で答えてください。メッセージ: "%s"`, req.Content)
-	result, err := c.callGeminiAPI(prompt, nil, "")
-	isSafe := true
-	if err == nil {
-		if strings.Contains(result, "UNSAFE") {
-			isSafe = false
-		}
+	result, err := c.Moderator.Check(r.Context(), req.Content)
+	if err != nil {
+		log.Printf("fail: check content, %v\n", err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]bool{"is_safe": isSafe})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"is_safe": result.Verdict != moderation.Block,
+		"verdict": result.Verdict,
+		"reason":  result.Reason,
+	})
 }
 
 // 職人チャット
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// importMaxBytes: 一括出品で受け付けるファイルの大きさ
+const importMaxBytes = 10 << 20
+
+type ImportController struct {
+	Usecase *usecase.ImportUsecase
+}
+
+func NewImportController(uc *usecase.ImportUsecase) *ImportController {
+	return &ImportController{Usecase: uc}
+}
+
+// HandleImport: CSV / JSONL の一括出品 (POST /items/import?user_id=xxx&dry_run=1&format=csv)
+// 本文にファイルをそのまま送るか、multipart の "file" で送る
+func (c *ImportController) HandleImport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	r.Body = http.MaxBytesReader(w, r.Body, importMaxBytes)
+
+	var body io.Reader = r.Body
+	name := ""
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "file is required", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body, name = file, header.Filename
+	}
+
+	format := DetectImportFormat(q.Get("format"), name, r.Header.Get("Content-Type"))
+	dryRun := q.Get("dry_run") == "1" || q.Get("dry_run") == "true"
+
+	report, err := c.Usecase.Import(q.Get("user_id"), body, format, dryRun)
+	if errors.Is(err, usecase.ErrNotAllowed) {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("fail: import items, %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// DetectImportFormat: 指定がなければ拡張子、Content-Type の順に形式を判定する（既定は CSV）
+func DetectImportFormat(format, filename, contentType string) model.ImportFormat {
+	if format != "" {
+		return model.ImportFormat(strings.ToLower(format))
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		return model.ImportJSONL
+	case ".csv":
+		return model.ImportCSV
+	}
+	if strings.Contains(contentType, "ndjson") || strings.Contains(contentType, "jsonl") {
+		return model.ImportJSONL
+	}
+	return model.ImportCSV
+}
@@ -6,19 +6,23 @@ import (
 	"fmt"
 	"hackathon-backend/dao"
 	"hackathon-backend/model"
+	"hackathon-backend/usecase"
 	"log"
 	"net/http"
+	"time"
 )
 
 type ItemController struct {
-	ItemDAO *dao.ItemDAO
+	ItemDAO    *dao.ItemDAO
+	Categories *usecase.CategoryUsecase
 }
 
-func NewItemController(itemDAO *dao.ItemDAO) *ItemController {
-	return &ItemController{ItemDAO: itemDAO}
+func NewItemController(itemDAO *dao.ItemDAO, categories *usecase.CategoryUsecase) *ItemController {
+	return &ItemController{ItemDAO: itemDAO, Categories: categories}
 }
 
-// HandleGetItems: 商品一覧または検索結果を返す (GET /items)
+// HandleGetItems: 商品一覧または検索結果を返す (GET /items?q=xxx&category=slug)
+// category を指定すると、そのカテゴリと子孫カテゴリの商品に絞り込む
 func (c *ItemController) HandleGetItems(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -28,7 +32,10 @@ func (c *ItemController) HandleGetItems(w http.ResponseWriter, r *http.Request)
 	var items []*model.Item
 	var err error
 
-	if keyword != "" {
+	if slug := r.URL.Query().Get("category"); slug != "" {
+		// 存在しないカテゴリなら空の一覧になる
+		items, err = c.ItemDAO.ListByCategories(c.Categories.Descendants(slug), keyword)
+	} else if keyword != "" {
 		// キーワードがある場合は検索を実行
 		log.Printf("Searching items with keyword: %s", keyword)
 		items, err = c.ItemDAO.Search(keyword)
@@ -53,33 +60,18 @@ func (c *ItemController) HandleGetItems(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(items)
 }
 
-// HandlePurchase: 商品を購入する
-func (c *ItemController) HandlePurchase(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
-	if err := c.ItemDAO.Purchase(id); err != nil {
-		log.Printf("fail: purchase item, %v\n", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, `{"message": "Purchase successful"}`)
-}
-
 // HandleAddItem: 商品を出品する
 func (c *ItemController) HandleAddItem(w http.ResponseWriter, r *http.Request) {
 	log.Println("Handling AddItem request...")
 
 	var req struct {
-		Name        string `json:"name"`
-		Price       int    `json:"price"`
-		Description string `json:"description"`
-		ImageURL    string `json:"image_url"`
+		Name        string   `json:"name"`
+		Price       int      `json:"price"`
+		Description string   `json:"description"`
+		ImageURL    string   `json:"image_url"`
+		SellerID    string   `json:"seller_id"`
+		Category    string   `json:"category"`
+		Tags        []string `json:"tags"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("fail: decode request body, %v\n", err)
@@ -88,6 +80,7 @@ func (c *ItemController) HandleAddItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id, _ := generateItemID()
+	now := time.Now()
 
 	item := &model.Item{
 		ID:          id,
@@ -95,6 +88,12 @@ func (c *ItemController) HandleAddItem(w http.ResponseWriter, r *http.Request) {
 		Price:       req.Price,
 		Description: req.Description,
 		ImageURL:    req.ImageURL,
+		SellerID:    req.SellerID,
+		Category:    c.Categories.Match(req.Category),
+		Tags:        req.Tags,
+		Status:      model.ItemPublished,
+		CreatedAt:   &now,
+		PublishedAt: &now,
 	}
 
 	if err := c.ItemDAO.Insert(item); err != nil {
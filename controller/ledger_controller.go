@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type LedgerController struct {
+	LedgerDAO *dao.LedgerDAO
+}
+
+func NewLedgerController(ledgerDAO *dao.LedgerDAO) *LedgerController {
+	return &LedgerController{LedgerDAO: ledgerDAO}
+}
+
+// HandleGetBalance: 売上残高 (GET /balance?user_id=xxx)
+func (c *LedgerController) HandleGetBalance(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	balance, err := c.LedgerDAO.Balance(model.SellerAccount(userID))
+	if err != nil {
+		log.Printf("fail: get balance, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userID,
+		"balance": balance,
+	})
+}
+
+// HandleGetTransactions: 売上の入出金履歴 (GET /balance/transactions?user_id=xxx&limit=50)
+func (c *LedgerController) HandleGetTransactions(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	entries, err := c.LedgerDAO.ListEntries(model.SellerAccount(userID), limit)
+	if err != nil {
+		log.Printf("fail: list ledger entries, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []*model.LedgerEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// HandleRequestPayout: 振込申請 (POST /payouts)
+func (c *LedgerController) HandleRequestPayout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+		Amount int    `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" || req.Amount <= 0 {
+		http.Error(w, "user_id and a positive amount are required", http.StatusBadRequest)
+		return
+	}
+
+	payout := &model.PayoutRequest{
+		ID:        model.NewID(),
+		SellerID:  req.UserID,
+		Amount:    req.Amount,
+		Status:    model.PayoutRequested,
+		CreatedAt: time.Now(),
+	}
+	if err := c.LedgerDAO.RequestPayout(payout); err != nil {
+		if errors.Is(err, dao.ErrInsufficientBalance) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("fail: request payout, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payout)
+}
+
+// HandleGetPayouts: 振込申請の一覧 (GET /payouts?user_id=xxx)
+func (c *LedgerController) HandleGetPayouts(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	payouts, err := c.LedgerDAO.ListPayouts(userID)
+	if err != nil {
+		log.Printf("fail: list payouts, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if payouts == nil {
+		payouts = []*model.PayoutRequest{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payouts)
+}
+
+// HandleReconcile: 台帳の照合レポート（管理者のみ） (GET /admin/ledger/reconcile?user_id=xxx)
+func (c *LedgerController) HandleReconcile(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r.URL.Query().Get("user_id")) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	report, err := c.LedgerDAO.Reconcile()
+	if err != nil {
+		log.Printf("fail: reconcile ledger, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleMarkPayoutPaid: 振込申請を送金済みにする（管理者のみ） (POST /admin/payouts/{id}/paid)
+func (c *LedgerController) HandleMarkPayoutPaid(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !isAdmin(req.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	payout, err := c.LedgerDAO.MarkPayoutPaid(r.PathValue("id"), time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, dao.ErrPayoutNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, dao.ErrPayoutAlreadyPaid):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("fail: mark payout paid, %v\n", err)
+			http.Error(w, "DB Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payout)
+}
@@ -2,34 +2,44 @@ package controller
 
 import (
 	"encoding/json"
-	"hackathon-backend/dao"
+	"errors"
+	"hackathon-backend/usecase"
 	"log"
 	"net/http"
 )
 
 type LikeController struct {
-	LikeDAO *dao.LikeDAO
+	Usecase *usecase.LikeUsecase
 }
 
-func NewLikeController(likeDAO *dao.LikeDAO) *LikeController {
-	return &LikeController{LikeDAO: likeDAO}
+func NewLikeController(uc *usecase.LikeUsecase) *LikeController {
+	return &LikeController{Usecase: uc}
 }
 
 // HandleToggleLike: いいねの切り替え
+// liked を指定すればその状態にする（連打や再送でも結果が変わらない）。省略すると今の状態を反転する
 func (c *LikeController) HandleToggleLike(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		UserID string `json:"user_id"`
 		ItemID string `json:"item_id"`
+		Liked  *bool  `json:"liked"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	isLiked, err := c.LikeDAO.ToggleLike(req.UserID, req.ItemID)
+	var isLiked bool
+	var err error
+	if req.Liked != nil {
+		isLiked = *req.Liked
+		err = c.Usecase.SetLiked(req.UserID, req.ItemID, isLiked)
+	} else {
+		isLiked, err = c.Usecase.Toggle(req.UserID, req.ItemID)
+	}
 	if err != nil {
 		log.Printf("【いいねエラー】ToggleLike Failed: %v", err)
-		http.Error(w, "Server Error: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), likeErrorStatus(err))
 		return
 	}
 
@@ -45,7 +55,7 @@ func (c *LikeController) HandleGetLikes(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	ids, err := c.LikeDAO.GetLikedItemIDs(userID)
+	ids, err := c.Usecase.LikeDAO.GetLikedItemIDs(userID)
 	if err != nil {
 		log.Printf("【いいね取得エラー】GetLikedItemIDs Failed: %v", err)
 		http.Error(w, "Server Error", http.StatusInternalServerError)
@@ -59,3 +69,16 @@ func (c *LikeController) HandleGetLikes(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ids)
 }
+
+// likeErrorStatus: いいねのエラーを HTTP ステータスに変換する
+func likeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrNotAllowed):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
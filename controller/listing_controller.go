@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+	"time"
+)
+
+type ListingController struct {
+	Usecase *usecase.ListingUsecase
+}
+
+func NewListingController(uc *usecase.ListingUsecase) *ListingController {
+	return &ListingController{Usecase: uc}
+}
+
+// draftRequest: 下書きの作成・更新で受け取る内容
+type draftRequest struct {
+	UserID      string     `json:"user_id"`
+	Name        string     `json:"name"`
+	Price       int        `json:"price"`
+	Description string     `json:"description"`
+	ImageURL    string     `json:"image_url"`
+	Category    string     `json:"category"`
+	Tags        []string   `json:"tags"`
+	PublishAt   *time.Time `json:"publish_at"`
+}
+
+func (req *draftRequest) item(id string) *model.Item {
+	return &model.Item{
+		ID:          id,
+		Name:        req.Name,
+		Price:       req.Price,
+		Description: req.Description,
+		ImageURL:    req.ImageURL,
+		SellerID:    req.UserID,
+		Category:    req.Category,
+		Tags:        req.Tags,
+		PublishAt:   req.PublishAt,
+	}
+}
+
+// HandleCreateDraft: 下書きを保存する (POST /drafts)
+func (c *ListingController) HandleCreateDraft(w http.ResponseWriter, r *http.Request) {
+	var req draftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	item := req.item("")
+	if err := c.Usecase.CreateDraft(item); err != nil {
+		log.Printf("fail: create draft, %v\n", err)
+		http.Error(w, err.Error(), listingErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+// HandleGetDrafts: 自分の下書き一覧 (GET /drafts?user_id=xxx)
+func (c *ListingController) HandleGetDrafts(w http.ResponseWriter, r *http.Request) {
+	items, err := c.Usecase.ListDrafts(r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: list drafts, %v\n", err)
+		http.Error(w, err.Error(), listingErrorStatus(err))
+		return
+	}
+	if items == nil {
+		items = []*model.Item{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// HandleUpdateDraft: 下書きを編集する (PUT /drafts/{id})
+func (c *ListingController) HandleUpdateDraft(w http.ResponseWriter, r *http.Request) {
+	var req draftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	item, err := c.Usecase.UpdateDraft(req.item(r.PathValue("id")))
+	if err != nil {
+		log.Printf("fail: update draft, %v\n", err)
+		http.Error(w, err.Error(), listingErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// HandleDeleteDraft: 下書きを削除する (DELETE /drafts/{id}?user_id=xxx)
+func (c *ListingController) HandleDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	if err := c.Usecase.DeleteDraft(r.PathValue("id"), r.URL.Query().Get("user_id")); err != nil {
+		log.Printf("fail: delete draft, %v\n", err)
+		http.Error(w, err.Error(), listingErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlePublish: 下書きを今すぐ、または publish_at に公開する (POST /drafts/{id}/publish)
+func (c *ListingController) HandlePublish(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID    string     `json:"user_id"`
+		PublishAt *time.Time `json:"publish_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	item, err := c.Usecase.Publish(r.PathValue("id"), req.UserID, req.PublishAt)
+	if err != nil {
+		log.Printf("fail: publish draft, %v\n", err)
+		http.Error(w, err.Error(), listingErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// HandleUpdateItem: 公開中の商品を編集する（値下げは、いいねしたユーザーに通知） (PUT /items/{id})
+func (c *ListingController) HandleUpdateItem(w http.ResponseWriter, r *http.Request) {
+	var req draftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	item, err := c.Usecase.Update(req.item(r.PathValue("id")))
+	if err != nil {
+		log.Printf("fail: update item, %v\n", err)
+		http.Error(w, err.Error(), listingErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// HandleGetPriceHistory: 商品の価格変更の履歴 (GET /items/{id}/price-history)
+func (c *ListingController) HandleGetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := c.Usecase.ItemDAO.ListPriceHistory(r.PathValue("id"))
+	if err != nil {
+		log.Printf("fail: list price history, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if history == nil {
+		history = []*model.PriceChange{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// HandleWithdraw: 公開中の商品を取り下げる (POST /items/{id}/withdraw)
+func (c *ListingController) HandleWithdraw(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	item, err := c.Usecase.Withdraw(r.PathValue("id"), req.UserID)
+	if err != nil {
+		log.Printf("fail: withdraw item, %v\n", err)
+		http.Error(w, err.Error(), listingErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// listingErrorStatus: 下書き・公開・編集のエラーを HTTP ステータスに変換する
+func listingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, model.ErrListingIncomplete):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrNotDraft), errors.Is(err, usecase.ErrItemNotEditable), errors.Is(err, dao.ErrConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
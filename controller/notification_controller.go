@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"encoding/json"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type NotificationController struct {
+	NotificationDAO *dao.NotificationDAO
+}
+
+func NewNotificationController(notificationDAO *dao.NotificationDAO) *NotificationController {
+	return &NotificationController{NotificationDAO: notificationDAO}
+}
+
+// HandleList: 通知一覧 (GET /notifications?user_id=xxx&unread=1&limit=50)
+func (c *NotificationController) HandleList(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "1"
+
+	list, err := c.NotificationDAO.ListByUser(userID, unreadOnly, limit)
+	if err != nil {
+		log.Printf("fail: list notifications, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []*model.Notification{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HandleMarkRead: 通知を既読にする（ids を省略するとすべて） (POST /notifications/read)
+func (c *NotificationController) HandleMarkRead(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string   `json:"user_id"`
+		IDs    []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	n, err := c.NotificationDAO.MarkRead(req.UserID, req.IDs, time.Now())
+	if err != nil {
+		log.Printf("fail: mark notifications read, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"updated": n})
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+)
+
+type OfferController struct {
+	Usecase *usecase.OfferUsecase
+}
+
+func NewOfferController(uc *usecase.OfferUsecase) *OfferController {
+	return &OfferController{Usecase: uc}
+}
+
+// HandleMakeOffer: 値下げの提示 (POST /items/{id}/offers)
+func (c *OfferController) HandleMakeOffer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+		Amount int    `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	offer, err := c.Usecase.MakeOffer(r.PathValue("id"), req.UserID, req.Amount)
+	if err != nil {
+		log.Printf("fail: make offer, %v\n", err)
+		http.Error(w, err.Error(), offerErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(offer)
+}
+
+// HandleGetOffers: 商品へのオファー一覧 (GET /items/{id}/offers?user_id=xxx)
+func (c *OfferController) HandleGetOffers(w http.ResponseWriter, r *http.Request) {
+	offers, err := c.Usecase.List(r.PathValue("id"), r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: list offers, %v\n", err)
+		http.Error(w, err.Error(), offerErrorStatus(err))
+		return
+	}
+	if offers == nil {
+		offers = []*model.Offer{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(offers)
+}
+
+// HandleAction: 逆提案・承諾・辞退 (POST /offers/{id}/counter|accept|decline)
+func (c *OfferController) HandleAction(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+		Amount int    `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	action := model.OfferAction(r.PathValue("action"))
+	switch action {
+	case model.OfferActionCounter, model.OfferActionAccept, model.OfferActionDecline:
+	default:
+		http.Error(w, "Unknown action", http.StatusNotFound)
+		return
+	}
+
+	offer, err := c.Usecase.Act(r.PathValue("id"), req.UserID, action, req.Amount)
+	if err != nil {
+		log.Printf("fail: offer %s, %v\n", action, err)
+		http.Error(w, err.Error(), offerErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(offer)
+}
+
+// offerErrorStatus: 値下げ交渉のエラーを HTTP ステータスに変換する
+func offerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrItemNotFound), errors.Is(err, usecase.ErrOfferNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed), errors.Is(err, usecase.ErrBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrOwnItem), errors.Is(err, model.ErrInvalidOfferAmount):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrIllegalOfferAction), errors.Is(err, usecase.ErrOfferExpired),
+		errors.Is(err, dao.ErrOfferExists), errors.Is(err, dao.ErrConflict), errors.Is(err, dao.ErrItemUnavailable):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/payment"
+	"hackathon-backend/usecase"
+	"io"
+	"log"
+	"net/http"
+)
+
+type OrderController struct {
+	Usecase *usecase.OrderUsecase
+}
+
+func NewOrderController(uc *usecase.OrderUsecase) *OrderController {
+	return &OrderController{Usecase: uc}
+}
+
+// HandlePurchase: 商品を購入する (POST /items/purchase?id=xxx&user_id=yyy&coupon_code=zzz)
+func (c *OrderController) HandlePurchase(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	buyerID := r.URL.Query().Get("user_id")
+	if id == "" || buyerID == "" {
+		http.Error(w, "id and user_id are required", http.StatusBadRequest)
+		return
+	}
+
+	order, err := c.Usecase.Purchase(id, buyerID, r.URL.Query().Get("coupon_code"))
+	if err != nil {
+		log.Printf("fail: purchase item, %v\n", err)
+		http.Error(w, err.Error(), orderErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Purchase successful",
+		"order":   order,
+	})
+}
+
+// HandleQuote: 購入前に支払額（クーポンの割引後）を確認する (GET /items/purchase/quote?id=xxx&user_id=yyy&coupon_code=zzz)
+func (c *OrderController) HandleQuote(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	buyerID := r.URL.Query().Get("user_id")
+	if id == "" || buyerID == "" {
+		http.Error(w, "id and user_id are required", http.StatusBadRequest)
+		return
+	}
+
+	checkout, err := c.Usecase.Quote(id, buyerID, r.URL.Query().Get("coupon_code"))
+	if err != nil {
+		http.Error(w, err.Error(), orderErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(checkout)
+}
+
+// HandleGetOrders: 自分が関わる注文の一覧 (GET /orders?user_id=xxx)
+func (c *OrderController) HandleGetOrders(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	orders, err := c.Usecase.OrderDAO.ListByUser(userID)
+	if err != nil {
+		log.Printf("fail: list orders, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if orders == nil {
+		orders = []*model.Order{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// HandleGetOrder: 注文詳細と遷移履歴 (GET /orders/{id}?user_id=xxx)
+func (c *OrderController) HandleGetOrder(w http.ResponseWriter, r *http.Request) {
+	order, events, err := c.Usecase.Get(r.PathValue("id"), r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: get order, %v\n", err)
+		http.Error(w, err.Error(), orderErrorStatus(err))
+		return
+	}
+	if events == nil {
+		events = []*model.OrderEvent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"order":  order,
+		"events": events,
+	})
+}
+
+// HandleTransition: 注文の状態を進める (POST /orders/{id}/status)
+func (c *OrderController) HandleTransition(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string            `json:"user_id"`
+		Status model.OrderStatus `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	order, err := c.Usecase.Transition(r.PathValue("id"), req.UserID, req.Status)
+	if err != nil {
+		log.Printf("fail: order transition, %v\n", err)
+		http.Error(w, err.Error(), orderErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// HandlePaymentWebhook: 決済プロバイダからの通知 (POST /payments/webhook)
+func (c *OrderController) HandlePaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+
+	ev, err := c.Usecase.Payments.VerifyWebhook(payload, r.Header.Get("Stripe-Signature"))
+	if err != nil {
+		log.Printf("fail: verify payment webhook, %v\n", err)
+		http.Error(w, "Invalid signature", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Usecase.HandlePaymentEvent(ev); err != nil {
+		// 5xx を返すとプロバイダが再送してくれる
+		log.Printf("fail: handle payment event %s, %v\n", ev.ID, err)
+		http.Error(w, "Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"received": true})
+}
+
+// orderErrorStatus: 取引関連のエラーを HTTP ステータスに変換する
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrItemNotFound), errors.Is(err, usecase.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed), errors.Is(err, usecase.ErrBlocked), errors.Is(err, model.ErrTransitionForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrOwnItem), errors.Is(err, dao.ErrCouponNotFound), errors.Is(err, model.ErrCouponNotActive),
+		errors.Is(err, model.ErrCouponNotApplicable), errors.Is(err, model.ErrCouponFirstPurchaseOnly):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrPaymentFailed), errors.Is(err, payment.ErrDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, model.ErrIllegalTransition), errors.Is(err, dao.ErrConflict), errors.Is(err, dao.ErrItemUnavailable),
+		errors.Is(err, usecase.ErrItemReserved), errors.Is(err, usecase.ErrNoSeller), errors.Is(err, model.ErrCouponExhausted), errors.Is(err, model.ErrCouponUserLimit):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"encoding/json"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"log"
+	"net/http"
+)
+
+// ProfileController: 他のユーザーからも見える公開プロフィール
+type ProfileController struct {
+	UserDAO   *dao.UserDAO
+	ReviewDAO *dao.ReviewDAO
+}
+
+func NewProfileController(userDAO *dao.UserDAO, reviewDAO *dao.ReviewDAO) *ProfileController {
+	return &ProfileController{UserDAO: userDAO, ReviewDAO: reviewDAO}
+}
+
+// HandleGetProfile: 公開プロフィールと評価 (GET /users/{id})
+func (c *ProfileController) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
+	user, err := c.UserDAO.GetVisibleUserByID(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	reputation, err := c.ReviewDAO.GetReputation(user.ID)
+	if err != nil {
+		log.Printf("fail: get reputation, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	reviews, err := c.ReviewDAO.ListRevealedByReviewee(user.ID, 20)
+	if err != nil {
+		log.Printf("fail: list reviews, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if reviews == nil {
+		reviews = []*model.Review{}
+	}
+
+	// メールアドレスやパスワードは公開しない
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":         user.ID,
+		"name":       user.Name,
+		"reputation": reputation,
+		"reviews":    reviews,
+	})
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type RecommendationController struct {
+	Usecase *usecase.RecommendationUsecase
+}
+
+func NewRecommendationController(uc *usecase.RecommendationUsecase) *RecommendationController {
+	return &RecommendationController{Usecase: uc}
+}
+
+// HandleGetRecommendations: いいね履歴からのおすすめ (GET /recommendations?user_id=xxx&limit=20)
+func (c *RecommendationController) HandleGetRecommendations(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	items, err := c.Usecase.Recommend(r.URL.Query().Get("user_id"), limit)
+	if err != nil {
+		log.Printf("fail: recommend, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// HandleGetSimilar: 似ている商品 (GET /items/{id}/similar?user_id=xxx&limit=10)
+func (c *RecommendationController) HandleGetSimilar(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	items, err := c.Usecase.SimilarItems(r.PathValue("id"), r.URL.Query().Get("user_id"), limit)
+	if errors.Is(err, usecase.ErrItemNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("fail: similar items, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+)
+
+type ReportController struct {
+	Usecase *usecase.ReportUsecase
+}
+
+func NewReportController(uc *usecase.ReportUsecase) *ReportController {
+	return &ReportController{Usecase: uc}
+}
+
+// HandleCreateReport: 通報 (POST /reports)
+func (c *ReportController) HandleCreateReport(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID     string             `json:"user_id"`
+		TargetType model.ReportTarget `json:"target_type"`
+		TargetID   string             `json:"target_id"`
+		Reason     model.ReportReason `json:"reason"`
+		Note       string             `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	report := &model.Report{
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		ReporterID: req.UserID,
+		Reason:     req.Reason,
+		Note:       req.Note,
+	}
+	hidden, err := c.Usecase.Report(report)
+	if err != nil {
+		log.Printf("fail: create report, %v\n", err)
+		http.Error(w, err.Error(), reportErrorStatus(err))
+		return
+	}
+	if hidden {
+		log.Printf("Moderation: %s %s hidden by report threshold", report.TargetType, report.TargetID)
+	}
+
+	// 通報者には非表示になったかどうかは返さない
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleGetQueue: モデレーションキュー（管理者のみ） (GET /admin/reports?user_id=xxx&status=open)
+func (c *ReportController) HandleGetQueue(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r.URL.Query().Get("user_id")) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	reports, err := c.Usecase.ReportDAO.List(model.ReportStatus(r.URL.Query().Get("status")), 100)
+	if err != nil {
+		log.Printf("fail: list reports, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if reports == nil {
+		reports = []*model.Report{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// HandleModerate: 通報の担当・解決・却下（管理者のみ） (POST /admin/reports/{id}/claim|resolve|dismiss)
+func (c *ReportController) HandleModerate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"user_id"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !isAdmin(req.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var to model.ReportStatus
+	switch r.PathValue("action") {
+	case "claim":
+		to = model.ReportClaimed
+	case "resolve":
+		to = model.ReportResolved
+	case "dismiss":
+		to = model.ReportDismissed
+	default:
+		http.Error(w, "Unknown action", http.StatusNotFound)
+		return
+	}
+
+	report, err := c.Usecase.Moderate(r.PathValue("id"), req.UserID, to, req.Note)
+	if err != nil {
+		log.Printf("fail: moderate report, %v\n", err)
+		http.Error(w, err.Error(), reportErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleGetAuditLog: モデレーション操作の監査ログ（管理者のみ） (GET /admin/moderation-log?user_id=xxx&target_id=yyy)
+func (c *ReportController) HandleGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r.URL.Query().Get("user_id")) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	actions, err := c.Usecase.ReportDAO.ListActions(r.URL.Query().Get("target_id"), 200)
+	if err != nil {
+		log.Printf("fail: list moderation actions, %v\n", err)
+		http.Error(w, "DB Error", http.StatusInternalServerError)
+		return
+	}
+	if actions == nil {
+		actions = []*model.ModerationAction{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(actions)
+}
+
+// reportErrorStatus: 通報関連のエラーを HTTP ステータスに変換する
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrReportNotFound), errors.Is(err, usecase.ErrTargetNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, model.ErrInvalidReportTarget), errors.Is(err, model.ErrInvalidReportReason):
+		return http.StatusBadRequest
+	case errors.Is(err, dao.ErrReportExists), errors.Is(err, dao.ErrConflict), errors.Is(err, dao.ErrClaimedByOther):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
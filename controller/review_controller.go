@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+)
+
+type ReviewController struct {
+	Usecase *usecase.ReviewUsecase
+}
+
+func NewReviewController(uc *usecase.ReviewUsecase) *ReviewController {
+	return &ReviewController{Usecase: uc}
+}
+
+// HandleSubmit: 取引相手を評価する (POST /orders/{id}/reviews)
+func (c *ReviewController) HandleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID  string      `json:"user_id"`
+		Rating  interface{} `json:"rating"` // 1〜5 または "good" / "normal" / "bad"
+		Comment string      `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	rating, err := model.ParseRating(req.Rating)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review, err := c.Usecase.Submit(r.PathValue("id"), req.UserID, rating, req.Comment)
+	if err != nil {
+		log.Printf("fail: submit review, %v\n", err)
+		http.Error(w, err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(review)
+}
+
+// HandleGetOrderReviews: 取引の評価 (GET /orders/{id}/reviews?user_id=xxx)
+func (c *ReviewController) HandleGetOrderReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := c.Usecase.ListForOrder(r.PathValue("id"), r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: list order reviews, %v\n", err)
+		http.Error(w, err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// reviewErrorStatus: 評価関連のエラーを HTTP ステータスに変換する
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrOrderNotCompleted), errors.Is(err, dao.ErrReviewExists), errors.Is(err, dao.ErrReviewClosed):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
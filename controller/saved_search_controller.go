@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"hackathon-backend/model"
+	"hackathon-backend/usecase"
+	"log"
+	"net/http"
+)
+
+type SavedSearchController struct {
+	Usecase *usecase.SavedSearchUsecase
+}
+
+func NewSavedSearchController(uc *usecase.SavedSearchUsecase) *SavedSearchController {
+	return &SavedSearchController{Usecase: uc}
+}
+
+// HandleCreate: 検索条件を保存する (POST /saved-searches)
+func (c *SavedSearchController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID   string `json:"user_id"`
+		Keyword  string `json:"keyword"`
+		MinPrice int    `json:"min_price"`
+		MaxPrice int    `json:"max_price"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	search := &model.SavedSearch{UserID: req.UserID, Keyword: req.Keyword, MinPrice: req.MinPrice, MaxPrice: req.MaxPrice}
+	if err := c.Usecase.Save(search); err != nil {
+		log.Printf("fail: save search, %v\n", err)
+		http.Error(w, err.Error(), savedSearchErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(search)
+}
+
+// HandleList: 保存した検索条件の一覧 (GET /saved-searches?user_id=xxx)
+func (c *SavedSearchController) HandleList(w http.ResponseWriter, r *http.Request) {
+	searches, err := c.Usecase.List(r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Printf("fail: list saved searches, %v\n", err)
+		http.Error(w, err.Error(), savedSearchErrorStatus(err))
+		return
+	}
+	if searches == nil {
+		searches = []*model.SavedSearch{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(searches)
+}
+
+// HandleDelete: 検索条件を削除する (DELETE /saved-searches/{id}?user_id=xxx)
+func (c *SavedSearchController) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := c.Usecase.Delete(r.PathValue("id"), r.URL.Query().Get("user_id")); err != nil {
+		log.Printf("fail: delete saved search, %v\n", err)
+		http.Error(w, err.Error(), savedSearchErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// savedSearchErrorStatus: 検索条件の保存のエラーを HTTP ステータスに変換する
+func savedSearchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrSavedSearchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrTooManySavedSearches):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrInvalidSavedSearch):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -34,7 +34,7 @@ func (c *SearchUserController) Handle(w http.ResponseWriter, r *http.Request) {
 	users, err := c.Usecase.Execute(name)
 	if err != nil {
 		// Usecaseからエラーが返ってきた（DBエラーなど）
-		log.Printf(err.Error())
+		log.Printf("fail: search user, %v\n", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
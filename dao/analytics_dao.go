@@ -0,0 +1,128 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+type AnalyticsDAO struct {
+	db *sql.DB
+}
+
+func NewAnalyticsDAO(db *sql.DB) *AnalyticsDAO {
+	return &AnalyticsDAO{db: db}
+}
+
+// RecordView: 閲覧を記録する（同じ閲覧者・同じ日の閲覧は1回と数え、重複なら false）
+func (dao *AnalyticsDAO) RecordView(itemID, viewerKey, day string, at time.Time) (bool, error) {
+	_, err := dao.db.Exec("INSERT INTO item_views (item_id, viewer_key, view_date, created_at) VALUES (?, ?, ?, ?)", itemID, viewerKey, day, at)
+	if isDuplicateKey(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to insert item view: %w", err)
+	}
+	return true, nil
+}
+
+// rollupQueries: 日次集計の各項目を商品ごとに数える（引数は集計する日の始まりと翌日の始まり、UTC）
+// 問い合わせは、やりとりに最初のメッセージが送られた日に1件と数える
+// （その日にメッセージがあったやりとりのうち、前日までのメッセージがないもの）
+// likes.created_at は TIMESTAMP で、セッションのタイムゾーンで読まれるため UNIX 時刻で比べる
+var rollupQueries = []struct {
+	name  string
+	query string
+	// unix: 範囲を UNIX 時刻（秒）で渡す
+	unix bool
+	set  func(s *model.ItemDailyStats, n int)
+}{
+	{"views", "SELECT item_id, COUNT(*) FROM item_views WHERE view_date >= ? AND view_date < ? GROUP BY item_id", false,
+		func(s *model.ItemDailyStats, n int) { s.Views = n }},
+	{"likes", "SELECT item_id, COUNT(*) FROM likes WHERE UNIX_TIMESTAMP(created_at) >= ? AND UNIX_TIMESTAMP(created_at) < ? GROUP BY item_id", true,
+		func(s *model.ItemDailyStats, n int) { s.Likes = n }},
+	{"threads", `SELECT c.item_id, COUNT(*) FROM conversations c JOIN (
+			SELECT conversation_id, MIN(created_at) AS first_at FROM messages
+			WHERE created_at >= ? AND created_at < ? GROUP BY conversation_id
+		) m ON m.conversation_id = c.id
+		WHERE NOT EXISTS (SELECT 1 FROM messages p WHERE p.conversation_id = c.id AND p.created_at < m.first_at)
+		GROUP BY c.item_id`, false,
+		func(s *model.ItemDailyStats, n int) { s.Threads = n }},
+	{"orders", "SELECT item_id, COUNT(*) FROM orders WHERE created_at >= ? AND created_at < ? AND status <> 'cancelled' GROUP BY item_id", false,
+		func(s *model.ItemDailyStats, n int) { s.Orders = n }},
+}
+
+// Rollup: day（YYYY-MM-DD、UTC）の集計を作り直す（何度実行しても同じ結果になる）
+func (dao *AnalyticsDAO) Rollup(day string) (int, error) {
+	from, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return 0, err
+	}
+	to := from.AddDate(0, 0, 1)
+
+	stats := map[string]*model.ItemDailyStats{}
+	for _, q := range rollupQueries {
+		args := []interface{}{from, to}
+		if q.unix {
+			args = []interface{}{from.Unix(), to.Unix()}
+		}
+		rows, err := dao.db.Query(q.query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("rollup %s: %w", q.name, err)
+		}
+		for rows.Next() {
+			var itemID string
+			var n int
+			if err := rows.Scan(&itemID, &n); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			if stats[itemID] == nil {
+				stats[itemID] = &model.ItemDailyStats{ItemID: itemID, Day: day}
+			}
+			q.set(stats[itemID], n)
+		}
+		rows.Close()
+	}
+
+	err = withTx(dao.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM item_daily_stats WHERE day = ?", day); err != nil {
+			return err
+		}
+		query := `INSERT INTO item_daily_stats (item_id, seller_id, day, views, likes, threads, orders)
+			SELECT id, seller_id, ?, ?, ?, ?, ? FROM items WHERE id = ?`
+		for _, s := range stats {
+			if _, err := tx.Exec(query, day, s.Views, s.Likes, s.Threads, s.Orders, s.ItemID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(stats), err
+}
+
+// ListSellerStats: 出品者の商品の日次集計（from〜to）と、商品IDから商品名への対応
+func (dao *AnalyticsDAO) ListSellerStats(sellerID, from, to string) ([]*model.ItemDailyStats, map[string]string, error) {
+	query := `SELECT s.item_id, s.seller_id, DATE_FORMAT(s.day, '%Y-%m-%d'), s.views, s.likes, s.threads, s.orders, COALESCE(i.name, '')
+		FROM item_daily_stats s LEFT JOIN items i ON i.id = s.item_id
+		WHERE s.seller_id = ? AND s.day BETWEEN ? AND ? ORDER BY s.day ASC`
+	rows, err := dao.db.Query(query, sellerID, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var stats []*model.ItemDailyStats
+	names := map[string]string{}
+	for rows.Next() {
+		var s model.ItemDailyStats
+		var name string
+		if err := rows.Scan(&s.ItemID, &s.SellerID, &s.Day, &s.Views, &s.Likes, &s.Threads, &s.Orders, &name); err != nil {
+			return nil, nil, err
+		}
+		names[s.ItemID] = name
+		stats = append(stats, &s)
+	}
+	return stats, names, rows.Err()
+}
@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+	"strings"
+	"time"
+)
+
+// ErrAttachmentUnavailable: 添付できない画像（別のやりとり・他人のアップロード・添付済み）
+var ErrAttachmentUnavailable = errors.New("attachment is not available")
+
+type AttachmentDAO struct {
+	db *sql.DB
+}
+
+func NewAttachmentDAO(db *sql.DB) *AttachmentDAO {
+	return &AttachmentDAO{db: db}
+}
+
+const attachmentColumns = "id, conversation_id, message_id, uploader_id, content_type, size, width, height, has_thumbnail, created_at"
+
+// Insert: アップロードした画像を登録する（まだどのメッセージにも結びつけない）
+func (dao *AttachmentDAO) Insert(a *model.Attachment) error {
+	query := "INSERT INTO attachments (" + attachmentColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := dao.db.Exec(query, a.ID, a.ConversationID, a.MessageID, a.UploaderID, a.ContentType, a.Size, a.Width, a.Height, a.HasThumbnail, a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert attachment: %w", err)
+	}
+	return nil
+}
+
+// GetByID: 添付を1件取得（見つからない場合は nil）
+func (dao *AttachmentDAO) GetByID(id string) (*model.Attachment, error) {
+	rows, err := dao.db.Query("SELECT "+attachmentColumns+" FROM attachments WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanAttachments(rows)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// ListByMessages: メッセージごとの添付（アップロード順）
+func (dao *AttachmentDAO) ListByMessages(messageIDs []string) (map[string][]*model.Attachment, error) {
+	byMessage := map[string][]*model.Attachment{}
+	if len(messageIDs) == 0 {
+		return byMessage, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(messageIDs)), ", ")
+	args := make([]interface{}, len(messageIDs))
+	for i, id := range messageIDs {
+		args[i] = id
+	}
+	rows, err := dao.db.Query("SELECT "+attachmentColumns+" FROM attachments WHERE message_id IN ("+placeholders+") ORDER BY created_at ASC, id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range list {
+		byMessage[a.MessageID] = append(byMessage[a.MessageID], a)
+	}
+	return byMessage, nil
+}
+
+// ListUnattached: before より前にアップロードされ、送信されなかった添付
+func (dao *AttachmentDAO) ListUnattached(before time.Time, limit int) ([]*model.Attachment, error) {
+	rows, err := dao.db.Query("SELECT "+attachmentColumns+" FROM attachments WHERE message_id = '' AND created_at < ? ORDER BY created_at ASC LIMIT ?", before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAttachments(rows)
+}
+
+// DeleteUnattached: 送信されなかった添付を削除する（その間に送信されていれば消さずに false）
+func (dao *AttachmentDAO) DeleteUnattached(id string) (bool, error) {
+	res, err := dao.db.Exec("DELETE FROM attachments WHERE id = ? AND message_id = ''", id)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// attachToMessage: アップロード済みの添付をメッセージに結びつける（メッセージの保存と同じトランザクションで）
+func attachToMessage(tx *sql.Tx, msg *model.Message) error {
+	for _, a := range msg.Attachments {
+		res, err := tx.Exec("UPDATE attachments SET message_id = ? WHERE id = ? AND conversation_id = ? AND uploader_id = ? AND message_id = ''",
+			msg.ID, a.ID, msg.ConversationID, msg.SenderID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrAttachmentUnavailable
+		}
+		a.MessageID = msg.ID
+	}
+	return nil
+}
+
+func scanAttachments(rows *sql.Rows) ([]*model.Attachment, error) {
+	var list []*model.Attachment
+	for rows.Next() {
+		var a model.Attachment
+		if err := rows.Scan(&a.ID, &a.ConversationID, &a.MessageID, &a.UploaderID, &a.ContentType, &a.Size, &a.Width, &a.Height, &a.HasThumbnail, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.SetURLs()
+		list = append(list, &a)
+	}
+	return list, rows.Err()
+}
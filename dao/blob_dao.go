@@ -0,0 +1,35 @@
+package dao
+
+import (
+	"database/sql"
+	"hackathon-backend/upload"
+	"time"
+)
+
+// BlobDAO: アップロードしたファイルを DB に保存する（複数サーバーで共有するための upload.Store）
+type BlobDAO struct {
+	db *sql.DB
+}
+
+func NewBlobDAO(db *sql.DB) *BlobDAO {
+	return &BlobDAO{db: db}
+}
+
+func (dao *BlobDAO) Put(key string, data []byte) error {
+	_, err := dao.db.Exec("INSERT INTO blobs (blob_key, data, created_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE data = VALUES(data)", key, data, time.Now())
+	return err
+}
+
+func (dao *BlobDAO) Get(key string) ([]byte, error) {
+	var data []byte
+	err := dao.db.QueryRow("SELECT data FROM blobs WHERE blob_key = ?", key).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, upload.ErrNotFound
+	}
+	return data, err
+}
+
+func (dao *BlobDAO) Delete(key string) error {
+	_, err := dao.db.Exec("DELETE FROM blobs WHERE blob_key = ?", key)
+	return err
+}
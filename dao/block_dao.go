@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"database/sql"
+	"hackathon-backend/model"
+	"time"
+)
+
+type BlockDAO struct {
+	db *sql.DB
+}
+
+func NewBlockDAO(db *sql.DB) *BlockDAO {
+	return &BlockDAO{db: db}
+}
+
+// blockedBetween: 2人のどちらかがもう一方をブロックしているかの条件（引数は a, b の順）
+const blockedBetween = `EXISTS(SELECT 1 FROM blocks bl
+	WHERE (bl.blocker_id = ? AND bl.blocked_id = ?) OR (bl.blocker_id = ? AND bl.blocked_id = ?))`
+
+// Block: ブロックする（既にブロックしていれば何もしない）
+func (dao *BlockDAO) Block(blockerID, blockedID string, at time.Time) error {
+	_, err := dao.db.Exec("INSERT IGNORE INTO blocks (blocker_id, blocked_id, created_at) VALUES (?, ?, ?)", blockerID, blockedID, at)
+	return err
+}
+
+// Unblock: ブロックを解除する（ブロックしていたかを返す）
+func (dao *BlockDAO) Unblock(blockerID, blockedID string) (bool, error) {
+	res, err := dao.db.Exec("DELETE FROM blocks WHERE blocker_id = ? AND blocked_id = ?", blockerID, blockedID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// IsBlockedBetween: 2人のどちらかがもう一方をブロックしているか
+func (dao *BlockDAO) IsBlockedBetween(a, b string) (bool, error) {
+	var blocked bool
+	err := dao.db.QueryRow("SELECT "+blockedBetween, a, b, b, a).Scan(&blocked)
+	return blocked, err
+}
+
+// ListBlockedWith: userID がブロックしている・userID をブロックしているユーザーの ID
+func (dao *BlockDAO) ListBlockedWith(userID string) ([]string, error) {
+	return queryIDs(dao.db, "SELECT blocked_id FROM blocks WHERE blocker_id = ? UNION SELECT blocker_id FROM blocks WHERE blocked_id = ?", userID, userID)
+}
+
+// ListByBlocker: ブロックしているユーザーの一覧（新しい順）
+func (dao *BlockDAO) ListByBlocker(blockerID string) ([]*model.Block, error) {
+	rows, err := dao.db.Query(`SELECT b.blocker_id, b.blocked_id, COALESCE(u.name, ''), b.created_at
+		FROM blocks b LEFT JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = ? ORDER BY b.created_at DESC`, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*model.Block
+	for rows.Next() {
+		var b model.Block
+		if err := rows.Scan(&b.BlockerID, &b.BlockedID, &b.BlockedName, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &b)
+	}
+	return list, rows.Err()
+}
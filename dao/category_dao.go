@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+)
+
+var (
+	// ErrCategoryExists: 同じ slug のカテゴリが既にある
+	ErrCategoryExists = errors.New("category already exists")
+	// ErrCategoryNotFound: カテゴリが見つからない
+	ErrCategoryNotFound = errors.New("category not found")
+)
+
+type CategoryDAO struct {
+	db *sql.DB
+}
+
+func NewCategoryDAO(db *sql.DB) *CategoryDAO {
+	return &CategoryDAO{db: db}
+}
+
+// SeedIfEmpty: カテゴリが1件もなければ初期カテゴリを登録する（管理者の変更は上書きしない）
+func (dao *CategoryDAO) SeedIfEmpty(list []*model.Category) (bool, error) {
+	var n int
+	if err := dao.db.QueryRow("SELECT COUNT(*) FROM categories").Scan(&n); err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return false, nil
+	}
+	err := withTx(dao.db, func(tx *sql.Tx) error {
+		for _, c := range list {
+			if err := insertCategory(tx, c); err != nil && !errors.Is(err, ErrCategoryExists) {
+				return err
+			}
+		}
+		return nil
+	})
+	return err == nil, err
+}
+
+// List: すべてのカテゴリ
+func (dao *CategoryDAO) List() ([]*model.Category, error) {
+	rows, err := dao.db.Query("SELECT slug, parent_slug, name_ja, name_en, aliases, sort_order FROM categories ORDER BY sort_order, slug")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*model.Category
+	for rows.Next() {
+		var c model.Category
+		var aliases sql.NullString
+		if err := rows.Scan(&c.Slug, &c.ParentSlug, &c.NameJa, &c.NameEn, &aliases, &c.SortOrder); err != nil {
+			return nil, err
+		}
+		c.Aliases = decodeTags(aliases)
+		list = append(list, &c)
+	}
+	return list, rows.Err()
+}
+
+// Insert: カテゴリを登録する
+func (dao *CategoryDAO) Insert(c *model.Category) error {
+	return insertCategory(dao.db, c)
+}
+
+func insertCategory(db execer, c *model.Category) error {
+	aliases, _ := json.Marshal(c.Aliases)
+	_, err := db.Exec("INSERT INTO categories (slug, parent_slug, name_ja, name_en, aliases, sort_order) VALUES (?, ?, ?, ?, ?, ?)",
+		c.Slug, c.ParentSlug, c.NameJa, c.NameEn, string(aliases), c.SortOrder)
+	if isDuplicateKey(err) {
+		return ErrCategoryExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert category: %w", err)
+	}
+	return nil
+}
+
+// Update: カテゴリの親・名前・別名・並び順を更新する（slug は変えない）
+func (dao *CategoryDAO) Update(c *model.Category) error {
+	aliases, _ := json.Marshal(c.Aliases)
+	res, err := dao.db.Exec("UPDATE categories SET parent_slug = ?, name_ja = ?, name_en = ?, aliases = ?, sort_order = ? WHERE slug = ?",
+		c.ParentSlug, c.NameJa, c.NameEn, string(aliases), c.SortOrder, c.Slug)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		var exists bool
+		if err := dao.db.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE slug = ?)", c.Slug).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrCategoryNotFound
+		}
+	}
+	return nil
+}
+
+// Delete: カテゴリを削除し、子カテゴリを newParent の下へ、商品を itemCategory へ移す
+func (dao *CategoryDAO) Delete(slug, newParent, itemCategory string) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec("DELETE FROM categories WHERE slug = ?", slug)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrCategoryNotFound
+		}
+		if _, err := tx.Exec("UPDATE categories SET parent_slug = ? WHERE parent_slug = ?", newParent, slug); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE items SET category = ? WHERE category = ?", itemCategory, slug)
+		return err
+	})
+}
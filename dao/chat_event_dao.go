@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+// ChatEventDAO: 複数サーバー間でチャットのイベントを中継するためのログ（chathub.EventLog）
+type ChatEventDAO struct {
+	db *sql.DB
+}
+
+func NewChatEventDAO(db *sql.DB) *ChatEventDAO {
+	return &ChatEventDAO{db: db}
+}
+
+// Append: イベントを記録する
+func (dao *ChatEventDAO) Append(ev model.ChatEvent) error {
+	_, err := dao.db.Exec("INSERT INTO chat_events (topic, event_id, type, data, created_at) VALUES (?, ?, ?, ?, ?)",
+		ev.Topic, ev.ID, ev.Type, string(ev.Data), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to insert chat event: %w", err)
+	}
+	return nil
+}
+
+// LastSeq: 記録済みの最後の通し番号
+func (dao *ChatEventDAO) LastSeq() (int64, error) {
+	var seq int64
+	err := dao.db.QueryRow("SELECT COALESCE(MAX(seq), 0) FROM chat_events").Scan(&seq)
+	return seq, err
+}
+
+// After: seq より後のイベントを通し番号の順に最大 limit 件
+func (dao *ChatEventDAO) After(seq int64, limit int) ([]model.ChatEvent, error) {
+	rows, err := dao.db.Query("SELECT seq, topic, event_id, type, data FROM chat_events WHERE seq > ? ORDER BY seq ASC LIMIT ?", seq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []model.ChatEvent
+	for rows.Next() {
+		var ev model.ChatEvent
+		var data string
+		if err := rows.Scan(&ev.Seq, &ev.Topic, &ev.ID, &ev.Type, &data); err != nil {
+			return nil, err
+		}
+		ev.Data = []byte(data)
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// DeleteBefore: t より前に記録したイベントを消す（購読中のサーバーはすでに受け取っている）
+func (dao *ChatEventDAO) DeleteBefore(t time.Time) (int64, error) {
+	res, err := dao.db.Exec("DELETE FROM chat_events WHERE created_at < ?", t)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
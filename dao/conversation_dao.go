@@ -0,0 +1,299 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+type ConversationDAO struct {
+	db *sql.DB
+}
+
+func NewConversationDAO(db *sql.DB) *ConversationDAO {
+	// ★自動修復機能: 既読管理のためのカラムを追加（最後のメッセージと、当事者それぞれが読んだ最後のメッセージの通し番号）
+	_, _ = db.Exec("ALTER TABLE conversations ADD COLUMN message_seq INT NOT NULL DEFAULT 0")
+	_, _ = db.Exec("ALTER TABLE conversations ADD COLUMN buyer_read_seq INT NOT NULL DEFAULT 0")
+	_, _ = db.Exec("ALTER TABLE conversations ADD COLUMN seller_read_seq INT NOT NULL DEFAULT 0")
+
+	return &ConversationDAO{db: db}
+}
+
+// conversationNotBlocked: 当事者の間にブロックがないやりとりだけにする条件
+const conversationNotBlocked = `NOT EXISTS(SELECT 1 FROM blocks bl
+	WHERE (bl.blocker_id = c.buyer_id AND bl.blocked_id = c.seller_id) OR (bl.blocker_id = c.seller_id AND bl.blocked_id = c.buyer_id))`
+
+const conversationColumns = "c.id, c.item_id, c.buyer_id, c.seller_id, c.created_at, c.updated_at, c.message_seq, c.buyer_read_seq, c.seller_read_seq"
+
+// GetOrCreate: 商品と購入希望者のやりとりを取得し、なければ作る
+func (dao *ConversationDAO) GetOrCreate(c *model.Conversation) (*model.Conversation, error) {
+	_, err := dao.db.Exec("INSERT INTO conversations (id, item_id, buyer_id, seller_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		c.ID, c.ItemID, c.BuyerID, c.SellerID, c.CreatedAt, c.UpdatedAt)
+	if err != nil && !isDuplicateKey(err) {
+		return nil, fmt.Errorf("failed to insert conversation: %w", err)
+	}
+	return dao.GetByItemAndBuyer(c.ItemID, c.BuyerID)
+}
+
+// GetByID: やりとりを1件取得（見つからない場合は nil）
+func (dao *ConversationDAO) GetByID(id string) (*model.Conversation, error) {
+	return dao.getOne("SELECT "+conversationColumns+" FROM conversations c WHERE c.id = ?", id)
+}
+
+// GetByItemAndBuyer: 商品と購入希望者のやりとりを取得（見つからない場合は nil）
+func (dao *ConversationDAO) GetByItemAndBuyer(itemID, buyerID string) (*model.Conversation, error) {
+	return dao.getOne("SELECT "+conversationColumns+" FROM conversations c WHERE c.item_id = ? AND c.buyer_id = ?", itemID, buyerID)
+}
+
+func (dao *ConversationDAO) getOne(query string, args ...interface{}) (*model.Conversation, error) {
+	var c model.Conversation
+	err := dao.db.QueryRow(query, args...).Scan(&c.ID, &c.ItemID, &c.BuyerID, &c.SellerID, &c.CreatedAt, &c.UpdatedAt, &c.MessageSeq, &c.BuyerReadSeq, &c.SellerReadSeq)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListByUser: ユーザーの受信箱（新しくメッセージが届いた順、最後のメッセージと商品名つき）
+// 出品者には、購入希望者がまだ何も送っていないやりとりは見せない。ブロックのあるやりとりも見せない
+func (dao *ConversationDAO) ListByUser(userID string, limit int) ([]*model.Conversation, error) {
+	query := "SELECT " + conversationColumns + `, COALESCE(i.name, ''), COALESCE(i.image_url, ''),
+		m.id, m.sender_id, m.content, m.created_at, m.unsent_at IS NOT NULL
+		FROM conversations c
+		LEFT JOIN items i ON i.id = c.item_id
+		LEFT JOIN messages m ON m.id = c.last_message_id AND m.hidden = FALSE
+		WHERE (c.buyer_id = ? OR (c.seller_id = ? AND c.last_message_id <> '')) AND ` + conversationNotBlocked + `
+		ORDER BY c.updated_at DESC, c.id DESC LIMIT ?`
+	rows, err := dao.db.Query(query, userID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*model.Conversation
+	for rows.Next() {
+		var c model.Conversation
+		var msgID, senderID, content sql.NullString
+		var createdAt sql.NullTime
+		var unsent sql.NullBool
+		if err := rows.Scan(&c.ID, &c.ItemID, &c.BuyerID, &c.SellerID, &c.CreatedAt, &c.UpdatedAt, &c.MessageSeq, &c.BuyerReadSeq, &c.SellerReadSeq, &c.ItemName, &c.ItemImageURL,
+			&msgID, &senderID, &content, &createdAt, &unsent); err != nil {
+			return nil, err
+		}
+		if msgID.Valid {
+			c.LastMessage = &model.Message{ID: msgID.String, ConversationID: c.ID, ItemID: c.ItemID,
+				SenderID: senderID.String, Content: content.String, CreatedAt: createdAt.Time, Unsent: unsent.Bool}
+		}
+		list = append(list, &c)
+	}
+	return list, rows.Err()
+}
+
+// MarkRead: userID の既読位置を seq まで進める（戻すことはなく、最後のメッセージを超えることもない）
+func (dao *ConversationDAO) MarkRead(conversationID, userID string, seq int) error {
+	_, err := dao.db.Exec(`UPDATE conversations SET
+		buyer_read_seq = IF(buyer_id = ?, GREATEST(buyer_read_seq, LEAST(?, message_seq)), buyer_read_seq),
+		seller_read_seq = IF(seller_id = ?, GREATEST(seller_read_seq, LEAST(?, message_seq)), seller_read_seq)
+		WHERE id = ?`, userID, seq, userID, seq, conversationID)
+	return err
+}
+
+// CountUnread: ユーザーの未読メッセージの合計と、未読のあるやりとりの数（ブロックのあるやりとりは数えない）
+func (dao *ConversationDAO) CountUnread(userID string) (int, int, error) {
+	query := `SELECT COALESCE(SUM(n), 0), COUNT(*) FROM (
+			SELECT IF(buyer_id = ?, message_seq - buyer_read_seq, message_seq - seller_read_seq) AS n
+			FROM conversations c WHERE (c.buyer_id = ? OR c.seller_id = ?) AND ` + conversationNotBlocked + `
+		) t WHERE n > 0`
+	var total, conversations int
+	err := dao.db.QueryRow(query, userID, userID, userID).Scan(&total, &conversations)
+	return total, conversations, err
+}
+
+// BackfillSeq: 通し番号のないメッセージがあるやりとりを、作成日時の順に振り直す
+// 既読管理を始める前のメッセージなので、当事者ともに既読として扱う
+func (dao *ConversationDAO) BackfillSeq() (int, error) {
+	rows, err := dao.db.Query("SELECT DISTINCT conversation_id FROM messages WHERE seq = 0 AND conversation_id <> ''")
+	if err != nil {
+		return 0, err
+	}
+	var convIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		convIDs = append(convIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, convID := range convIDs {
+		err := withTx(dao.db, func(tx *sql.Tx) error {
+			// 送信と同時に振り直さないよう、やりとりの行をロックする
+			var current int
+			if err := tx.QueryRow("SELECT message_seq FROM conversations WHERE id = ? FOR UPDATE", convID).Scan(&current); err != nil {
+				return err
+			}
+			msgIDs, err := queryIDs(tx, "SELECT id FROM messages WHERE conversation_id = ? ORDER BY created_at ASC, id ASC", convID)
+			if err != nil {
+				return err
+			}
+			for i, id := range msgIDs {
+				if _, err := tx.Exec("UPDATE messages SET seq = ? WHERE id = ?", i+1, id); err != nil {
+					return err
+				}
+			}
+			n := len(msgIDs)
+			_, err = tx.Exec("UPDATE conversations SET message_seq = ?, buyer_read_seq = ?, seller_read_seq = ? WHERE id = ?", n, n, n, convID)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(convIDs), nil
+}
+
+// ListBetween: a と b の（どちらが購入希望者でも）やりとりの ID
+func (dao *ConversationDAO) ListBetween(a, b string) ([]string, error) {
+	return queryIDs(dao.db, "SELECT id FROM conversations WHERE (buyer_id = ? AND seller_id = ?) OR (buyer_id = ? AND seller_id = ?)", a, b, b, a)
+}
+
+// queryer: *sql.DB と *sql.Tx のどちらでも問い合わせられるようにする
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func queryIDs(db queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListLegacyItemIDs: やりとりに振り分けていないメッセージがある商品
+func (dao *ConversationDAO) ListLegacyItemIDs() ([]string, error) {
+	rows, err := dao.db.Query("SELECT DISTINCT item_id FROM messages WHERE conversation_id = ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListLegacyMessages: 商品のやりとりに振り分けていないメッセージ（非表示のものも含む）
+func (dao *ConversationDAO) ListLegacyMessages(itemID string) ([]*model.Message, error) {
+	rows, err := dao.db.Query("SELECT "+messageColumns+" FROM messages m WHERE m.item_id = ? AND m.conversation_id = ''", itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// AssignLegacy: 商品のメッセージを購入希望者ごとのやりとりに移す（assigned はメッセージ ID → 購入希望者 ID）
+// 既にやりとりがあればそれに追加し、最終メッセージと更新日時も合わせる
+func (dao *ConversationDAO) AssignLegacy(itemID, sellerID string, assigned map[string]string, at time.Time) (int, error) {
+	byBuyer := map[string][]string{}
+	for msgID, buyerID := range assigned {
+		byBuyer[buyerID] = append(byBuyer[buyerID], msgID)
+	}
+
+	err := withTx(dao.db, func(tx *sql.Tx) error {
+		for buyerID, msgIDs := range byBuyer {
+			_, err := tx.Exec("INSERT IGNORE INTO conversations (id, item_id, buyer_id, seller_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+				model.NewID(), itemID, buyerID, sellerID, at, at)
+			if err != nil {
+				return err
+			}
+			var convID string
+			if err := tx.QueryRow("SELECT id FROM conversations WHERE item_id = ? AND buyer_id = ?", itemID, buyerID).Scan(&convID); err != nil {
+				return err
+			}
+			for _, msgID := range msgIDs {
+				if _, err := tx.Exec("UPDATE messages SET conversation_id = ? WHERE id = ? AND conversation_id = ''", convID, msgID); err != nil {
+					return err
+				}
+			}
+
+			// 最後のメッセージとその日時
+			var lastID string
+			var lastAt time.Time
+			err = tx.QueryRow("SELECT id, created_at FROM messages WHERE conversation_id = ? ORDER BY created_at DESC, id DESC LIMIT 1", convID).Scan(&lastID, &lastAt)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec("UPDATE conversations SET last_message_id = ?, updated_at = ? WHERE id = ?", lastID, lastAt, convID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(assigned), nil
+}
+
+// ListClosedBefore: 終わったやりとり（商品が売り切れ・取り下げ・削除済み）のうち、before より後に動きのないものを最大 limit 件
+// 未対応の通報があるメッセージを含むやりとりは、モデレーターが確認するまで残す
+func (dao *ConversationDAO) ListClosedBefore(before time.Time, limit int) ([]string, error) {
+	query := `SELECT c.id FROM conversations c LEFT JOIN items i ON i.id = c.item_id
+		WHERE c.updated_at < ? AND (i.id IS NULL OR i.sold_out = TRUE OR i.status IN (?, ?))
+		AND NOT EXISTS(SELECT 1 FROM reports r JOIN messages m ON m.id = r.target_id
+			WHERE r.target_type = ? AND r.status IN (?, ?) AND m.conversation_id = c.id)
+		ORDER BY c.updated_at ASC LIMIT ?`
+	return queryIDs(dao.db, query, before, model.ItemSold, model.ItemWithdrawn,
+		model.ReportTargetMessage, model.ReportOpen, model.ReportClaimed, limit)
+}
+
+// Purge: やりとりとそのメッセージ・本文の履歴を削除し、削除したメッセージ数を返す
+// 添付は送信前の状態に戻すので、未送信の添付の掃除で画像ごと消える（当事者はやりとりがないので見られない）
+func (dao *ConversationDAO) Purge(conversationID string) (int, error) {
+	var n int64
+	err := withTx(dao.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE r FROM message_revisions r JOIN messages m ON m.id = r.message_id
+			WHERE m.conversation_id = ?`, conversationID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE attachments SET message_id = '' WHERE conversation_id = ?", conversationID); err != nil {
+			return err
+		}
+		res, err := tx.Exec("DELETE FROM messages WHERE conversation_id = ?", conversationID)
+		if err != nil {
+			return err
+		}
+		n, _ = res.RowsAffected()
+		_, err = tx.Exec("DELETE FROM conversations WHERE id = ?", conversationID)
+		return err
+	})
+	return int(n), err
+}
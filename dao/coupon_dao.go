@@ -0,0 +1,189 @@
+package dao
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+var (
+	// ErrCouponExists: 同じコードのクーポンが既にある
+	ErrCouponExists = errors.New("coupon already exists")
+	// ErrCouponNotFound: クーポンが見つからない
+	ErrCouponNotFound = errors.New("coupon not found")
+)
+
+type CouponDAO struct {
+	db *sql.DB
+}
+
+func NewCouponDAO(db *sql.DB) *CouponDAO {
+	return &CouponDAO{db: db}
+}
+
+const couponColumns = `code, description, discount_type, amount, max_discount, min_price, categories, first_purchase_only,
+	starts_at, ends_at, max_redemptions, max_per_user, redeemed_count, active, created_at`
+
+// Create: クーポンを登録する
+func (dao *CouponDAO) Create(c *model.Coupon) error {
+	categories, _ := json.Marshal(c.Categories)
+	_, err := dao.db.Exec("INSERT INTO coupons ("+couponColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?)",
+		c.Code, c.Description, c.DiscountType, c.Amount, c.MaxDiscount, c.MinPrice, string(categories), c.FirstPurchaseOnly,
+		c.StartsAt, c.EndsAt, c.MaxRedemptions, c.MaxPerUser, c.Active, c.CreatedAt)
+	if isDuplicateKey(err) {
+		return ErrCouponExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert coupon: %w", err)
+	}
+	return nil
+}
+
+// Update: クーポンの条件を更新する（コードと利用数は変えない）
+func (dao *CouponDAO) Update(c *model.Coupon) error {
+	categories, _ := json.Marshal(c.Categories)
+	res, err := dao.db.Exec(`UPDATE coupons SET description = ?, discount_type = ?, amount = ?, max_discount = ?, min_price = ?, categories = ?,
+		first_purchase_only = ?, starts_at = ?, ends_at = ?, max_redemptions = ?, max_per_user = ?, active = ? WHERE code = ?`,
+		c.Description, c.DiscountType, c.Amount, c.MaxDiscount, c.MinPrice, string(categories),
+		c.FirstPurchaseOnly, c.StartsAt, c.EndsAt, c.MaxRedemptions, c.MaxPerUser, c.Active, c.Code)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		existing, err := dao.GetByCode(c.Code)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrCouponNotFound
+		}
+	}
+	return nil
+}
+
+// GetByCode: クーポンを1件取得（見つからない場合は nil）
+func (dao *CouponDAO) GetByCode(code string) (*model.Coupon, error) {
+	rows, err := dao.db.Query("SELECT "+couponColumns+" FROM coupons WHERE code = ?", code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons, err := scanCoupons(rows)
+	if err != nil || len(coupons) == 0 {
+		return nil, err
+	}
+	return coupons[0], nil
+}
+
+// List: すべてのクーポン（新しい順）
+func (dao *CouponDAO) List() ([]*model.Coupon, error) {
+	rows, err := dao.db.Query("SELECT " + couponColumns + " FROM coupons ORDER BY created_at DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCoupons(rows)
+}
+
+// CountRedemptions: ユーザーがクーポンを使った回数
+func (dao *CouponDAO) CountRedemptions(code, userID string) (int, error) {
+	var n int
+	err := dao.db.QueryRow("SELECT COUNT(*) FROM coupon_redemptions WHERE code = ? AND user_id = ?", code, userID).Scan(&n)
+	return n, err
+}
+
+// HasPurchased: ユーザーに（キャンセルされていない）購入履歴があるか
+func (dao *CouponDAO) HasPurchased(userID string) (bool, error) {
+	var exists bool
+	err := dao.db.QueryRow("SELECT EXISTS(SELECT 1 FROM orders WHERE buyer_id = ? AND status <> ?)", userID, model.OrderStatusCancelled).Scan(&exists)
+	return exists, err
+}
+
+func scanCoupons(rows *sql.Rows) ([]*model.Coupon, error) {
+	var coupons []*model.Coupon
+	for rows.Next() {
+		var c model.Coupon
+		var categories sql.NullString
+		var startsAt, endsAt sql.NullTime
+		if err := rows.Scan(&c.Code, &c.Description, &c.DiscountType, &c.Amount, &c.MaxDiscount, &c.MinPrice, &categories, &c.FirstPurchaseOnly,
+			&startsAt, &endsAt, &c.MaxRedemptions, &c.MaxPerUser, &c.RedeemedCount, &c.Active, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.Categories = decodeTags(categories)
+		c.StartsAt = nullTimePtr(startsAt)
+		c.EndsAt = nullTimePtr(endsAt)
+		coupons = append(coupons, &c)
+	}
+	return coupons, rows.Err()
+}
+
+// redeemCoupon: 注文のトランザクション内でクーポンの利用を記録する
+// クーポンの行をロックしてから上限を確認するので、同時に使われても上限を超えない
+func redeemCoupon(tx *sql.Tx, order *model.Order, at time.Time) error {
+	var active, firstOnly bool
+	var maxTotal, maxPerUser, redeemed int
+	err := tx.QueryRow("SELECT active, first_purchase_only, max_redemptions, max_per_user, redeemed_count FROM coupons WHERE code = ? FOR UPDATE",
+		order.CouponCode).Scan(&active, &firstOnly, &maxTotal, &maxPerUser, &redeemed)
+	if err == sql.ErrNoRows {
+		return ErrCouponNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !active {
+		return model.ErrCouponNotActive
+	}
+	if maxTotal > 0 && redeemed >= maxTotal {
+		return model.ErrCouponExhausted
+	}
+
+	// 同じクーポンの利用はこの時点で直列化されている（ロック読み取りで最新の状態を見る）
+	if maxPerUser > 0 {
+		var used int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM coupon_redemptions WHERE code = ? AND user_id = ? FOR UPDATE",
+			order.CouponCode, order.BuyerID).Scan(&used); err != nil {
+			return err
+		}
+		if used >= maxPerUser {
+			return model.ErrCouponUserLimit
+		}
+	}
+	if firstOnly {
+		var orders int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM orders WHERE buyer_id = ? AND status <> ? FOR UPDATE",
+			order.BuyerID, model.OrderStatusCancelled).Scan(&orders); err != nil {
+			return err
+		}
+		if orders > 0 {
+			return model.ErrCouponFirstPurchaseOnly
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE coupons SET redeemed_count = redeemed_count + 1 WHERE code = ?", order.CouponCode); err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO coupon_redemptions (id, code, user_id, order_id, discount, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		model.NewID(), order.CouponCode, order.BuyerID, order.ID, order.Discount, at)
+	if err != nil {
+		return fmt.Errorf("failed to insert coupon redemption: %w", err)
+	}
+	return nil
+}
+
+// releaseCoupon: キャンセルされた注文のクーポン利用を取り消し、もう一度使えるようにする
+func releaseCoupon(tx *sql.Tx, order *model.Order) error {
+	res, err := tx.Exec("DELETE FROM coupon_redemptions WHERE order_id = ?", order.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+	_, err = tx.Exec("UPDATE coupons SET redeemed_count = redeemed_count - 1 WHERE code = ? AND redeemed_count > 0", order.CouponCode)
+	return err
+}
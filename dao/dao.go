@@ -1 +1,34 @@
 package dao
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrConflict: 条件付き更新で対象行が見つからなかった（他のリクエストに先を越された）
+	ErrConflict = errors.New("conflict")
+	// ErrItemUnavailable: 売り切れなどで購入できない
+	ErrItemUnavailable = errors.New("item is not available")
+)
+
+// withTx: fn をトランザクション内で実行し、エラーならロールバックする
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// isDuplicateKey: UNIQUE 制約違反 (MySQL 1062) かどうか
+func isDuplicateKey(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == 1062
+}
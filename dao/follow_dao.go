@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"database/sql"
+)
+
+type FollowDAO struct {
+	db *sql.DB
+}
+
+func NewFollowDAO(db *sql.DB) *FollowDAO {
+	return &FollowDAO{db: db}
+}
+
+// ToggleFollow: 出品者のフォローを付けたり外したりする（いいねと同じスイッチ式）
+func (dao *FollowDAO) ToggleFollow(followerID, sellerID string) (bool, error) {
+	var exists bool
+	queryCheck := "SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = ? AND seller_id = ?)"
+	if err := dao.db.QueryRow(queryCheck, followerID, sellerID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	if exists {
+		_, err := dao.db.Exec("DELETE FROM follows WHERE follower_id = ? AND seller_id = ?", followerID, sellerID)
+		if err != nil {
+			return false, err
+		}
+		return false, nil // "フォロー解除"
+	}
+
+	// 同時に押された場合も二重登録にしない
+	_, err := dao.db.Exec("INSERT IGNORE INTO follows (follower_id, seller_id) VALUES (?, ?)", followerID, sellerID)
+	if err != nil {
+		return false, err
+	}
+	return true, nil // "フォロー"
+}
+
+// GetFollowingIDs: そのユーザーがフォローしている出品者のID一覧
+func (dao *FollowDAO) GetFollowingIDs(followerID string) ([]string, error) {
+	rows, err := dao.db.Query("SELECT seller_id FROM follows WHERE follower_id = ? ORDER BY created_at DESC", followerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CountFollowers: 出品者のフォロワー数
+func (dao *FollowDAO) CountFollowers(sellerID string) (int, error) {
+	var n int
+	err := dao.db.QueryRow("SELECT COUNT(*) FROM follows WHERE seller_id = ?", sellerID).Scan(&n)
+	return n, err
+}
+
+// IsFollowing: followerID が sellerID をフォローしているか
+func (dao *FollowDAO) IsFollowing(followerID, sellerID string) (bool, error) {
+	var exists bool
+	err := dao.db.QueryRow("SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = ? AND seller_id = ?)", followerID, sellerID).Scan(&exists)
+	return exists, err
+}
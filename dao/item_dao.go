@@ -2,24 +2,60 @@ package dao
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"hackathon-backend/model"
 	"log"
+	"strings"
+	"time"
 )
 
 type ItemDAO struct {
 	DB *sql.DB
 }
 
+// itemSelect: scanItems と対応する SELECT 句（出品者の評価を結合する）
+const itemSelect = `SELECT items.id, items.name, items.price, items.description, items.sold_out, items.image_url, items.like_count,
+	items.seller_id, items.reserved_for, items.reserved_price, items.reserved_until, items.hidden,
+	items.status, items.publish_at, items.published_at, items.created_at, items.category, items.tags, items.external_sku,
+	COALESCE(rep.rating_sum, 0), COALESCE(rep.rating_count, 0)
+	FROM items LEFT JOIN user_reputation rep ON rep.user_id = items.seller_id`
+
 func NewItemDAO(db *sql.DB) *ItemDAO {
 	// ★自動修復機能: アプリ起動時に「like_count」カラムがなければ勝手に追加する
 	_, _ = db.Exec("ALTER TABLE items ADD COLUMN like_count INT DEFAULT 0")
+	// 出品者 (取引の相手方を決めるために必要)
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN seller_id VARCHAR(255) NOT NULL DEFAULT ''")
+	// 値下げ交渉で合意した購入者のための確保
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN reserved_for VARCHAR(255) NOT NULL DEFAULT ''")
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN reserved_price INT NOT NULL DEFAULT 0")
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN reserved_until DATETIME NULL")
+	// 通報が一定数を超えた・違反と判断された商品は非表示
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE")
+	// 出品の状態（下書き・公開中・取り下げ・売り切れ）と予約公開
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published'")
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN publish_at DATETIME NULL")
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN published_at DATETIME NULL")
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN created_at DATETIME NULL")
+	// 似ている商品を探すためのカテゴリとタグ（タグは JSON 配列）
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN category VARCHAR(255) NOT NULL DEFAULT ''")
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN tags TEXT NULL")
+	_, _ = db.Exec("CREATE INDEX idx_items_category ON items (category)")
+	// 一括出品の重複防止（出品者ごとの外部 SKU、SKU なしは NULL）
+	_, _ = db.Exec("ALTER TABLE items ADD COLUMN external_sku VARCHAR(255) NULL")
+	_, _ = db.Exec("CREATE UNIQUE INDEX uq_items_seller_sku ON items (seller_id, external_sku)")
+	_, _ = db.Exec("CREATE INDEX idx_items_status_publish_at ON items (status, publish_at)")
+	// 出品者ごとの一覧（ストアフロント）
+	_, _ = db.Exec("CREATE INDEX idx_items_seller_status ON items (seller_id, status)")
+	// status 追加前に売れた商品を揃える
+	_, _ = db.Exec("UPDATE items SET status = 'sold' WHERE sold_out = TRUE AND status = 'published'")
 
 	return &ItemDAO{DB: db}
 }
 
 // GetAll: 商品一覧取得
 func (d *ItemDAO) GetAll() ([]*model.Item, error) {
-	query := "SELECT id, name, price, description, sold_out, image_url, like_count FROM items"
+	query := itemSelect + " WHERE items.hidden = FALSE AND items.status = 'published'"
 
 	rows, err := d.DB.Query(query)
 	if err != nil {
@@ -33,7 +69,7 @@ func (d *ItemDAO) GetAll() ([]*model.Item, error) {
 
 // Search: 検索機能
 func (d *ItemDAO) Search(keyword string) ([]*model.Item, error) {
-	query := "SELECT id, name, price, description, sold_out, image_url, like_count FROM items WHERE name LIKE ?"
+	query := itemSelect + " WHERE items.hidden = FALSE AND items.status = 'published' AND items.name LIKE ?"
 	searchTerm := "%" + keyword + "%"
 
 	rows, err := d.DB.Query(query, searchTerm)
@@ -46,35 +82,377 @@ func (d *ItemDAO) Search(keyword string) ([]*model.Item, error) {
 	return d.scanItems(rows)
 }
 
+// GetByID: 商品を1件取得（見つからない場合は nil、非表示の商品も返す）
+func (d *ItemDAO) GetByID(id string) (*model.Item, error) {
+	rows, err := d.DB.Query(itemSelect+" WHERE items.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items, err := d.scanItems(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
 // scanItems: データを読み込む共通処理
 func (d *ItemDAO) scanItems(rows *sql.Rows) ([]*model.Item, error) {
 	var items []*model.Item
 	for rows.Next() {
-		item := &model.Item{}
-		var imageURL sql.NullString
-
-		// like_count を読み込む
-		if err := rows.Scan(&item.ID, &item.Name, &item.Price, &item.Description, &item.SoldOut, &imageURL, &item.LikeCount); err != nil {
+		item, err := scanItem(rows)
+		if err != nil {
 			return nil, err
 		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// scanItem: itemSelect の1行を読み込む
+func scanItem(rows *sql.Rows) (*model.Item, error) {
+	item := &model.Item{}
+	var imageURL sql.NullString
+	var reservedUntil, publishAt, publishedAt, createdAt sql.NullTime
+	var tags, sku sql.NullString
+	var ratingSum int
 
-		if imageURL.Valid {
-			item.ImageURL = imageURL.String
+	// like_count を読み込む
+	if err := rows.Scan(&item.ID, &item.Name, &item.Price, &item.Description, &item.SoldOut, &imageURL, &item.LikeCount, &item.SellerID,
+		&item.ReservedFor, &item.ReservedPrice, &reservedUntil, &item.Hidden,
+		&item.Status, &publishAt, &publishedAt, &createdAt, &item.Category, &tags, &sku, &ratingSum, &item.SellerReviewCount); err != nil {
+		return nil, err
+	}
+	item.ReservedUntil = nullTimePtr(reservedUntil)
+	item.PublishAt = nullTimePtr(publishAt)
+	item.PublishedAt = nullTimePtr(publishedAt)
+	item.CreatedAt = nullTimePtr(createdAt)
+	item.Tags = decodeTags(tags)
+	item.ExternalSKU = sku.String
+	item.SellerRating = model.RatingAverage(ratingSum, item.SellerReviewCount)
+
+	if imageURL.Valid {
+		item.ImageURL = imageURL.String
+	}
+	return item, nil
+}
+
+// ItemStreamFilter: Stream で読む商品の条件
+type ItemStreamFilter struct {
+	SellerID      string // 指定すると、その出品者の商品（PublishedOnly でなければ下書き・取り下げ・売り切れも含む）
+	PublishedOnly bool   // 公開中で非表示でない商品だけ
+	Limit         int    // 0 なら全件
+}
+
+// Stream: 条件に合う商品を新しい順に1件ずつ fn に渡す（全件をメモリに載せない）
+// fn がエラーを返したらそこで止める
+func (d *ItemDAO) Stream(filter ItemStreamFilter, fn func(*model.Item) error) error {
+	query := itemSelect + " WHERE 1 = 1"
+	var args []interface{}
+	if filter.SellerID != "" {
+		query += " AND items.seller_id = ?"
+		args = append(args, filter.SellerID)
+	}
+	if filter.PublishedOnly {
+		query += " AND items.status = 'published' AND items.hidden = FALSE"
+	}
+	query += " ORDER BY COALESCE(items.published_at, items.created_at) DESC, items.id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := d.DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
 		}
-		items = append(items, item)
 	}
-	return items, nil
+	return rows.Err()
 }
 
-// Purchase: 購入処理
-func (d *ItemDAO) Purchase(id string) error {
-	_, err := d.DB.Exec("UPDATE items SET sold_out = TRUE WHERE id = ?", id)
-	return err
+// encodeTags / decodeTags: タグを JSON 配列として保存する
+func encodeTags(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(tags)
+	return string(b)
+}
+
+func decodeTags(s sql.NullString) []string {
+	var tags []string
+	if s.Valid && s.String != "" {
+		_ = json.Unmarshal([]byte(s.String), &tags)
+	}
+	return tags
 }
 
-// Insert: 商品登録
+// ErrDuplicateSKU: 同じ出品者が同じ外部 SKU の商品を登録済み
+var ErrDuplicateSKU = errors.New("item with this sku already exists")
+
+// Insert: 商品登録（Status が空なら公開中として登録する）
 func (d *ItemDAO) Insert(item *model.Item) error {
-	query := "INSERT INTO items (id, name, price, description, sold_out, image_url, like_count) VALUES (?, ?, ?, ?, ?, ?, 0)"
-	_, err := d.DB.Exec(query, item.ID, item.Name, item.Price, item.Description, false, item.ImageURL)
+	if item.Status == "" {
+		item.Status = model.ItemPublished
+	}
+	var sku interface{}
+	if item.ExternalSKU != "" {
+		sku = item.ExternalSKU
+	}
+	query := `INSERT INTO items (id, name, price, description, sold_out, image_url, like_count, seller_id, status, publish_at, published_at, created_at, category, tags, external_sku)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.DB.Exec(query, item.ID, item.Name, item.Price, item.Description, false, item.ImageURL, item.SellerID,
+		item.Status, item.PublishAt, item.PublishedAt, item.CreatedAt, item.Category, encodeTags(item.Tags), sku)
+	if isDuplicateKey(err) && sku != nil {
+		return ErrDuplicateSKU
+	}
 	return err
 }
+
+// ListBySeller: 出品者の商品を状態で絞り込んで新しい順に取得
+func (d *ItemDAO) ListBySeller(sellerID string, status model.ItemStatus) ([]*model.Item, error) {
+	query := itemSelect + " WHERE items.seller_id = ? AND items.status = ? ORDER BY items.created_at DESC"
+	rows, err := d.DB.Query(query, sellerID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanItems(rows)
+}
+
+// UpdateDraft: 下書きの内容と予約公開日時を更新する（下書きでなくなっていたら ErrConflict）
+func (d *ItemDAO) UpdateDraft(item *model.Item) error {
+	query := `UPDATE items SET name = ?, price = ?, description = ?, image_url = ?, category = ?, tags = ?, publish_at = ?
+		WHERE id = ? AND seller_id = ? AND status = 'draft'`
+	res, err := d.DB.Exec(query, item.Name, item.Price, item.Description, item.ImageURL, item.Category, encodeTags(item.Tags),
+		item.PublishAt, item.ID, item.SellerID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// DeleteDraft: 下書きを削除する（公開済みの商品は消せない）
+func (d *ItemDAO) DeleteDraft(id, sellerID string) error {
+	res, err := d.DB.Exec("DELETE FROM items WHERE id = ? AND seller_id = ? AND status = 'draft'", id, sellerID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// SetStatus: 現在の状態が from の場合に限り to へ更新する（公開時は published_at も記録）
+func (d *ItemDAO) SetStatus(id string, from, to model.ItemStatus, at time.Time) error {
+	query := "UPDATE items SET status = ?, publish_at = NULL WHERE id = ? AND status = ?"
+	args := []interface{}{to, id, from}
+	if to == model.ItemPublished {
+		query = "UPDATE items SET status = ?, publish_at = NULL, published_at = ? WHERE id = ? AND status = ?"
+		args = []interface{}{to, at, id, from}
+	}
+	res, err := d.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// ListDueDrafts: 予約公開日時を過ぎた下書きを取得
+func (d *ItemDAO) ListDueDrafts(now time.Time) ([]*model.Item, error) {
+	query := itemSelect + " WHERE items.status = 'draft' AND items.publish_at IS NOT NULL AND items.publish_at <= ? ORDER BY items.publish_at ASC"
+	rows, err := d.DB.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanItems(rows)
+}
+
+// ListPublishedSince: since 以降に公開された商品を公開順に取得（新着通知用）
+func (d *ItemDAO) ListPublishedSince(since time.Time) ([]*model.Item, error) {
+	query := itemSelect + " WHERE items.status = 'published' AND items.hidden = FALSE AND items.published_at >= ? ORDER BY items.published_at ASC, items.id ASC"
+	rows, err := d.DB.Query(query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanItems(rows)
+}
+
+// UpdateListing: 公開中・取り下げ中の商品の内容を更新し、価格が変わったら履歴に残す
+// 価格が oldPrice から変わっていた（同時に編集された）場合は ErrConflict
+func (d *ItemDAO) UpdateListing(item *model.Item, oldPrice int, at time.Time) error {
+	return withTx(d.DB, func(tx *sql.Tx) error {
+		query := `UPDATE items SET name = ?, price = ?, description = ?, image_url = ?, category = ?, tags = ?
+			WHERE id = ? AND seller_id = ? AND status IN ('published', 'withdrawn') AND price = ?`
+		res, err := tx.Exec(query, item.Name, item.Price, item.Description, item.ImageURL, item.Category, encodeTags(item.Tags),
+			item.ID, item.SellerID, oldPrice)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrConflict
+		}
+		if item.Price == oldPrice {
+			return nil
+		}
+		_, err = tx.Exec("INSERT INTO item_price_history (item_id, old_price, new_price, changed_at) VALUES (?, ?, ?, ?)",
+			item.ID, oldPrice, item.Price, at)
+		return err
+	})
+}
+
+// ListPriceHistory: 商品の価格変更の履歴を古い順に取得
+func (d *ItemDAO) ListPriceHistory(itemID string) ([]*model.PriceChange, error) {
+	rows, err := d.DB.Query("SELECT item_id, old_price, new_price, changed_at FROM item_price_history WHERE item_id = ? ORDER BY changed_at ASC, id ASC", itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*model.PriceChange
+	for rows.Next() {
+		var c model.PriceChange
+		if err := rows.Scan(&c.ItemID, &c.OldPrice, &c.NewPrice, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, &c)
+	}
+	return history, rows.Err()
+}
+
+// FindBySKUs: 出品者の登録済みの外部 SKU と商品IDの対応
+func (d *ItemDAO) FindBySKUs(sellerID string, skus []string) (map[string]string, error) {
+	found := map[string]string{}
+	if len(skus) == 0 {
+		return found, nil
+	}
+	args := []interface{}{sellerID}
+	for _, sku := range skus {
+		args = append(args, sku)
+	}
+	query := "SELECT external_sku, id FROM items WHERE seller_id = ? AND external_sku IN (?" + strings.Repeat(", ?", len(skus)-1) + ")"
+	rows, err := d.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sku, id string
+		if err := rows.Scan(&sku, &id); err != nil {
+			return nil, err
+		}
+		found[sku] = id
+	}
+	return found, rows.Err()
+}
+
+// ListByCategories: カテゴリ（slug の一覧）に属する公開中の商品（keyword があれば商品名でも絞り込む）
+func (d *ItemDAO) ListByCategories(categories []string, keyword string) ([]*model.Item, error) {
+	if len(categories) == 0 {
+		return nil, nil
+	}
+	query := itemSelect + " WHERE items.hidden = FALSE AND items.status = 'published' AND items.category IN (?" + strings.Repeat(", ?", len(categories)-1) + ")"
+	var args []interface{}
+	for _, c := range categories {
+		args = append(args, c)
+	}
+	if keyword != "" {
+		query += " AND items.name LIKE ?"
+		args = append(args, "%"+keyword+"%")
+	}
+
+	rows, err := d.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanItems(rows)
+}
+
+// itemListedAt: 新着順の並びに使う日時（model.Item.ListedAt と同じ）
+const itemListedAt = "COALESCE(items.published_at, items.created_at, '1970-01-01 00:00:00')"
+
+// listPage: 条件に合う商品を新着順に limit+1 件取得する（cursor があればその続きから）
+func (d *ItemDAO) listPage(join, where string, args []interface{}, cursor *model.ItemCursor, limit int) ([]*model.Item, error) {
+	query := itemSelect + join + " WHERE " + where
+	if cursor != nil {
+		query += " AND (" + itemListedAt + " < ? OR (" + itemListedAt + " = ? AND items.id < ?))"
+		args = append(args, cursor.At, cursor.At, cursor.ID)
+	}
+	query += " ORDER BY " + itemListedAt + " DESC, items.id DESC LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := d.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanItems(rows)
+}
+
+// ListStorefront: 出品者のショップに並べる商品（公開中 or 売り切れ）を新着順に取得
+func (d *ItemDAO) ListStorefront(sellerID string, status model.ItemStatus, cursor *model.ItemCursor, limit int) ([]*model.Item, error) {
+	return d.listPage("", "items.seller_id = ? AND items.status = ? AND items.hidden = FALSE",
+		[]interface{}{sellerID, status}, cursor, limit)
+}
+
+// ListFeed: フォロー中の出品者の公開中の商品を新着順に取得
+func (d *ItemDAO) ListFeed(followerID string, cursor *model.ItemCursor, limit int) ([]*model.Item, error) {
+	return d.listPage(" JOIN follows f ON f.seller_id = items.seller_id",
+		"f.follower_id = ? AND items.status = 'published' AND items.hidden = FALSE",
+		[]interface{}{followerID}, cursor, limit)
+}
+
+// DistinctCategories: 商品に使われているカテゴリの値
+func (d *ItemDAO) DistinctCategories() ([]string, error) {
+	rows, err := d.DB.Query("SELECT DISTINCT category FROM items WHERE category <> ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// RenameCategory: カテゴリの値をまとめて置き換える
+func (d *ItemDAO) RenameCategory(from, to string) (int64, error) {
+	res, err := d.DB.Exec("UPDATE items SET category = ? WHERE category = ?", to, from)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
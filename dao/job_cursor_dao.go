@@ -0,0 +1,33 @@
+package dao
+
+import (
+	"database/sql"
+	"time"
+)
+
+// JobCursorDAO: 定期ジョブがどこまで処理したかを記録する（再起動しても続きから処理するため）
+type JobCursorDAO struct {
+	db *sql.DB
+}
+
+func NewJobCursorDAO(db *sql.DB) *JobCursorDAO {
+	return &JobCursorDAO{db: db}
+}
+
+// Get: ジョブの処理済み時刻（未実行なら ok = false）
+func (dao *JobCursorDAO) Get(name string) (at time.Time, ok bool, err error) {
+	err = dao.db.QueryRow("SELECT cursor_at FROM job_cursors WHERE name = ?", name).Scan(&at)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return at, true, nil
+}
+
+// Set: ジョブの処理済み時刻を更新する
+func (dao *JobCursorDAO) Set(name string, at time.Time) error {
+	_, err := dao.db.Exec("INSERT INTO job_cursors (name, cursor_at) VALUES (?, ?) ON DUPLICATE KEY UPDATE cursor_at = VALUES(cursor_at)", name, at)
+	return err
+}
@@ -0,0 +1,193 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInsufficientBalance: 振込申請額が残高を超えている
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrPayoutNotFound: 振込申請が存在しない
+	ErrPayoutNotFound = errors.New("payout not found")
+	// ErrPayoutAlreadyPaid: 振込申請はすでに送金済み
+	ErrPayoutAlreadyPaid = errors.New("payout is already paid")
+)
+
+type LedgerDAO struct {
+	db *sql.DB
+}
+
+func NewLedgerDAO(db *sql.DB) *LedgerDAO {
+	// 既存のテーブルに送金日時の列を追加（すでにあればエラーになるので無視）
+	_, _ = db.Exec("ALTER TABLE payout_requests ADD COLUMN paid_at DATETIME NULL")
+	return &LedgerDAO{db: db}
+}
+
+// insertLedgerEntries: 仕訳をまとめて登録する（呼び出し側のトランザクション内で使う）
+func insertLedgerEntries(tx *sql.Tx, entries []*model.LedgerEntry) error {
+	query := "INSERT INTO ledger_entries (id, txn_id, account, amount, entry_type, order_id, payout_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	for _, e := range entries {
+		if _, err := tx.Exec(query, e.ID, e.TxnID, e.Account, e.Amount, e.EntryType, e.OrderID, e.PayoutID, e.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert ledger entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// Balance: 勘定の残高
+func (dao *LedgerDAO) Balance(account string) (int, error) {
+	var balance int
+	err := dao.db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account = ?", account).Scan(&balance)
+	return balance, err
+}
+
+// ListEntries: 勘定の仕訳を新しい順に取得
+func (dao *LedgerDAO) ListEntries(account string, limit int) ([]*model.LedgerEntry, error) {
+	query := "SELECT id, txn_id, account, amount, entry_type, order_id, payout_id, created_at FROM ledger_entries WHERE account = ? ORDER BY created_at DESC, id DESC LIMIT ?"
+	rows, err := dao.db.Query(query, account, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.LedgerEntry
+	for rows.Next() {
+		var e model.LedgerEntry
+		if err := rows.Scan(&e.ID, &e.TxnID, &e.Account, &e.Amount, &e.EntryType, &e.OrderID, &e.PayoutID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// RequestPayout: 残高を確認して振込申請を登録し、残高から差し引く
+func (dao *LedgerDAO) RequestPayout(p *model.PayoutRequest) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		// FOR UPDATE で同じ出品者の仕訳をロックし、同時申請で残高を超えないようにする
+		var balance int
+		query := "SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE account = ? FOR UPDATE"
+		if err := tx.QueryRow(query, model.SellerAccount(p.SellerID)).Scan(&balance); err != nil {
+			return err
+		}
+		if p.Amount > balance {
+			return ErrInsufficientBalance
+		}
+
+		if _, err := tx.Exec("INSERT INTO payout_requests (id, seller_id, amount, status, created_at) VALUES (?, ?, ?, ?, ?)",
+			p.ID, p.SellerID, p.Amount, p.Status, p.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert payout request: %w", err)
+		}
+		return insertLedgerEntries(tx, model.PayoutEntries(p))
+	})
+}
+
+// ListPayouts: 出品者の振込申請を新しい順に取得
+func (dao *LedgerDAO) ListPayouts(sellerID string) ([]*model.PayoutRequest, error) {
+	rows, err := dao.db.Query("SELECT id, seller_id, amount, status, created_at, paid_at FROM payout_requests WHERE seller_id = ? ORDER BY created_at DESC", sellerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []*model.PayoutRequest
+	for rows.Next() {
+		var p model.PayoutRequest
+		var paidAt sql.NullTime
+		if err := rows.Scan(&p.ID, &p.SellerID, &p.Amount, &p.Status, &p.CreatedAt, &paidAt); err != nil {
+			return nil, err
+		}
+		if paidAt.Valid {
+			p.PaidAt = &paidAt.Time
+		}
+		payouts = append(payouts, &p)
+	}
+	return payouts, rows.Err()
+}
+
+// MarkPayoutPaid: 振込申請を送金済みにし、未送金の勘定から外部へ移す
+func (dao *LedgerDAO) MarkPayoutPaid(id string, at time.Time) (*model.PayoutRequest, error) {
+	var p model.PayoutRequest
+	err := withTx(dao.db, func(tx *sql.Tx) error {
+		query := "SELECT id, seller_id, amount, status, created_at FROM payout_requests WHERE id = ? FOR UPDATE"
+		err := tx.QueryRow(query, id).Scan(&p.ID, &p.SellerID, &p.Amount, &p.Status, &p.CreatedAt)
+		if err == sql.ErrNoRows {
+			return ErrPayoutNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if p.Status == model.PayoutPaid {
+			return ErrPayoutAlreadyPaid
+		}
+
+		if _, err := tx.Exec("UPDATE payout_requests SET status = ?, paid_at = ? WHERE id = ?", model.PayoutPaid, at, p.ID); err != nil {
+			return fmt.Errorf("failed to update payout request: %w", err)
+		}
+		p.Status = model.PayoutPaid
+		p.PaidAt = &at
+		return insertLedgerEntries(tx, model.PayoutPaidEntries(&p, at))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Reconcile: 仕訳全体の整合性を確認する
+func (dao *LedgerDAO) Reconcile() (*model.LedgerReport, error) {
+	report := &model.LedgerReport{
+		UnbalancedTxns: map[string]int{},
+		Accounts:       map[string]int{},
+	}
+
+	// 1. 取引ごとの合計
+	rows, err := dao.db.Query("SELECT txn_id, SUM(amount) FROM ledger_entries GROUP BY txn_id HAVING SUM(amount) <> 0")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var txnID string
+		var sum int
+		if err := rows.Scan(&txnID, &sum); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.UnbalancedTxns[txnID] = sum
+	}
+	rows.Close()
+
+	// 2. 勘定ごとの残高（出品者はまとめて集計）
+	rows, err = dao.db.Query("SELECT account, SUM(amount) FROM ledger_entries GROUP BY account")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var account string
+		var sum int
+		if err := rows.Scan(&account, &sum); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.Total += sum
+		if strings.HasPrefix(account, model.SellerAccount("")) {
+			account = "sellers"
+		}
+		report.Accounts[account] += sum
+	}
+	rows.Close()
+
+	// 3. 預かり勘定と、預かり中の注文の合計を突き合わせる
+	report.EscrowBalance = report.Accounts[model.AccountEscrow]
+	if err := dao.db.QueryRow("SELECT COALESCE(SUM(price), 0) FROM orders WHERE escrow_status = ?", model.EscrowHeld).Scan(&report.HeldOrdersTotal); err != nil {
+		return nil, err
+	}
+
+	report.OK = report.Total == 0 && len(report.UnbalancedTxns) == 0 && report.EscrowBalance == report.HeldOrdersTotal
+	return report, nil
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"database/sql"
+	"hackathon-backend/model"
 )
 
 type LikeDAO struct {
@@ -12,52 +13,42 @@ func NewLikeDAO(db *sql.DB) *LikeDAO {
 	return &LikeDAO{db: db}
 }
 
-// ToggleLike: いいねを付けたり消したりする（スイッチ機能）
-func (dao *LikeDAO) ToggleLike(userID, itemID string) (bool, error) {
-	// 1. まず、既にいいねしているか確認
-	var exists bool
-	queryCheck := "SELECT EXISTS(SELECT 1 FROM likes WHERE user_id = ? AND item_id = ?)"
-	err := dao.db.QueryRow(queryCheck, userID, itemID).Scan(&exists)
-	if err != nil {
-		return false, err
-	}
-
-	if exists {
-		// --- 既にいいね済みなら -> 解除 (Unlike) ---
-
-		// A. likesテーブルから削除
-		_, err := dao.db.Exec("DELETE FROM likes WHERE user_id = ? AND item_id = ?", userID, itemID)
+// Like: いいねを付ける（すでに付いていれば何もしない）
+// 登録できたときだけ items のいいね数(like_count)を +1 するので、同時に押されても数がずれない
+func (dao *LikeDAO) Like(userID, itemID string) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec("INSERT IGNORE INTO likes (user_id, item_id) VALUES (?, ?)", userID, itemID)
 		if err != nil {
-			return false, err
+			return err
 		}
-
-		// B. itemsテーブルのいいね数(like_count)を -1 する
-		// ★ここが「数が保存されない」を直すための重要な行です！
-		_, err = dao.db.Exec("UPDATE items SET like_count = like_count - 1 WHERE id = ?", itemID)
-		if err != nil {
-			return false, err
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
 		}
+		_, err = tx.Exec("UPDATE items SET like_count = like_count + 1 WHERE id = ?", itemID)
+		return err
+	})
+}
 
-		return false, nil // "いいね解除"
-
-	} else {
-		// --- まだいいねしてないなら -> 登録 (Like) ---
-
-		// A. likesテーブルに登録
-		_, err := dao.db.Exec("INSERT INTO likes (user_id, item_id) VALUES (?, ?)", userID, itemID)
+// Unlike: いいねを外す（付いていなければ何もしない）
+func (dao *LikeDAO) Unlike(userID, itemID string) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec("DELETE FROM likes WHERE user_id = ? AND item_id = ?", userID, itemID)
 		if err != nil {
-			return false, err
+			return err
 		}
-
-		// B. itemsテーブルのいいね数(like_count)を +1 する
-		// ★ここが「数が保存されない」を直すための重要な行です！
-		_, err = dao.db.Exec("UPDATE items SET like_count = like_count + 1 WHERE id = ?", itemID)
-		if err != nil {
-			return false, err
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
 		}
+		_, err = tx.Exec("UPDATE items SET like_count = like_count - 1 WHERE id = ?", itemID)
+		return err
+	})
+}
 
-		return true, nil // "いいね"
-	}
+// IsLiked: そのユーザーが商品にいいねしているか
+func (dao *LikeDAO) IsLiked(userID, itemID string) (bool, error) {
+	var exists bool
+	err := dao.db.QueryRow("SELECT EXISTS(SELECT 1 FROM likes WHERE user_id = ? AND item_id = ?)", userID, itemID).Scan(&exists)
+	return exists, err
 }
 
 // GetLikedItemIDs: そのユーザーがいいねした商品のID一覧を取得
@@ -78,3 +69,41 @@ func (dao *LikeDAO) GetLikedItemIDs(userID string) ([]string, error) {
 	}
 	return ids, nil
 }
+
+// GetLikerIDs: その商品にいいねしたユーザーのID一覧を取得
+func (dao *LikeDAO) GetLikerIDs(itemID string) ([]string, error) {
+	rows, err := dao.db.Query("SELECT user_id FROM likes WHERE item_id = ?", itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetAll: すべてのいいねを取得（おすすめの計算用）
+func (dao *LikeDAO) GetAll() ([]model.Like, error) {
+	rows, err := dao.db.Query("SELECT user_id, item_id FROM likes")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var likes []model.Like
+	for rows.Next() {
+		var l model.Like
+		if err := rows.Scan(&l.UserID, &l.ItemID); err != nil {
+			return nil, err
+		}
+		likes = append(likes, l)
+	}
+	return likes, rows.Err()
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"hackathon-backend/model"
+	"log"
+	"time"
 )
 
 type MessageDAO struct {
@@ -11,36 +13,264 @@ type MessageDAO struct {
 }
 
 func NewMessageDAO(db *sql.DB) *MessageDAO {
+	// ★自動修復機能: 通報で非表示にするためのカラムを追加
+	_, _ = db.Exec("ALTER TABLE messages ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE")
+	// 購入希望者ごとのやりとり（空のものは商品ごとの共有チャット時代のメッセージ）
+	_, _ = db.Exec("ALTER TABLE messages ADD COLUMN conversation_id VARCHAR(255) NOT NULL DEFAULT ''")
+	_, _ = db.Exec("CREATE INDEX idx_messages_conversation ON messages (conversation_id, created_at, id)")
+	// やりとりの中での通し番号（既読の判定に使う）
+	_, _ = db.Exec("ALTER TABLE messages ADD COLUMN seq INT NOT NULL DEFAULT 0")
+	// 編集・送信取り消しの日時（取り消したメッセージは本文を空にして跡だけ残す）
+	_, _ = db.Exec("ALTER TABLE messages ADD COLUMN edited_at DATETIME(6) NULL")
+	_, _ = db.Exec("ALTER TABLE messages ADD COLUMN unsent_at DATETIME(6) NULL")
+	// 日次集計で、その日のメッセージだけを読むため
+	_, _ = db.Exec("CREATE INDEX idx_messages_created ON messages (created_at)")
+
 	return &MessageDAO{db: db}
 }
 
-// 取得: created_at の昇順（古い順）で取得
-func (dao *MessageDAO) GetByItemID(itemID string) ([]*model.Message, error) {
-	query := "SELECT id, item_id, sender_id, content, created_at FROM messages WHERE item_id = ? ORDER BY created_at ASC"
+const messageColumns = "m.id, m.conversation_id, m.item_id, m.sender_id, m.content, m.created_at, m.seq, m.edited_at, m.unsent_at IS NOT NULL, m.hidden"
+
+// GetByID: メッセージを1件取得（通報で非表示にしたものも含む。なければ nil）
+func (dao *MessageDAO) GetByID(id string) (*model.Message, error) {
+	rows, err := dao.db.Query("SELECT "+messageColumns+" FROM messages m WHERE m.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+// ListPage: やりとりのメッセージを最大 limit 件、古い順に取得（作成日時、同じ時刻は ID の順）
+// before を指定するとそれより古いもの、after を指定するとそれより新しいもの、どちらもなければ最新のもの。
+// 戻り値の bool は、取得した範囲の先（before・指定なしなら古い側、after なら新しい側）にまだメッセージがあるか
+func (dao *MessageDAO) ListPage(conversationID, before, after string, limit int) ([]*model.Message, bool, error) {
+	where := "m.conversation_id = ? AND m.hidden = FALSE"
+	args := []interface{}{conversationID}
+	order := "DESC"
+	if cursor := before + after; cursor != "" {
+		at, err := dao.cursorTime(conversationID, cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		if after != "" {
+			where += " AND (m.created_at > ? OR (m.created_at = ? AND m.id > ?))"
+			order = "ASC"
+		} else {
+			where += " AND (m.created_at < ? OR (m.created_at = ? AND m.id < ?))"
+		}
+		args = append(args, at, at, cursor)
+	}
+	query := "SELECT " + messageColumns + " FROM messages m WHERE " + where +
+		" ORDER BY m.created_at " + order + ", m.id " + order + " LIMIT ?"
+
+	rows, err := dao.db.Query(query, append(args, limit+1)...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, false, err
+	}
+	more := len(messages) > limit
+	if more {
+		messages = messages[:limit]
+	}
+	if order == "DESC" {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+	return messages, more, nil
+}
+
+// cursorTime: カーソル（メッセージ ID）の作成日時。やりとりにないメッセージなら model.ErrInvalidCursor
+func (dao *MessageDAO) cursorTime(conversationID, messageID string) (time.Time, error) {
+	var at time.Time
+	err := dao.db.QueryRow("SELECT created_at FROM messages WHERE id = ? AND conversation_id = ?", messageID, conversationID).Scan(&at)
+	if err == sql.ErrNoRows {
+		return at, model.ErrInvalidCursor
+	}
+	return at, err
+}
+
+// ListAfter: afterID のメッセージより後のメッセージを古い順に取得（リアルタイム配信の再接続用）
+// afterID が見つからない場合は空
+func (dao *MessageDAO) ListAfter(conversationID, afterID string) ([]*model.Message, error) {
+	query := "SELECT " + messageColumns + ` FROM messages m
+		JOIN messages last ON last.id = ? AND last.conversation_id = m.conversation_id
+		WHERE m.conversation_id = ? AND m.hidden = FALSE
+		AND (m.created_at > last.created_at OR (m.created_at = last.created_at AND m.id > last.id))
+		ORDER BY m.created_at ASC, m.id ASC`
 
-	rows, err := dao.db.Query(query, itemID)
+	rows, err := dao.db.Query(query, afterID, conversationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanMessages(rows)
+}
+
+// MigrateCreatedAt: 文字列（サーバーのローカル時刻・秒単位）で保存していた created_at を UTC の DATETIME(6) に移す
+// 移し終えていれば何もしない。途中で止まっても、次の起動で残りから続ける
+func (dao *MessageDAO) MigrateCreatedAt() (int, error) {
+	var dataType string
+	err := dao.db.QueryRow(`SELECT DATA_TYPE FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'created_at'`).Scan(&dataType)
+	if err != nil {
+		return 0, err
+	}
+	if dataType == "datetime" {
+		return 0, nil
+	}
+
+	_, _ = dao.db.Exec("ALTER TABLE messages ADD COLUMN created_at_utc DATETIME(6) NULL")
+	rows, err := dao.db.Query("SELECT id, COALESCE(created_at, '') FROM messages WHERE created_at_utc IS NULL")
+	if err != nil {
+		return 0, err
+	}
+	legacy := map[string]string{}
+	for rows.Next() {
+		var id, createdAt string
+		if err := rows.Scan(&id, &createdAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		legacy[id] = createdAt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, createdAt := range legacy {
+		at, err := time.ParseInLocation("2006-01-02 15:04:05", createdAt, time.Local)
+		if err != nil {
+			// 読めない日時は並び順の先頭に置く
+			log.Printf("message %s has unreadable created_at %q, using epoch", id, createdAt)
+			at = time.Unix(0, 0)
+		}
+		if _, err := dao.db.Exec("UPDATE messages SET created_at_utc = ? WHERE id = ?", at.UTC(), id); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = dao.db.Exec("ALTER TABLE messages DROP COLUMN created_at, CHANGE COLUMN created_at_utc created_at DATETIME(6) NOT NULL")
+	if err != nil {
+		return 0, err
+	}
+	// created_at を作り直したので索引も張り直す
+	_, _ = dao.db.Exec("DROP INDEX idx_messages_conversation ON messages")
+	_, _ = dao.db.Exec("CREATE INDEX idx_messages_conversation ON messages (conversation_id, created_at, id)")
+	_, _ = dao.db.Exec("CREATE INDEX idx_messages_created ON messages (created_at)")
+	return len(legacy), nil
+}
+
+func scanMessages(rows *sql.Rows) ([]*model.Message, error) {
 	var messages []*model.Message
 	for rows.Next() {
 		var m model.Message
-		if err := rows.Scan(&m.ID, &m.ItemID, &m.SenderID, &m.Content, &m.CreatedAt); err != nil {
+		var editedAt sql.NullTime
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.ItemID, &m.SenderID, &m.Content, &m.CreatedAt, &m.Seq, &editedAt, &m.Unsent, &m.Hidden); err != nil {
 			return nil, err
 		}
+		if editedAt.Valid {
+			m.EditedAt = &editedAt.Time
+		}
 		messages = append(messages, &m)
 	}
-	return messages, nil
+	return messages, rows.Err()
+}
+
+// 保存: メッセージを保存し、やりとりの最終メッセージ・通し番号・更新日時を進める
+// 通し番号はやりとりの行をロックして振るので、同時に送られても重複・欠番しない。
+// 送信者は自分のメッセージまでを読んだことにする。添付も同じトランザクションでメッセージに結びつける
+func (dao *MessageDAO) Insert(msg *model.Message, at time.Time) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		var seq int
+		if err := tx.QueryRow("SELECT message_seq FROM conversations WHERE id = ? FOR UPDATE", msg.ConversationID).Scan(&seq); err != nil {
+			return err
+		}
+		msg.Seq = seq + 1
+
+		query := "INSERT INTO messages (id, conversation_id, item_id, sender_id, content, created_at, seq) VALUES (?, ?, ?, ?, ?, ?, ?)"
+		if _, err := tx.Exec(query, msg.ID, msg.ConversationID, msg.ItemID, msg.SenderID, msg.Content, msg.CreatedAt, msg.Seq); err != nil {
+			return fmt.Errorf("failed to insert message: %w", err)
+		}
+		if err := attachToMessage(tx, msg); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`UPDATE conversations SET message_seq = ?, last_message_id = ?, updated_at = ?,
+			buyer_read_seq = IF(buyer_id = ?, ?, buyer_read_seq),
+			seller_read_seq = IF(seller_id = ?, ?, seller_read_seq)
+			WHERE id = ?`,
+			msg.Seq, msg.ID, at, msg.SenderID, msg.Seq, msg.SenderID, msg.Seq, msg.ConversationID)
+		return err
+	})
 }
 
-// 保存
-func (dao *MessageDAO) Insert(msg *model.Message) error {
-	query := "INSERT INTO messages (id, item_id, sender_id, content, created_at) VALUES (?, ?, ?, ?, ?)"
-	_, err := dao.db.Exec(query, msg.ID, msg.ItemID, msg.SenderID, msg.Content, msg.CreatedAt)
+// Edit: 本文を書き換え、書き換える前の本文を履歴に残す（送信を取り消していれば model.ErrMessageUnsent）
+func (dao *MessageDAO) Edit(messageID, content string, at time.Time) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		if err := saveRevision(tx, messageID, model.MessageRevisionEdit, at); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE messages SET content = ?, edited_at = ? WHERE id = ?", content, at, messageID)
+		return err
+	})
+}
+
+// Unsend: 送信を取り消す（本文は履歴に移して空にする。添付はモデレーターの確認用にそのまま残す）
+func (dao *MessageDAO) Unsend(messageID string, at time.Time) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		if err := saveRevision(tx, messageID, model.MessageRevisionUnsend, at); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE messages SET content = '', unsent_at = ? WHERE id = ?", at, messageID)
+		return err
+	})
+}
+
+// saveRevision: メッセージの行をロックし、今の本文を履歴に残す
+func saveRevision(tx *sql.Tx, messageID string, action model.MessageRevisionAction, at time.Time) error {
+	var content string
+	var unsent bool
+	err := tx.QueryRow("SELECT content, unsent_at IS NOT NULL FROM messages WHERE id = ? FOR UPDATE", messageID).Scan(&content, &unsent)
+	if err != nil {
+		return err
+	}
+	if unsent {
+		return model.ErrMessageUnsent
+	}
+	_, err = tx.Exec("INSERT INTO message_revisions (id, message_id, action, content, created_at) VALUES (?, ?, ?, ?, ?)",
+		model.NewID(), messageID, action, content, at)
+	return err
+}
+
+// ListRevisions: メッセージの本文の履歴（古い順）
+func (dao *MessageDAO) ListRevisions(messageID string) ([]*model.MessageRevision, error) {
+	rows, err := dao.db.Query(`SELECT id, message_id, action, content, created_at FROM message_revisions
+		WHERE message_id = ? ORDER BY created_at ASC, id ASC`, messageID)
 	if err != nil {
-		return fmt.Errorf("failed to insert message: %w", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*model.MessageRevision
+	for rows.Next() {
+		var rev model.MessageRevision
+		if err := rows.Scan(&rev.ID, &rev.MessageID, &rev.Action, &rev.Content, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &rev)
 	}
-	return nil
+	return list, rows.Err()
 }
@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"hackathon-backend/model"
+	"strings"
+	"time"
+)
+
+type NotificationDAO struct {
+	db *sql.DB
+}
+
+func NewNotificationDAO(db *sql.DB) *NotificationDAO {
+	return &NotificationDAO{db: db}
+}
+
+const notificationColumns = "id, user_id, type, item_id, source_id, message, dedup_key, read_at, created_at"
+
+// Create: 通知を登録する（同じ DedupKey の通知が既にあれば何もせず false を返す）
+func (dao *NotificationDAO) Create(n *model.Notification) (bool, error) {
+	query := "INSERT INTO notifications (" + notificationColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, NULL, ?)"
+	_, err := dao.db.Exec(query, n.ID, n.UserID, n.Type, n.ItemID, n.SourceID, n.Message, n.DedupKey, n.CreatedAt)
+	if isDuplicateKey(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to insert notification: %w", err)
+	}
+	return true, nil
+}
+
+// CountBySourceSince: since 以降に sourceID をきっかけに作られた通知の数
+func (dao *NotificationDAO) CountBySourceSince(sourceID string, since time.Time) (int, error) {
+	var n int
+	err := dao.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE source_id = ? AND created_at >= ?", sourceID, since).Scan(&n)
+	return n, err
+}
+
+// ListByUser: ユーザーの通知を新しい順に取得
+func (dao *NotificationDAO) ListByUser(userID string, unreadOnly bool, limit int) ([]*model.Notification, error) {
+	query := "SELECT " + notificationColumns + " FROM notifications WHERE user_id = ?"
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+
+	rows, err := dao.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*model.Notification
+	for rows.Next() {
+		var n model.Notification
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.ItemID, &n.SourceID, &n.Message, &n.DedupKey, &readAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.ReadAt = nullTimePtr(readAt)
+		list = append(list, &n)
+	}
+	return list, rows.Err()
+}
+
+// MarkRead: 通知を既読にする（ids が空ならユーザーの通知すべて）
+func (dao *NotificationDAO) MarkRead(userID string, ids []string, at time.Time) (int64, error) {
+	query := "UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL"
+	args := []interface{}{at, userID}
+	if len(ids) > 0 {
+		query += " AND id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	res, err := dao.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
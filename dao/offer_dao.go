@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+// ErrOfferExists: 同じ商品に対して交渉中のオファーが既にある
+var ErrOfferExists = errors.New("an active offer already exists for this item")
+
+type OfferDAO struct {
+	db *sql.DB
+}
+
+func NewOfferDAO(db *sql.DB) *OfferDAO {
+	return &OfferDAO{db: db}
+}
+
+const offerColumns = "id, item_id, buyer_id, seller_id, amount, status, expires_at, created_at, updated_at"
+
+// activeFlag: 交渉中は 1、終了後は NULL
+// UNIQUE (item_id, buyer_id, active) により、購入者ごとの交渉中オファーを1件に制限する（NULL 同士は重複しない）
+func activeFlag(status model.OfferStatus) interface{} {
+	if status.IsActive() {
+		return 1
+	}
+	return nil
+}
+
+// Create: オファーを登録する（交渉中のものがあれば ErrOfferExists）
+func (dao *OfferDAO) Create(o *model.Offer) error {
+	query := "INSERT INTO offers (" + offerColumns + ", active) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := dao.db.Exec(query, o.ID, o.ItemID, o.BuyerID, o.SellerID, o.Amount, o.Status, o.ExpiresAt, o.CreatedAt, o.UpdatedAt, activeFlag(o.Status))
+	if isDuplicateKey(err) {
+		return ErrOfferExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert offer: %w", err)
+	}
+	return nil
+}
+
+// GetByID: オファーを1件取得（見つからない場合は nil）
+func (dao *OfferDAO) GetByID(id string) (*model.Offer, error) {
+	rows, err := dao.db.Query("SELECT "+offerColumns+" FROM offers WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	offers, err := scanOffers(rows)
+	if err != nil || len(offers) == 0 {
+		return nil, err
+	}
+	return offers[0], nil
+}
+
+// ListByItem: 商品へのオファーを新しい順に取得（buyerID を指定するとその購入者の分だけ）
+func (dao *OfferDAO) ListByItem(itemID, buyerID string) ([]*model.Offer, error) {
+	query := "SELECT " + offerColumns + " FROM offers WHERE item_id = ?"
+	args := []interface{}{itemID}
+	if buyerID != "" {
+		query += " AND buyer_id = ?"
+		args = append(args, buyerID)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := dao.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOffers(rows)
+}
+
+// Update: 状態が from の場合に限り、金額・状態・期限を更新する（競合時は ErrConflict）
+func (dao *OfferDAO) Update(o *model.Offer, from model.OfferStatus) error {
+	return updateOffer(dao.db, o, from)
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func updateOffer(db execer, o *model.Offer, from model.OfferStatus) error {
+	query := "UPDATE offers SET amount = ?, status = ?, expires_at = ?, updated_at = ?, active = ? WHERE id = ? AND status = ?"
+	res, err := db.Exec(query, o.Amount, o.Status, o.ExpiresAt, o.UpdatedAt, activeFlag(o.Status), o.ID, from)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Accept: オファーを合意済みにし、ExpiresAt まで商品を購入者のために確保する
+// 売り切れや他の購入者のために確保中の場合は ErrItemUnavailable
+func (dao *OfferDAO) Accept(o *model.Offer, from model.OfferStatus) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		if err := updateOffer(tx, o, from); err != nil {
+			return err
+		}
+
+		query := `UPDATE items SET reserved_for = ?, reserved_price = ?, reserved_until = ?
+			WHERE id = ? AND sold_out = FALSE AND status = 'published' AND (reserved_for = '' OR reserved_until IS NULL OR reserved_until < ?)`
+		res, err := tx.Exec(query, o.BuyerID, o.Amount, o.ExpiresAt, o.ItemID, o.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrItemUnavailable
+		}
+		return nil
+	})
+}
+
+// ExpireDue: 期限切れの交渉と、購入されないまま期限が過ぎた確保を失効させる
+func (dao *OfferDAO) ExpireDue(now time.Time) (int64, error) {
+	res, err := dao.db.Exec("UPDATE offers SET status = ?, active = NULL, updated_at = ? WHERE status IN (?, ?) AND expires_at < ?",
+		model.OfferExpired, now, model.OfferPending, model.OfferCountered, now)
+	if err != nil {
+		return 0, err
+	}
+	expired, _ := res.RowsAffected()
+
+	query := `UPDATE offers o JOIN items i ON i.id = o.item_id
+		SET o.status = ?, o.updated_at = ?, i.reserved_for = '', i.reserved_price = 0, i.reserved_until = NULL
+		WHERE o.status = ? AND o.expires_at < ? AND i.sold_out = FALSE AND i.reserved_for = o.buyer_id`
+	res, err = dao.db.Exec(query, model.OfferExpired, now, model.OfferAccepted, now)
+	if err != nil {
+		return expired, err
+	}
+	released, _ := res.RowsAffected()
+	return expired + released, nil
+}
+
+func scanOffers(rows *sql.Rows) ([]*model.Offer, error) {
+	var offers []*model.Offer
+	for rows.Next() {
+		var o model.Offer
+		if err := rows.Scan(&o.ID, &o.ItemID, &o.BuyerID, &o.SellerID, &o.Amount, &o.Status, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		offers = append(offers, &o)
+	}
+	return offers, rows.Err()
+}
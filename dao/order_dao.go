@@ -0,0 +1,251 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+type OrderDAO struct {
+	db *sql.DB
+}
+
+func NewOrderDAO(db *sql.DB) *OrderDAO {
+	// ★自動修復機能: 決済・エスクロー用のカラムを追加
+	_, _ = db.Exec("ALTER TABLE orders ADD COLUMN payment_id VARCHAR(255) NOT NULL DEFAULT ''")
+	_, _ = db.Exec("ALTER TABLE orders ADD COLUMN escrow_status VARCHAR(32) NOT NULL DEFAULT ''")
+	_, _ = db.Exec("CREATE INDEX idx_orders_payment ON orders (payment_id)")
+	// クーポンの割引
+	_, _ = db.Exec("ALTER TABLE orders ADD COLUMN discount INT NOT NULL DEFAULT 0")
+	_, _ = db.Exec("ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(64) NOT NULL DEFAULT ''")
+
+	return &OrderDAO{db: db}
+}
+
+const orderColumns = "id, item_id, buyer_id, seller_id, price, status, discount, coupon_code, payment_id, escrow_status, created_at, updated_at, paid_at, shipped_at, received_at, completed_at, cancelled_at"
+
+// statusTimeColumns: 遷移先ごとに記録するタイムスタンプのカラム
+var statusTimeColumns = map[model.OrderStatus]string{
+	model.OrderStatusPaid:      "paid_at",
+	model.OrderStatusShipped:   "shipped_at",
+	model.OrderStatusReceived:  "received_at",
+	model.OrderStatusCompleted: "completed_at",
+	model.OrderStatusCancelled: "cancelled_at",
+}
+
+// Create: 商品を売り切れにして注文を作成する（クーポンを使う場合は利用も記録する）
+// 同時購入や、他の購入者のために確保中の場合は ErrItemUnavailable
+func (dao *OrderDAO) Create(order *model.Order) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		query := `UPDATE items SET sold_out = TRUE, status = 'sold', reserved_for = '', reserved_price = 0, reserved_until = NULL
+			WHERE id = ? AND sold_out = FALSE AND status = 'published' AND (reserved_for = '' OR reserved_for = ? OR reserved_until IS NULL OR reserved_until < ?)`
+		res, err := tx.Exec(query, order.ItemID, order.BuyerID, order.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrItemUnavailable
+		}
+
+		if order.CouponCode != "" {
+			if err := redeemCoupon(tx, order, order.CreatedAt); err != nil {
+				return err
+			}
+		}
+
+		query = "INSERT INTO orders (id, item_id, buyer_id, seller_id, price, status, discount, coupon_code, payment_id, escrow_status, created_at, updated_at, paid_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		if _, err := tx.Exec(query, order.ID, order.ItemID, order.BuyerID, order.SellerID, order.Price, order.Status, order.Discount, order.CouponCode,
+			order.PaymentID, order.EscrowStatus, order.CreatedAt, order.UpdatedAt, order.PaidAt); err != nil {
+			return fmt.Errorf("failed to insert order: %w", err)
+		}
+
+		return insertOrderEvent(tx, &model.OrderEvent{
+			ID:        model.NewID(),
+			OrderID:   order.ID,
+			ToStatus:  order.Status,
+			ActorID:   order.BuyerID,
+			ActorRole: model.OrderActorBuyer,
+			CreatedAt: order.CreatedAt,
+		})
+	})
+}
+
+// Transition: 現在の状態が from の場合に限り to へ更新し、監査ログを残す
+// 他の遷移と競合した場合は ErrConflict を返す
+func (dao *OrderDAO) Transition(order *model.Order, to model.OrderStatus, actorID string, role model.OrderActor, at time.Time) error {
+	column, ok := statusTimeColumns[to]
+	if !ok {
+		return model.ErrIllegalTransition
+	}
+
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		query := "UPDATE orders SET status = ?, updated_at = ?, " + column + " = ? WHERE id = ? AND status = ?"
+		res, err := tx.Exec(query, to, at, at, order.ID, order.Status)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrConflict
+		}
+
+		// キャンセルされたら商品を再び購入可能にし、クーポンも使える状態に戻す
+		if to == model.OrderStatusCancelled {
+			if _, err := tx.Exec("UPDATE items SET sold_out = FALSE, status = 'published' WHERE id = ? AND status = 'sold'", order.ItemID); err != nil {
+				return err
+			}
+			if order.CouponCode != "" {
+				if err := releaseCoupon(tx, order); err != nil {
+					return err
+				}
+			}
+		}
+
+		return insertOrderEvent(tx, &model.OrderEvent{
+			ID:         model.NewID(),
+			OrderID:    order.ID,
+			FromStatus: order.Status,
+			ToStatus:   to,
+			ActorID:    actorID,
+			ActorRole:  role,
+			CreatedAt:  at,
+		})
+	})
+}
+
+func insertOrderEvent(tx *sql.Tx, ev *model.OrderEvent) error {
+	query := "INSERT INTO order_events (id, order_id, from_status, to_status, actor_id, actor_role, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	if _, err := tx.Exec(query, ev.ID, ev.OrderID, ev.FromStatus, ev.ToStatus, ev.ActorID, ev.ActorRole, ev.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert order event: %w", err)
+	}
+	return nil
+}
+
+// GetByID: 注文を1件取得（見つからない場合は nil）
+func (dao *OrderDAO) GetByID(id string) (*model.Order, error) {
+	rows, err := dao.db.Query("SELECT "+orderColumns+" FROM orders WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil || len(orders) == 0 {
+		return nil, err
+	}
+	return orders[0], nil
+}
+
+// ListByUser: 購入者または出品者として関わる注文を新しい順に取得
+func (dao *OrderDAO) ListByUser(userID string) ([]*model.Order, error) {
+	query := "SELECT " + orderColumns + " FROM orders WHERE buyer_id = ? OR seller_id = ? ORDER BY created_at DESC"
+	rows, err := dao.db.Query(query, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
+// ListStale: status のまま since 以前から更新されていない注文を取得（自動完了用）
+func (dao *OrderDAO) ListStale(status model.OrderStatus, since time.Time) ([]*model.Order, error) {
+	query := "SELECT " + orderColumns + " FROM orders WHERE status = ? AND updated_at <= ?"
+	rows, err := dao.db.Query(query, status, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
+// UpdateEscrow: 預かり状態が from の場合に限り to へ更新し、対応する仕訳を同じトランザクションで登録する
+// 状態の条件付き更新が二重計上を防ぐ（競合時は ErrConflict）
+func (dao *OrderDAO) UpdateEscrow(orderID string, from, to model.EscrowStatus, entries []*model.LedgerEntry) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec("UPDATE orders SET escrow_status = ? WHERE id = ? AND escrow_status = ?", to, orderID, from)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrConflict
+		}
+		return insertLedgerEntries(tx, entries)
+	})
+}
+
+// GetByPaymentID: 決済IDから注文を取得（Webhook 用、見つからない場合は nil）
+func (dao *OrderDAO) GetByPaymentID(paymentID string) (*model.Order, error) {
+	rows, err := dao.db.Query("SELECT "+orderColumns+" FROM orders WHERE payment_id = ? AND payment_id <> ''", paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil || len(orders) == 0 {
+		return nil, err
+	}
+	return orders[0], nil
+}
+
+// ListUnsettled: 取引が進んだのに預かり金の処理が終わっていない注文を取得
+func (dao *OrderDAO) ListUnsettled() ([]*model.Order, error) {
+	query := "SELECT " + orderColumns + " FROM orders WHERE (escrow_status = ? AND status IN (?, ?, ?)) OR (escrow_status = ? AND status = ?)"
+	rows, err := dao.db.Query(query,
+		model.EscrowHeld, model.OrderStatusReceived, model.OrderStatusCompleted, model.OrderStatusCancelled,
+		model.EscrowAuthorized, model.OrderStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
+// ListEvents: 注文の監査ログを古い順に取得
+func (dao *OrderDAO) ListEvents(orderID string) ([]*model.OrderEvent, error) {
+	query := "SELECT id, order_id, from_status, to_status, actor_id, actor_role, created_at FROM order_events WHERE order_id = ? ORDER BY created_at ASC, id ASC"
+	rows, err := dao.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.OrderEvent
+	for rows.Next() {
+		var ev model.OrderEvent
+		if err := rows.Scan(&ev.ID, &ev.OrderID, &ev.FromStatus, &ev.ToStatus, &ev.ActorID, &ev.ActorRole, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &ev)
+	}
+	return events, rows.Err()
+}
+
+func scanOrders(rows *sql.Rows) ([]*model.Order, error) {
+	var orders []*model.Order
+	for rows.Next() {
+		var o model.Order
+		var paid, shipped, received, completed, cancelled sql.NullTime
+		if err := rows.Scan(&o.ID, &o.ItemID, &o.BuyerID, &o.SellerID, &o.Price, &o.Status, &o.Discount, &o.CouponCode, &o.PaymentID, &o.EscrowStatus, &o.CreatedAt, &o.UpdatedAt,
+			&paid, &shipped, &received, &completed, &cancelled); err != nil {
+			return nil, err
+		}
+		o.PaidAt = nullTimePtr(paid)
+		o.ShippedAt = nullTimePtr(shipped)
+		o.ReceivedAt = nullTimePtr(received)
+		o.CompletedAt = nullTimePtr(completed)
+		o.CancelledAt = nullTimePtr(cancelled)
+		orders = append(orders, &o)
+	}
+	return orders, rows.Err()
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
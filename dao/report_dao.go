@@ -0,0 +1,258 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+// ErrReportExists: 同じ対象への未対応の通報が既にある
+var ErrReportExists = errors.New("you have already reported this")
+
+// ErrClaimedByOther: 他の担当者が確認中の通報は閉じられない
+var ErrClaimedByOther = errors.New("report is claimed by another moderator")
+
+type ReportDAO struct {
+	db *sql.DB
+}
+
+func NewReportDAO(db *sql.DB) *ReportDAO {
+	return &ReportDAO{db: db}
+}
+
+// reportTargetTables: 通報対象の種類とテーブルの対応（各テーブルの hidden カラムで非表示にする）
+var reportTargetTables = map[model.ReportTarget]string{
+	model.ReportTargetItem:    "items",
+	model.ReportTargetMessage: "messages",
+	model.ReportTargetUser:    "users",
+}
+
+const reportColumns = "id, target_type, target_id, reporter_id, reason, note, status, claimed_by, created_at, updated_at"
+
+// TargetExists: 通報対象が存在するか
+func (dao *ReportDAO) TargetExists(target model.ReportTarget, id string) (bool, error) {
+	table, ok := reportTargetTables[target]
+	if !ok {
+		return false, model.ErrInvalidReportTarget
+	}
+	var exists bool
+	err := dao.db.QueryRow("SELECT EXISTS(SELECT 1 FROM "+table+" WHERE id = ?)", id).Scan(&exists)
+	return exists, err
+}
+
+// Create: 通報を登録し、未対応の通報者数が threshold に達したら対象を非表示にする
+// 数えるのは実在するユーザーの通報だけ（システムからの通報や、存在しない ID は数えない）
+// 戻り値は、この通報で非表示になったかどうか
+func (dao *ReportDAO) Create(r *model.Report, threshold int) (bool, error) {
+	hidden := false
+	err := withTx(dao.db, func(tx *sql.Tx) error {
+		query := "INSERT INTO reports (" + reportColumns + ", open_flag) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)"
+		_, err := tx.Exec(query, r.ID, r.TargetType, r.TargetID, r.ReporterID, r.Reason, r.Note, r.Status, r.ClaimedBy, r.CreatedAt, r.UpdatedAt)
+		if isDuplicateKey(err) {
+			return ErrReportExists
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert report: %w", err)
+		}
+
+		if threshold <= 0 {
+			return nil
+		}
+		var reporters int
+		if err := tx.QueryRow(`SELECT COUNT(DISTINCT r.reporter_id) FROM reports r JOIN users u ON u.id = r.reporter_id
+			WHERE r.target_type = ? AND r.target_id = ? AND r.open_flag = 1`,
+			r.TargetType, r.TargetID).Scan(&reporters); err != nil {
+			return err
+		}
+		if reporters < threshold {
+			return nil
+		}
+
+		changed, err := setHidden(tx, r.TargetType, r.TargetID, true)
+		if err != nil || !changed {
+			return err
+		}
+		hidden = true
+		return insertModerationAction(tx, &model.ModerationAction{
+			ID:          model.NewID(),
+			ReportID:    r.ID,
+			TargetType:  r.TargetType,
+			TargetID:    r.TargetID,
+			ModeratorID: model.SystemActorID,
+			Action:      model.ModActionAutoHide,
+			Note:        fmt.Sprintf("%d reporters reached threshold %d", reporters, threshold),
+			CreatedAt:   r.CreatedAt,
+		})
+	})
+	return hidden, err
+}
+
+// setHidden: 対象の表示・非表示を切り替える（変化があったかを返す）
+func setHidden(tx *sql.Tx, target model.ReportTarget, id string, hidden bool) (bool, error) {
+	table, ok := reportTargetTables[target]
+	if !ok {
+		return false, model.ErrInvalidReportTarget
+	}
+	res, err := tx.Exec("UPDATE "+table+" SET hidden = ? WHERE id = ? AND hidden = ?", hidden, id, !hidden)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func insertModerationAction(tx *sql.Tx, a *model.ModerationAction) error {
+	query := "INSERT INTO moderation_actions (id, report_id, target_type, target_id, moderator_id, action, note, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	if _, err := tx.Exec(query, a.ID, a.ReportID, a.TargetType, a.TargetID, a.ModeratorID, a.Action, a.Note, a.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert moderation action: %w", err)
+	}
+	return nil
+}
+
+// GetByID: 通報を1件取得（見つからない場合は nil）
+func (dao *ReportDAO) GetByID(id string) (*model.Report, error) {
+	rows, err := dao.db.Query("SELECT "+reportColumns+" FROM reports WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports, err := scanReports(rows)
+	if err != nil || len(reports) == 0 {
+		return nil, err
+	}
+	return reports[0], nil
+}
+
+// List: 通報を古い順に取得（status が空なら未対応のものすべて）
+func (dao *ReportDAO) List(status model.ReportStatus, limit int) ([]*model.Report, error) {
+	query := "SELECT " + reportColumns + " FROM reports WHERE open_flag = 1 ORDER BY created_at ASC LIMIT ?"
+	args := []interface{}{limit}
+	if status != "" {
+		query = "SELECT " + reportColumns + " FROM reports WHERE status = ? ORDER BY created_at ASC LIMIT ?"
+		args = []interface{}{status, limit}
+	}
+
+	rows, err := dao.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReports(rows)
+}
+
+// Claim: 未対応の通報を担当する
+func (dao *ReportDAO) Claim(r *model.Report, moderatorID, note string, at time.Time) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec("UPDATE reports SET status = ?, claimed_by = ?, updated_at = ? WHERE id = ? AND status = ?",
+			model.ReportClaimed, moderatorID, at, r.ID, model.ReportOpen)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrConflict
+		}
+		return insertModerationAction(tx, &model.ModerationAction{
+			ID: model.NewID(), ReportID: r.ID, TargetType: r.TargetType, TargetID: r.TargetID,
+			ModeratorID: moderatorID, Action: model.ModActionClaim, Note: note, CreatedAt: at,
+		})
+	})
+}
+
+// Close: 対象への未対応の通報をまとめて resolved / dismissed にする
+// resolved なら対象を非表示のままにし、dismissed なら表示に戻す
+func (dao *ReportDAO) Close(r *model.Report, to model.ReportStatus, moderatorID, note string, at time.Time) error {
+	action := model.ModActionResolve
+	if to == model.ReportDismissed {
+		action = model.ModActionDismiss
+	}
+
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		// 確認を始める前の通報か、自分が確認中の通報だけを閉じる
+		res, err := tx.Exec(`UPDATE reports SET status = ?, claimed_by = ?, updated_at = ?, open_flag = NULL
+			WHERE id = ? AND open_flag = 1 AND (status = ? OR claimed_by = ?)`,
+			to, moderatorID, at, r.ID, model.ReportOpen, moderatorID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			var open bool
+			if err := tx.QueryRow("SELECT open_flag IS NOT NULL FROM reports WHERE id = ?", r.ID).Scan(&open); err != nil {
+				return err
+			}
+			if open {
+				return ErrClaimedByOther
+			}
+			return ErrConflict
+		}
+		// 同じ対象への他の通報も同じ判断で閉じる（他の担当者が確認中のものは残す）
+		if _, err := tx.Exec(`UPDATE reports SET status = ?, claimed_by = ?, updated_at = ?, open_flag = NULL
+			WHERE target_type = ? AND target_id = ? AND open_flag = 1 AND (status = ? OR claimed_by = ?)`,
+			to, moderatorID, at, r.TargetType, r.TargetID, model.ReportOpen, moderatorID); err != nil {
+			return err
+		}
+
+		if err := insertModerationAction(tx, &model.ModerationAction{
+			ID: model.NewID(), ReportID: r.ID, TargetType: r.TargetType, TargetID: r.TargetID,
+			ModeratorID: moderatorID, Action: action, Note: note, CreatedAt: at,
+		}); err != nil {
+			return err
+		}
+
+		changed, err := setHidden(tx, r.TargetType, r.TargetID, to == model.ReportResolved)
+		if err != nil || !changed {
+			return err
+		}
+		hideAction := model.ModActionUnhide
+		if to == model.ReportResolved {
+			hideAction = model.ModActionHide
+		}
+		return insertModerationAction(tx, &model.ModerationAction{
+			ID: model.NewID(), ReportID: r.ID, TargetType: r.TargetType, TargetID: r.TargetID,
+			ModeratorID: moderatorID, Action: hideAction, CreatedAt: at,
+		})
+	})
+}
+
+// ListActions: 監査ログを新しい順に取得（targetID を指定するとその対象だけ）
+func (dao *ReportDAO) ListActions(targetID string, limit int) ([]*model.ModerationAction, error) {
+	query := "SELECT id, report_id, target_type, target_id, moderator_id, action, note, created_at FROM moderation_actions"
+	args := []interface{}{}
+	if targetID != "" {
+		query += " WHERE target_id = ?"
+		args = append(args, targetID)
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := dao.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []*model.ModerationAction
+	for rows.Next() {
+		var a model.ModerationAction
+		if err := rows.Scan(&a.ID, &a.ReportID, &a.TargetType, &a.TargetID, &a.ModeratorID, &a.Action, &a.Note, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		actions = append(actions, &a)
+	}
+	return actions, rows.Err()
+}
+
+func scanReports(rows *sql.Rows) ([]*model.Report, error) {
+	var reports []*model.Report
+	for rows.Next() {
+		var r model.Report
+		if err := rows.Scan(&r.ID, &r.TargetType, &r.TargetID, &r.ReporterID, &r.Reason, &r.Note, &r.Status, &r.ClaimedBy, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		reports = append(reports, &r)
+	}
+	return reports, rows.Err()
+}
@@ -0,0 +1,187 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+	"time"
+)
+
+// ErrReviewExists: この取引は評価済み
+var ErrReviewExists = errors.New("already reviewed")
+
+// ErrReviewClosed: 評価の受付は締め切った（相手の評価が公開済み、または期限切れ）
+var ErrReviewClosed = errors.New("review period has ended")
+
+type ReviewDAO struct {
+	db *sql.DB
+}
+
+func NewReviewDAO(db *sql.DB) *ReviewDAO {
+	return &ReviewDAO{db: db}
+}
+
+const reviewColumns = "id, order_id, reviewer_id, reviewee_id, reviewer_role, rating, comment, created_at, revealed_at"
+
+// Create: 評価を登録し、取引の両者が評価済みになったら両方を公開する
+func (dao *ReviewDAO) Create(r *model.Review) error {
+	return withTx(dao.db, func(tx *sql.Tx) error {
+		// 取引の行をロックして、両者が同時に評価しても公開漏れが起きないようにする
+		var locked string
+		if err := tx.QueryRow("SELECT id FROM orders WHERE id = ? FOR UPDATE", r.OrderID).Scan(&locked); err != nil {
+			return err
+		}
+		// 相手の評価が公開された後は、それを読んでから書けてしまうので受け付けない
+		var revealed bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM reviews WHERE order_id = ? AND revealed_at IS NOT NULL)", r.OrderID).Scan(&revealed); err != nil {
+			return err
+		}
+		if revealed {
+			return ErrReviewClosed
+		}
+
+		query := "INSERT INTO reviews (" + reviewColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULL)"
+		_, err := tx.Exec(query, r.ID, r.OrderID, r.ReviewerID, r.RevieweeID, r.ReviewerRole, r.Rating, r.Comment, r.CreatedAt)
+		if isDuplicateKey(err) {
+			return ErrReviewExists
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert review: %w", err)
+		}
+
+		rows, err := tx.Query("SELECT id FROM reviews WHERE order_id = ? AND revealed_at IS NULL", r.OrderID)
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+
+		if len(ids) < 2 {
+			return nil
+		}
+		for _, id := range ids {
+			if err := revealReview(tx, id, r.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// revealReview: 評価を公開し、評価された側の集計に加える（公開済みなら何もしない）
+func revealReview(tx *sql.Tx, reviewID string, at time.Time) error {
+	res, err := tx.Exec("UPDATE reviews SET revealed_at = ? WHERE id = ? AND revealed_at IS NULL", at, reviewID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO user_reputation (user_id, rating_sum, rating_count, good, normal, bad)
+		SELECT reviewee_id, rating, 1, rating >= 4, rating = 3, rating <= 2 FROM reviews WHERE id = ?
+		ON DUPLICATE KEY UPDATE
+			rating_sum = rating_sum + VALUES(rating_sum),
+			rating_count = rating_count + 1,
+			good = good + VALUES(good),
+			normal = normal + VALUES(normal),
+			bad = bad + VALUES(bad)`
+	_, err = tx.Exec(query, reviewID)
+	return err
+}
+
+// RevealDue: 相手が評価しないまま before より前に書かれた評価を公開する
+func (dao *ReviewDAO) RevealDue(before time.Time) (int, error) {
+	rows, err := dao.db.Query("SELECT id FROM reviews WHERE revealed_at IS NULL AND created_at <= ?", before)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	now := time.Now()
+	for i, id := range ids {
+		err := withTx(dao.db, func(tx *sql.Tx) error {
+			// Create と同じく取引の行をロックし、公開と同時に相手の評価が書かれないようにする
+			var locked string
+			if err := tx.QueryRow("SELECT o.id FROM orders o JOIN reviews r ON r.order_id = o.id WHERE r.id = ? FOR UPDATE", id).Scan(&locked); err != nil {
+				return err
+			}
+			return revealReview(tx, id, now)
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// ListByOrder: 取引の評価を取得（公開前のものも含む）
+func (dao *ReviewDAO) ListByOrder(orderID string) ([]*model.Review, error) {
+	rows, err := dao.db.Query("SELECT "+reviewColumns+" FROM reviews WHERE order_id = ? ORDER BY created_at ASC", orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+// ListRevealedByReviewee: ユーザーが受けた公開済みの評価を新しい順に取得
+func (dao *ReviewDAO) ListRevealedByReviewee(userID string, limit int) ([]*model.Review, error) {
+	query := "SELECT " + reviewColumns + " FROM reviews WHERE reviewee_id = ? AND revealed_at IS NOT NULL ORDER BY revealed_at DESC LIMIT ?"
+	rows, err := dao.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+// GetReputation: ユーザーの評価の集計
+func (dao *ReviewDAO) GetReputation(userID string) (*model.Reputation, error) {
+	var sum int
+	rep := &model.Reputation{}
+	err := dao.db.QueryRow("SELECT rating_sum, rating_count, good, normal, bad FROM user_reputation WHERE user_id = ?", userID).
+		Scan(&sum, &rep.Count, &rep.Good, &rep.Normal, &rep.Bad)
+	if err == sql.ErrNoRows {
+		return rep, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rep.Average = model.RatingAverage(sum, rep.Count)
+	return rep, nil
+}
+
+func scanReviews(rows *sql.Rows) ([]*model.Review, error) {
+	var reviews []*model.Review
+	for rows.Next() {
+		var r model.Review
+		var revealed sql.NullTime
+		if err := rows.Scan(&r.ID, &r.OrderID, &r.ReviewerID, &r.RevieweeID, &r.ReviewerRole, &r.Rating, &r.Comment, &r.CreatedAt, &revealed); err != nil {
+			return nil, err
+		}
+		r.RevealedAt = nullTimePtr(revealed)
+		reviews = append(reviews, &r)
+	}
+	return reviews, rows.Err()
+}
@@ -0,0 +1,77 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"hackathon-backend/model"
+)
+
+type SavedSearchDAO struct {
+	db *sql.DB
+}
+
+func NewSavedSearchDAO(db *sql.DB) *SavedSearchDAO {
+	return &SavedSearchDAO{db: db}
+}
+
+const savedSearchColumns = "id, user_id, keyword, min_price, max_price, created_at"
+
+// Create: 検索条件を保存する
+func (dao *SavedSearchDAO) Create(s *model.SavedSearch) error {
+	query := "INSERT INTO saved_searches (" + savedSearchColumns + ") VALUES (?, ?, ?, ?, ?, ?)"
+	if _, err := dao.db.Exec(query, s.ID, s.UserID, s.Keyword, s.MinPrice, s.MaxPrice, s.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert saved search: %w", err)
+	}
+	return nil
+}
+
+// CountByUser: ユーザーが保存している検索条件の数
+func (dao *SavedSearchDAO) CountByUser(userID string) (int, error) {
+	var n int
+	err := dao.db.QueryRow("SELECT COUNT(*) FROM saved_searches WHERE user_id = ?", userID).Scan(&n)
+	return n, err
+}
+
+// ListByUser: ユーザーの検索条件を新しい順に取得
+func (dao *SavedSearchDAO) ListByUser(userID string) ([]*model.SavedSearch, error) {
+	rows, err := dao.db.Query("SELECT "+savedSearchColumns+" FROM saved_searches WHERE user_id = ? ORDER BY created_at DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSavedSearches(rows)
+}
+
+// ListAll: すべての検索条件を取得（新着照合用）
+func (dao *SavedSearchDAO) ListAll() ([]*model.SavedSearch, error) {
+	rows, err := dao.db.Query("SELECT " + savedSearchColumns + " FROM saved_searches")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSavedSearches(rows)
+}
+
+// Delete: 本人の検索条件を削除する（見つからなければ false）
+func (dao *SavedSearchDAO) Delete(id, userID string) (bool, error) {
+	res, err := dao.db.Exec("DELETE FROM saved_searches WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func scanSavedSearches(rows *sql.Rows) ([]*model.SavedSearch, error) {
+	var list []*model.SavedSearch
+	for rows.Next() {
+		var s model.SavedSearch
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Keyword, &s.MinPrice, &s.MaxPrice, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &s)
+	}
+	return list, rows.Err()
+}
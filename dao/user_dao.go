@@ -13,6 +13,9 @@ type UserDAO struct {
 }
 
 func NewUserDAO(db *sql.DB) *UserDAO {
+	// ★自動修復機能: 通報で非表示にするためのカラムを追加
+	_, _ = db.Exec("ALTER TABLE users ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE")
+
 	return &UserDAO{DB: db}
 }
 
@@ -55,7 +58,7 @@ func (d *UserDAO) GetUserByEmail(email string) (*model.User, error) {
 func (d *UserDAO) FindUsersByName(name string) ([]model.User, error) {
 	// テーブル名を "users" に修正
 	// Age はなくなったので取得しません
-	query := "SELECT id, name, email, password FROM users WHERE name = ?"
+	query := "SELECT id, name, email, password FROM users WHERE name = ? AND hidden = FALSE"
 
 	rows, err := d.DB.Query(query, name)
 	if err != nil {
@@ -76,3 +79,28 @@ func (d *UserDAO) FindUsersByName(name string) ([]model.User, error) {
 
 	return users, nil
 }
+
+// GetUserByID は、IDからユーザーを取得します（見つからない場合は nil。通報で非表示のユーザーも返す）
+// 取引・評価・ブロックなど、非表示になった後も相手として扱う必要がある処理で使います
+func (d *UserDAO) GetUserByID(id string) (*model.User, error) {
+	return d.getUser("SELECT id, name, email, password FROM users WHERE id = ?", id)
+}
+
+// GetVisibleUserByID は、公開プロフィール用にユーザーを取得します（見つからない・非表示の場合は nil）
+func (d *UserDAO) GetVisibleUserByID(id string) (*model.User, error) {
+	return d.getUser("SELECT id, name, email, password FROM users WHERE id = ? AND hidden = FALSE", id)
+}
+
+func (d *UserDAO) getUser(query string, id string) (*model.User, error) {
+	user := &model.User{}
+	err := d.DB.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.Password)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("fail: get user by id, %v\n", err)
+		return nil, err
+	}
+
+	return user, nil
+}
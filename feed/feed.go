@@ -0,0 +1,174 @@
+// Package feed: 商品一覧を CSV・Atom・商品フィード XML として書き出す
+// 1件ずつ書き込むので、DB カーソルから読みながら全件をメモリに載せずに出力できる
+package feed
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"hackathon-backend/model"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Writer: 商品を1件ずつ書き出す（最後に Close で閉じタグなどを書く）
+type Writer interface {
+	WriteItem(item *model.Item) error
+	Close() error
+}
+
+// Meta: フィード全体の情報
+type Meta struct {
+	Title   string
+	SiteURL string // 商品ページの URL は SiteURL + "/items/" + ID
+	Updated time.Time
+}
+
+func (m Meta) itemURL(id string) string {
+	return strings.TrimRight(m.SiteURL, "/") + "/items/" + id
+}
+
+// publicImage: フィードに載せられる画像 URL（data URL などは載せない）
+func publicImage(url string) string {
+	if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
+		return url
+	}
+	return ""
+}
+
+// CSVHeader: CSV の列（name, price, description, image_url, sku は一括出品の取り込みと同じ）
+var CSVHeader = []string{"id", "sku", "name", "price", "description", "image_url", "category", "tags", "status", "sold_out", "like_count", "created_at", "published_at"}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSV: 出品者のバックアップ用 CSV（そのまま一括出品で取り込める）
+func NewCSV(w io.Writer) (Writer, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVHeader); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (c *csvWriter) WriteItem(item *model.Item) error {
+	return c.w.Write([]string{
+		item.ID, item.ExternalSKU, item.Name, strconv.Itoa(item.Price), item.Description, item.ImageURL,
+		item.Category, strings.Join(item.Tags, "|"), string(item.Status), strconv.FormatBool(item.SoldOut),
+		strconv.Itoa(item.LikeCount), formatTime(item.CreatedAt), formatTime(item.PublishedAt),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// xmlStream: 開始部分を書いた後、要素を1つずつエンコードする
+type xmlStream struct {
+	enc    *xml.Encoder
+	closer string
+	w      io.Writer
+	encode func(enc *xml.Encoder, item *model.Item) error
+}
+
+func newXMLStream(w io.Writer, opening, closer string, encode func(*xml.Encoder, *model.Item) error) (Writer, error) {
+	if _, err := io.WriteString(w, xml.Header+opening); err != nil {
+		return nil, err
+	}
+	return &xmlStream{enc: xml.NewEncoder(w), closer: closer, w: w, encode: encode}, nil
+}
+
+func (x *xmlStream) WriteItem(item *model.Item) error {
+	if err := x.encode(x.enc, item); err != nil {
+		return err
+	}
+	return x.enc.Flush()
+}
+
+func (x *xmlStream) Close() error {
+	_, err := io.WriteString(x.w, x.closer)
+	return err
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+type atomEntry struct {
+	XMLName xml.Name `xml:"entry"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    struct {
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+	Summary string `xml:"summary"`
+}
+
+// NewAtom: 新着商品の Atom フィード
+func NewAtom(w io.Writer, meta Meta) (Writer, error) {
+	opening := fmt.Sprintf(`<feed xmlns="http://www.w3.org/2005/Atom"><title>%s</title><id>%s</id><link href="%s"/><updated>%s</updated>`,
+		escape(meta.Title), escape(meta.SiteURL+"/"), escape(meta.SiteURL+"/"), meta.Updated.UTC().Format(time.RFC3339))
+	return newXMLStream(w, opening, "</feed>\n", func(enc *xml.Encoder, item *model.Item) error {
+		e := atomEntry{
+			ID:      meta.itemURL(item.ID),
+			Title:   item.Name,
+			Updated: formatTime(item.PublishedAt),
+			Summary: fmt.Sprintf("¥%d %s", item.Price, item.Description),
+		}
+		if e.Updated == "" {
+			e.Updated = meta.Updated.UTC().Format(time.RFC3339)
+		}
+		e.Link.Href = e.ID
+		return enc.Encode(e)
+	})
+}
+
+type productEntry struct {
+	XMLName      xml.Name `xml:"item"`
+	ID           string   `xml:"g:id"`
+	Title        string   `xml:"title"`
+	Description  string   `xml:"description"`
+	Link         string   `xml:"link"`
+	ImageLink    string   `xml:"g:image_link,omitempty"`
+	Price        string   `xml:"g:price"`
+	Availability string   `xml:"g:availability"`
+	Condition    string   `xml:"g:condition"`
+	ProductType  string   `xml:"g:product_type,omitempty"`
+}
+
+// NewProductFeed: 価格比較サイト向けの商品フィード（Google Merchant Center の RSS 2.0 形式）
+func NewProductFeed(w io.Writer, meta Meta) (Writer, error) {
+	opening := fmt.Sprintf(`<rss version="2.0" xmlns:g="http://base.google.com/ns/1.0"><channel><title>%s</title><link>%s</link><description>%s</description>`,
+		escape(meta.Title), escape(meta.SiteURL+"/"), escape(meta.Title))
+	return newXMLStream(w, opening, "</channel></rss>\n", func(enc *xml.Encoder, item *model.Item) error {
+		availability := "in_stock"
+		if item.SoldOut {
+			availability = "out_of_stock"
+		}
+		return enc.Encode(productEntry{
+			ID:           item.ID,
+			Title:        item.Name,
+			Description:  item.Description,
+			Link:         meta.itemURL(item.ID),
+			ImageLink:    publicImage(item.ImageURL),
+			Price:        fmt.Sprintf("%d JPY", item.Price),
+			Availability: availability,
+			Condition:    "used",
+			ProductType:  item.Category,
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"hackathon-backend/model"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	published    = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	itemsFixture = []*model.Item{
+		{ID: "a", ExternalSKU: "SKU-1", Name: "ジャンク <カメラ> & レンズ", Price: 3000, Description: "動作未確認",
+			ImageURL: "https://example.com/a.jpg", Category: "カメラ", Status: model.ItemPublished, PublishedAt: &published},
+		{ID: "b", Name: "ラジオ", Price: 1200, ImageURL: "data:image/png;base64,AAAA", SoldOut: true, Status: model.ItemSold},
+	}
+	metaFixture = Meta{Title: "Shop", SiteURL: "https://shop.example.com", Updated: published}
+)
+
+func writeAll(t *testing.T, w Writer, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	for _, item := range itemsFixture {
+		if err := w.WriteItem(item); err != nil {
+			t.Fatalf("WriteItem: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// wellFormed: XML として最後まで読めるか
+func wellFormed(t *testing.T, data []byte) {
+	t.Helper()
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("invalid XML: %v\n%s", err, data)
+		}
+	}
+}
+
+// TestCSV はバックアップ CSV がそのまま一括出品で取り込めることのテスト
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSV(&buf)
+	writeAll(t, w, err)
+
+	rows, err := model.ParseImport(&buf, model.ImportCSV, 10)
+	if err != nil {
+		t.Fatalf("ParseImport: %v", err)
+	}
+	if len(rows) != 2 || rows[0].SKU != "SKU-1" || rows[0].Name != itemsFixture[0].Name || rows[0].Price != 3000 {
+		t.Errorf("round trip rows[0] = %+v", rows[0])
+	}
+}
+
+// TestXMLFeeds は Atom と商品フィードの出力のテスト
+func TestXMLFeeds(t *testing.T) {
+	var atom bytes.Buffer
+	w, err := NewAtom(&atom, metaFixture)
+	writeAll(t, w, err)
+	wellFormed(t, atom.Bytes())
+	if got := strings.Count(atom.String(), "<entry>"); got != 2 {
+		t.Errorf("Atom entries = %d, want 2", got)
+	}
+	if !strings.Contains(atom.String(), "<id>https://shop.example.com/items/a</id>") {
+		t.Errorf("Atom entry id is missing:\n%s", atom.String())
+	}
+
+	var products bytes.Buffer
+	w, err = NewProductFeed(&products, metaFixture)
+	writeAll(t, w, err)
+	wellFormed(t, products.Bytes())
+	out := products.String()
+	for _, want := range []string{"<g:price>3000 JPY</g:price>", "<g:availability>out_of_stock</g:availability>", "<g:image_link>https://example.com/a.jpg</g:image_link>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("product feed does not contain %s", want)
+		}
+	}
+	if strings.Contains(out, "data:image") {
+		t.Error("data URL の画像がフィードに含まれている")
+	}
+}
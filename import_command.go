@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"hackathon-backend/controller"
+	"hackathon-backend/usecase"
+)
+
+// runImportCommand: サーバーを起動せずに一括出品する（終了コードを返す）
+//
+//	hackathon-backend import -seller USER_ID [-format csv|jsonl] [-dry-run] FILE
+//
+// FILE に "-" を指定すると標準入力から読む。結果は JSON で標準出力に書く
+func runImportCommand(uc *usecase.ImportUsecase, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	sellerID := fs.String("seller", "", "出品者のユーザーID")
+	format := fs.String("format", "", "csv または jsonl（省略時は拡張子から判定）")
+	dryRun := fs.Bool("dry-run", false, "検証だけ行い、出品しない")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *sellerID == "" || fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: import -seller USER_ID [-format csv|jsonl] [-dry-run] FILE")
+		return 2
+	}
+
+	path := fs.Arg(0)
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fail: open %s, %v\n", path, err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+
+	report, err := uc.Import(*sellerID, in, controller.DetectImportFormat(*format, path, ""), *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail: import, %v\n", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	fmt.Fprintf(os.Stderr, "total %d, created %d, skipped %d, failed %d\n", report.Total, report.Created, report.Skipped, report.Failed)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -1,19 +1,28 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
 
+	"hackathon-backend/chathub"
 	"hackathon-backend/controller"
 	"hackathon-backend/dao"
+	"hackathon-backend/moderation"
+	"hackathon-backend/payment"
+	"hackathon-backend/upload"
 	"hackathon-backend/usecase"
 )
 
@@ -58,7 +67,22 @@ func main() {
 	userDAO := dao.NewUserDAO(db)
 	itemDAO := dao.NewItemDAO(db)
 	messageDAO := dao.NewMessageDAO(db)
+	conversationDAO := dao.NewConversationDAO(db)
+	attachmentDAO := dao.NewAttachmentDAO(db)
 	likeDAO := dao.NewLikeDAO(db)
+	orderDAO := dao.NewOrderDAO(db)
+	ledgerDAO := dao.NewLedgerDAO(db)
+	offerDAO := dao.NewOfferDAO(db)
+	reviewDAO := dao.NewReviewDAO(db)
+	reportDAO := dao.NewReportDAO(db)
+	notificationDAO := dao.NewNotificationDAO(db)
+	savedSearchDAO := dao.NewSavedSearchDAO(db)
+	jobCursorDAO := dao.NewJobCursorDAO(db)
+	analyticsDAO := dao.NewAnalyticsDAO(db)
+	categoryDAO := dao.NewCategoryDAO(db)
+	followDAO := dao.NewFollowDAO(db)
+	blockDAO := dao.NewBlockDAO(db)
+	couponDAO := dao.NewCouponDAO(db)
 
 	// Controller & Usecase
 	authController := controller.NewAuthController(userDAO)
@@ -68,10 +92,88 @@ func main() {
 	searchUserController := controller.NewSearchUserController(searchUserUsecase)
 	registerUserController := controller.NewRegisterUserController(registerUserUsecase)
 
-	itemController := controller.NewItemController(itemDAO)
-	geminiController := controller.NewGeminiController(itemDAO)
-	chatController := controller.NewChatController(messageDAO)
-	likeController := controller.NewLikeController(likeDAO)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryDAO, itemDAO)
+	if err := categoryUsecase.Init(); err != nil {
+		log.Printf("fail: init categories, %v\n", err)
+	}
+	categoryController := controller.NewCategoryController(categoryUsecase)
+
+	itemController := controller.NewItemController(itemDAO, categoryUsecase)
+	likeNotifier := usecase.NewLikeNotifier(likeDAO, notificationDAO)
+	listingUsecase := usecase.NewListingUsecase(itemDAO, likeNotifier, categoryUsecase)
+	listingController := controller.NewListingController(listingUsecase)
+	moderator := newModerator()
+	geminiController := controller.NewGeminiController(itemDAO, categoryUsecase, moderator)
+	blockUsecase := usecase.NewBlockUsecase(blockDAO, userDAO)
+	blockController := controller.NewBlockController(blockUsecase)
+	chatHub := chathub.NewHub(newChatBackend(dao.NewChatEventDAO(db)))
+	go func() {
+		// 配信が止まったまま SSE を受け付け続けないよう、中継が終わったらサーバーごと止める
+		err := chatHub.Run(context.Background())
+		log.Fatalf("fail: chat hub stopped, %v\n", err)
+	}()
+	chatUsecase := usecase.NewChatUsecase(conversationDAO, messageDAO, itemDAO, attachmentDAO, reportDAO, chatHub, moderator, blockUsecase,
+		time.Duration(envInt("MESSAGE_EDIT_WINDOW_MINUTES", 15))*time.Minute)
+	// ブロックしたら、二人のやりとりの配信中のストリームを閉じる
+	blockUsecase.OnBlock = chatUsecase.CloseBetween
+	if n, err := chatUsecase.MigrateLegacy(); err != nil {
+		log.Printf("fail: migrate legacy messages, %v\n", err)
+	} else if n > 0 {
+		log.Printf("migrated %d legacy messages into conversations\n", n)
+	}
+	chatController := controller.NewChatController(chatUsecase)
+	attachmentUsecase := usecase.NewAttachmentUsecase(attachmentDAO, chatUsecase, newUploadStore(db), envInt("ATTACHMENT_MAX_BYTES", 5<<20))
+	attachmentController := controller.NewAttachmentController(attachmentUsecase)
+	likeController := controller.NewLikeController(usecase.NewLikeUsecase(likeDAO, itemDAO, blockUsecase))
+
+	couponUsecase := usecase.NewCouponUsecase(couponDAO, categoryUsecase)
+	couponController := controller.NewCouponController(couponUsecase)
+
+	orderUsecase := usecase.NewOrderUsecase(orderDAO, itemDAO, newPaymentProvider(), platformFeeRateBps(), likeNotifier, couponUsecase, blockUsecase)
+	orderController := controller.NewOrderController(orderUsecase)
+	ledgerController := controller.NewLedgerController(ledgerDAO)
+
+	offerUsecase := usecase.NewOfferUsecase(offerDAO, itemDAO,
+		time.Duration(envInt("OFFER_TTL_HOURS", 24))*time.Hour,
+		time.Duration(envInt("OFFER_RESERVATION_HOURS", 24))*time.Hour, blockUsecase)
+	offerController := controller.NewOfferController(offerUsecase)
+
+	reviewUsecase := usecase.NewReviewUsecase(reviewDAO, orderDAO, time.Duration(envInt("REVIEW_REVEAL_DAYS", 14))*24*time.Hour)
+	reviewController := controller.NewReviewController(reviewUsecase)
+	profileController := controller.NewProfileController(userDAO, reviewDAO)
+
+	followUsecase := usecase.NewFollowUsecase(followDAO, itemDAO, userDAO)
+	followController := controller.NewFollowController(followUsecase)
+
+	reportUsecase := usecase.NewReportUsecase(reportDAO, userDAO, envInt("REPORT_HIDE_THRESHOLD", 3))
+	reportController := controller.NewReportController(reportUsecase)
+
+	savedSearchUsecase := usecase.NewSavedSearchUsecase(savedSearchDAO, notificationDAO, itemDAO, jobCursorDAO,
+		envInt("SAVED_SEARCH_MAX_PER_USER", 20), envInt("SAVED_SEARCH_MAX_ALERTS_PER_HOUR", 10))
+	savedSearchController := controller.NewSavedSearchController(savedSearchUsecase)
+	notificationController := controller.NewNotificationController(notificationDAO)
+
+	recommendationUsecase := usecase.NewRecommendationUsecase(likeDAO, itemDAO, blockUsecase)
+	recommendationController := controller.NewRecommendationController(recommendationUsecase)
+
+	analyticsUsecase := usecase.NewAnalyticsUsecase(analyticsDAO, itemDAO)
+	analyticsController := controller.NewAnalyticsController(analyticsUsecase)
+
+	importUsecase := usecase.NewImportUsecase(itemDAO, envInt("IMPORT_MAX_ROWS", 1000))
+	importController := controller.NewImportController(importUsecase)
+
+	siteURL := os.Getenv("PUBLIC_SITE_URL")
+	if siteURL == "" {
+		siteURL = "http://localhost:3000"
+	}
+	exportController := controller.NewExportController(itemDAO, siteURL)
+
+	// サブコマンド: 一括出品だけ行って終了する
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		code := runImportCommand(importUsecase, os.Args[2:])
+		db.Close()
+		os.Exit(code)
+	}
 
 	// --- 3. ルーティング設定 ---
 	mux := http.NewServeMux()
@@ -105,6 +207,61 @@ func main() {
 		}
 	})
 
+	mux.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			profileController.HandleGetProfile(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/users/{id}/items", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			followController.HandleStorefront(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 出品者のフォローとフォロー中の新着
+	mux.HandleFunc("/follows", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			followController.HandleToggleFollow(w, r)
+		case http.MethodGet:
+			followController.HandleGetFollowing(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/blocks", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			blockController.HandleBlock(w, r)
+		case http.MethodGet:
+			blockController.HandleGetBlocks(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/blocks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			blockController.HandleUnblock(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			followController.HandleFeed(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// 商品
 	mux.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -117,9 +274,379 @@ func main() {
 		}
 	})
 
+	// カテゴリ
+	mux.HandleFunc("/categories", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			categoryController.HandleList(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/categories/match", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			categoryController.HandleMatch(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// クーポン（管理者）
+	mux.HandleFunc("/admin/coupons", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			couponController.HandleList(w, r)
+		case http.MethodPost:
+			couponController.HandleCreate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/coupons/{code}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			couponController.HandleUpdate(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/categories", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			categoryController.HandleCreate(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/categories/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			categoryController.HandleUpdate(w, r)
+		case http.MethodDelete:
+			categoryController.HandleDelete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/items/import", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			importController.HandleImport(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/items/purchase", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
-			itemController.HandlePurchase(w, r)
+			orderController.HandlePurchase(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/items/purchase/quote", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			orderController.HandleQuote(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			listingController.HandleUpdateItem(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/items/{id}/price-history", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			listingController.HandleGetPriceHistory(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/items/{id}/similar", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			recommendationController.HandleGetSimilar(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/items/{id}/views", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			analyticsController.HandleRecordView(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/me/analytics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			analyticsController.HandleGetAnalytics(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/items/{id}/withdraw", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			listingController.HandleWithdraw(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// エクスポート・フィード
+	mux.HandleFunc("/export/items.csv", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			exportController.HandleExportCSV(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/feeds/atom.xml", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			exportController.HandleAtom(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/feeds/products.xml", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			exportController.HandleProductFeed(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 下書き・予約公開
+	mux.HandleFunc("/drafts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			listingController.HandleGetDrafts(w, r)
+		case http.MethodPost:
+			listingController.HandleCreateDraft(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/drafts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			listingController.HandleUpdateDraft(w, r)
+		case http.MethodDelete:
+			listingController.HandleDeleteDraft(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/drafts/{id}/publish", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			listingController.HandlePublish(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/recommendations", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			recommendationController.HandleGetRecommendations(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 保存した検索条件・通知
+	mux.HandleFunc("/saved-searches", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			savedSearchController.HandleList(w, r)
+		case http.MethodPost:
+			savedSearchController.HandleCreate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/saved-searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			savedSearchController.HandleDelete(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/notifications", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			notificationController.HandleList(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/notifications/read", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			notificationController.HandleMarkRead(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 値下げ交渉
+	mux.HandleFunc("/items/{id}/offers", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			offerController.HandleGetOffers(w, r)
+		case http.MethodPost:
+			offerController.HandleMakeOffer(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/offers/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			offerController.HandleAction(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 取引
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			orderController.HandleGetOrders(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			orderController.HandleGetOrder(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/orders/{id}/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			orderController.HandleTransition(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/orders/{id}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			reviewController.HandleGetOrderReviews(w, r)
+		case http.MethodPost:
+			reviewController.HandleSubmit(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 売上・振込
+	mux.HandleFunc("/balance", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			ledgerController.HandleGetBalance(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/balance/transactions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			ledgerController.HandleGetTransactions(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/payouts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			ledgerController.HandleGetPayouts(w, r)
+		case http.MethodPost:
+			ledgerController.HandleRequestPayout(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/payouts/{id}/paid", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			ledgerController.HandleMarkPayoutPaid(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/ledger/reconcile", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			ledgerController.HandleReconcile(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 通報・モデレーション
+	mux.HandleFunc("/reports", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			reportController.HandleCreateReport(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/reports", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			reportController.HandleGetQueue(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/reports/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			reportController.HandleModerate(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/messages/{id}/revisions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			chatController.HandleGetMessageHistory(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/moderation-log", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			reportController.HandleGetAuditLog(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// 決済プロバイダからの Webhook
+	mux.HandleFunc("/payments/webhook", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			orderController.HandlePaymentWebhook(w, r)
 		} else {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -168,7 +695,66 @@ func main() {
 		}
 	})
 
-	// チャット
+	// チャット（商品ごとの購入希望者と出品者のやりとり）
+	mux.HandleFunc("/conversations", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			chatController.HandleGetConversations(w, r)
+		case http.MethodPost:
+			chatController.HandleStartConversation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/conversations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			chatController.HandleGetConversation(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/conversations/unread", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			chatController.HandleGetUnread(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/conversations/{id}/read", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			chatController.HandleMarkRead(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/conversations/{id}/attachments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			attachmentController.HandleUpload(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/attachments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			attachmentController.HandleGet(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/attachments/{id}/thumbnail", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			attachmentController.HandleGetThumbnail(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
@@ -180,6 +766,25 @@ func main() {
 		}
 	})
 
+	mux.HandleFunc("/messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			chatController.HandleEditMessage(w, r)
+		case http.MethodDelete:
+			chatController.HandleUnsendMessage(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/messages/stream", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			chatController.HandleStream(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// いいね機能
 	mux.HandleFunc("/likes", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -192,6 +797,59 @@ func main() {
 		}
 	})
 
+	// --- 3.5 バックグラウンドジョブ ---
+	autoCompleteDays := envInt("ORDER_AUTO_COMPLETE_DAYS", 7)
+	startJob("order auto-complete", time.Hour, func() error {
+		n, err := orderUsecase.AutoComplete(time.Duration(autoCompleteDays) * 24 * time.Hour)
+		if n > 0 {
+			log.Printf("Auto-completed %d orders", n)
+		}
+		return err
+	})
+	startJob("escrow settlement", 10*time.Minute, orderUsecase.SettleEscrows)
+	startJob("review reveal", time.Hour, func() error {
+		_, err := reviewUsecase.RevealDue()
+		return err
+	})
+	startJob("offer expiry", 5*time.Minute, func() error {
+		_, err := offerUsecase.ExpireDue()
+		return err
+	})
+	startJob("scheduled publish", time.Minute, func() error {
+		items, err := listingUsecase.PublishDue()
+		if len(items) > 0 {
+			log.Printf("Published %d scheduled items", len(items))
+		}
+		return err
+	})
+	startJob("recommendation index", time.Duration(envPositiveInt("RECOMMEND_REFRESH_MINUTES", 30))*time.Minute, recommendationUsecase.Refresh)
+	startJob("analytics rollup", time.Hour, analyticsUsecase.Rollup)
+	startJob("category reload", 5*time.Minute, categoryUsecase.Reload)
+	startJob("attachment cleanup", time.Hour, func() error {
+		n, err := attachmentUsecase.CleanupUnattached(24 * time.Hour)
+		if n > 0 {
+			log.Printf("Deleted %d unsent attachments", n)
+		}
+		return err
+	})
+	// 終わったやりとりは、最後の動きから MESSAGE_RETENTION_DAYS 日たったらメッセージごと削除する（0 なら削除しない）
+	if retentionDays := envInt("MESSAGE_RETENTION_DAYS", 365); retentionDays > 0 {
+		startJob("message retention", 24*time.Hour, func() error {
+			n, err := chatUsecase.PurgeClosed(time.Duration(retentionDays) * 24 * time.Hour)
+			if n > 0 {
+				log.Printf("Purged %d messages from closed conversations", n)
+			}
+			return err
+		})
+	}
+	startJob("saved search alerts", time.Minute, func() error {
+		n, err := savedSearchUsecase.NotifyNewListings()
+		if n > 0 {
+			log.Printf("Sent %d saved search alerts", n)
+		}
+		return err
+	})
+
 	// --- 4. サーバー起動 ---
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -221,7 +879,7 @@ func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -232,6 +890,125 @@ func enableCORS(next http.Handler) http.Handler {
 	})
 }
 
+// startJob: fn を interval ごとに実行する（起動直後にも1回実行）
+func startJob(name string, interval time.Duration, fn func() error) {
+	if interval <= 0 {
+		log.Fatalf("fail: job %s, interval must be positive: %v\n", name, interval)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := fn(); err != nil {
+				log.Printf("fail: job %s, %v\n", name, err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// newChatBackend: CHAT_PUBSUB に応じてチャット配信のサーバー間中継を選ぶ（既定はサーバー内のみ）
+// 複数台で動かすときは sql にして、共有 DB の chat_events を CHAT_POLL_MS ごとにポーリングする
+func newChatBackend(eventLog chathub.EventLog) chathub.Backend {
+	if os.Getenv("CHAT_PUBSUB") == "sql" {
+		log.Println("Chat pub/sub: sql")
+		return chathub.NewSQLBackend(eventLog, time.Duration(envPositiveInt("CHAT_POLL_MS", 500))*time.Millisecond, 10*time.Minute)
+	}
+	return chathub.NewLocalBackend()
+}
+
+// newUploadStore: UPLOAD_STORE に応じてアップロードの保存先を選ぶ（既定は UPLOAD_DIR のディレクトリ）
+// sql なら DB に保存する（複数サーバー・ディスクが消える環境向け）
+func newUploadStore(db *sql.DB) upload.Store {
+	if os.Getenv("UPLOAD_STORE") == "sql" {
+		log.Println("Upload store: sql")
+		return dao.NewBlobDAO(db)
+	}
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	log.Printf("Upload store: dir %s", dir)
+	return upload.NewDirStore(dir)
+}
+
+// newPaymentProvider: PAYMENT_PROVIDER に応じて決済プロバイダを選ぶ（既定はフェイク）
+func newPaymentProvider() payment.Provider {
+	webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if os.Getenv("PAYMENT_PROVIDER") == "stripe" {
+		if webhookSecret == "" {
+			log.Fatal("Fatal: PAYMENT_WEBHOOK_SECRET is required for the stripe payment provider")
+		}
+		log.Println("Payment provider: stripe")
+		return payment.NewStripeProvider(os.Getenv("STRIPE_API_BASE"), os.Getenv("STRIPE_SECRET_KEY"), webhookSecret)
+	}
+	log.Println("Payment provider: fake (no real charges)")
+	if webhookSecret == "" {
+		log.Println("PAYMENT_WEBHOOK_SECRET is not set; all payment webhooks will be rejected")
+	}
+	p := payment.NewFakeProvider(webhookSecret)
+	p.Run = strconv.FormatInt(time.Now().Unix(), 36)
+	return p
+}
+
+// newModerator: メッセージの審査（組み込みの NG ワードに MODERATION_RULES_FILE のルールを追加し、GEMINI_API_KEY があれば AI でも判定）
+// MODERATION_AI=off で AI を使わず、MODERATION_FAIL_MODE=closed で AI の障害時には送信させない
+func newModerator() *moderation.Moderator {
+	rules, err := moderation.DefaultRules()
+	if err != nil {
+		log.Fatalf("Fatal: invalid built-in moderation rules: %v", err)
+	}
+	if path := os.Getenv("MODERATION_RULES_FILE"); path != "" {
+		extra, err := moderation.LoadRules(path)
+		if err != nil {
+			log.Fatalf("Fatal: failed to load moderation rules: %v", err)
+		}
+		rules = append(rules, extra...)
+	}
+	ruleSet, err := moderation.NewRuleSet(rules)
+	if err != nil {
+		log.Fatalf("Fatal: invalid moderation rules: %v", err)
+	}
+
+	m := &moderation.Moderator{
+		Rules:    ruleSet,
+		FailOpen: os.Getenv("MODERATION_FAIL_MODE") != "closed",
+		Timeout:  time.Duration(envInt("MODERATION_AI_TIMEOUT_MS", 5000)) * time.Millisecond,
+	}
+	if key := strings.TrimSpace(os.Getenv("GEMINI_API_KEY")); key != "" && os.Getenv("MODERATION_AI") != "off" {
+		m.AI = moderation.NewGeminiChecker(key)
+	}
+	log.Printf("Moderation: %d rules, ai=%v, fail_open=%v", len(rules), m.AI != nil, m.FailOpen)
+	return m
+}
+
+// platformFeeRateBps: PLATFORM_FEE_RATE（例: 0.1 = 10%）をベーシスポイントに変換する
+func platformFeeRateBps() int {
+	rate, err := strconv.ParseFloat(os.Getenv("PLATFORM_FEE_RATE"), 64)
+	if err != nil || rate < 0 || rate >= 1 {
+		return 1000 // 既定: 10%
+	}
+	return int(math.Round(rate * 10000))
+}
+
+// envInt: 整数の環境変数を読み込む（未設定・不正な値なら def）
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// envPositiveInt: 正の整数の環境変数を読み込む（未設定・不正な値・0 以下なら def）
+func envPositiveInt(key string, def int) int {
+	if v := envInt(key, def); v > 0 {
+		return v
+	}
+	log.Printf("%s must be positive, using %d\n", key, def)
+	return def
+}
+
 // createTables: テーブル作成とインデックス追加
 func createTables(db *sql.DB) error {
 	// Itemテーブル
@@ -267,12 +1044,57 @@ func createTables(db *sql.DB) error {
         item_id VARCHAR(255),
         sender_id VARCHAR(255),
         content TEXT,
-        created_at VARCHAR(255)
+        created_at DATETIME(6) NOT NULL
     );`
 	if _, err := db.Exec(queryMsg); err != nil {
 		return fmt.Errorf("create messages table error: %w", err)
 	}
 
+	// メッセージの本文の履歴（編集・送信取り消しの前の本文。モデレーター向け）
+	queryMessageRevisions := `
+    CREATE TABLE IF NOT EXISTS message_revisions (
+        id VARCHAR(255) PRIMARY KEY,
+        message_id VARCHAR(255) NOT NULL,
+        action VARCHAR(16) NOT NULL,
+        content TEXT NOT NULL,
+        created_at DATETIME(6) NOT NULL,
+        INDEX idx_message_revisions_message (message_id, created_at)
+    );`
+	if _, err := db.Exec(queryMessageRevisions); err != nil {
+		return fmt.Errorf("create message_revisions table error: %w", err)
+	}
+
+	// やりとりテーブル（商品と購入希望者の組ごとに1つ）
+	queryConversations := `
+    CREATE TABLE IF NOT EXISTS conversations (
+        id VARCHAR(255) PRIMARY KEY,
+        item_id VARCHAR(255) NOT NULL,
+        buyer_id VARCHAR(255) NOT NULL,
+        seller_id VARCHAR(255) NOT NULL,
+        last_message_id VARCHAR(255) NOT NULL DEFAULT '',
+        created_at DATETIME NOT NULL,
+        updated_at DATETIME NOT NULL,
+        UNIQUE KEY uniq_conversation (item_id, buyer_id),
+        INDEX idx_conversations_buyer (buyer_id, updated_at),
+        INDEX idx_conversations_seller (seller_id, updated_at)
+    );`
+	if _, err := db.Exec(queryConversations); err != nil {
+		return fmt.Errorf("create conversations table error: %w", err)
+	}
+
+	// ブロックテーブル（blocker_id が blocked_id をブロックしている）
+	queryBlocks := `
+    CREATE TABLE IF NOT EXISTS blocks (
+        blocker_id VARCHAR(255) NOT NULL,
+        blocked_id VARCHAR(255) NOT NULL,
+        created_at DATETIME NOT NULL,
+        PRIMARY KEY (blocker_id, blocked_id),
+        INDEX idx_blocks_blocked (blocked_id)
+    );`
+	if _, err := db.Exec(queryBlocks); err != nil {
+		return fmt.Errorf("create blocks table error: %w", err)
+	}
+
 	// いいねテーブル
 	queryLikes := `
     CREATE TABLE IF NOT EXISTS likes (
@@ -285,6 +1107,372 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("create likes table error: %w", err)
 	}
 
+	// 注文テーブル
+	queryOrders := `
+    CREATE TABLE IF NOT EXISTS orders (
+        id VARCHAR(255) PRIMARY KEY,
+        item_id VARCHAR(255) NOT NULL,
+        buyer_id VARCHAR(255) NOT NULL,
+        seller_id VARCHAR(255) NOT NULL,
+        price INT NOT NULL,
+        status VARCHAR(32) NOT NULL,
+        created_at DATETIME NOT NULL,
+        updated_at DATETIME NOT NULL,
+        paid_at DATETIME NULL,
+        shipped_at DATETIME NULL,
+        received_at DATETIME NULL,
+        completed_at DATETIME NULL,
+        cancelled_at DATETIME NULL,
+        INDEX idx_orders_buyer (buyer_id),
+        INDEX idx_orders_seller (seller_id),
+        INDEX idx_orders_status (status, updated_at)
+    );`
+	if _, err := db.Exec(queryOrders); err != nil {
+		return fmt.Errorf("create orders table error: %w", err)
+	}
+
+	// 注文の状態遷移ログ（監査用）
+	queryOrderEvents := `
+    CREATE TABLE IF NOT EXISTS order_events (
+        id VARCHAR(255) PRIMARY KEY,
+        order_id VARCHAR(255) NOT NULL,
+        from_status VARCHAR(32) NOT NULL,
+        to_status VARCHAR(32) NOT NULL,
+        actor_id VARCHAR(255) NOT NULL,
+        actor_role VARCHAR(32) NOT NULL,
+        created_at DATETIME NOT NULL,
+        INDEX idx_order_events_order (order_id)
+    );`
+	if _, err := db.Exec(queryOrderEvents); err != nil {
+		return fmt.Errorf("create order_events table error: %w", err)
+	}
+
+	// 売上台帳（複式簿記）
+	queryLedger := `
+    CREATE TABLE IF NOT EXISTS ledger_entries (
+        id VARCHAR(255) PRIMARY KEY,
+        txn_id VARCHAR(255) NOT NULL,
+        account VARCHAR(255) NOT NULL,
+        amount INT NOT NULL,
+        entry_type VARCHAR(32) NOT NULL,
+        order_id VARCHAR(255) NOT NULL DEFAULT '',
+        payout_id VARCHAR(255) NOT NULL DEFAULT '',
+        created_at DATETIME NOT NULL,
+        INDEX idx_ledger_account (account, created_at),
+        INDEX idx_ledger_txn (txn_id)
+    );`
+	if _, err := db.Exec(queryLedger); err != nil {
+		return fmt.Errorf("create ledger_entries table error: %w", err)
+	}
+
+	// 振込申請
+	queryPayouts := `
+    CREATE TABLE IF NOT EXISTS payout_requests (
+        id VARCHAR(255) PRIMARY KEY,
+        seller_id VARCHAR(255) NOT NULL,
+        amount INT NOT NULL,
+        status VARCHAR(32) NOT NULL,
+        created_at DATETIME NOT NULL,
+        paid_at DATETIME NULL,
+        INDEX idx_payouts_seller (seller_id)
+    );`
+	if _, err := db.Exec(queryPayouts); err != nil {
+		return fmt.Errorf("create payout_requests table error: %w", err)
+	}
+
+	// 値下げ交渉（active は交渉中のみ 1、購入者ごとに1件まで）
+	queryOffers := `
+    CREATE TABLE IF NOT EXISTS offers (
+        id VARCHAR(255) PRIMARY KEY,
+        item_id VARCHAR(255) NOT NULL,
+        buyer_id VARCHAR(255) NOT NULL,
+        seller_id VARCHAR(255) NOT NULL,
+        amount INT NOT NULL,
+        status VARCHAR(32) NOT NULL,
+        active TINYINT NULL,
+        expires_at DATETIME NOT NULL,
+        created_at DATETIME NOT NULL,
+        updated_at DATETIME NOT NULL,
+        UNIQUE KEY uq_offers_active (item_id, buyer_id, active),
+        INDEX idx_offers_status (status, expires_at)
+    );`
+	if _, err := db.Exec(queryOffers); err != nil {
+		return fmt.Errorf("create offers table error: %w", err)
+	}
+
+	// 取引後の相互評価（公開されるまで相手には見えない）
+	queryReviews := `
+    CREATE TABLE IF NOT EXISTS reviews (
+        id VARCHAR(255) PRIMARY KEY,
+        order_id VARCHAR(255) NOT NULL,
+        reviewer_id VARCHAR(255) NOT NULL,
+        reviewee_id VARCHAR(255) NOT NULL,
+        reviewer_role VARCHAR(32) NOT NULL,
+        rating TINYINT NOT NULL,
+        comment TEXT,
+        created_at DATETIME NOT NULL,
+        revealed_at DATETIME NULL,
+        UNIQUE KEY uq_reviews_order_reviewer (order_id, reviewer_id),
+        INDEX idx_reviews_reviewee (reviewee_id, revealed_at)
+    );`
+	if _, err := db.Exec(queryReviews); err != nil {
+		return fmt.Errorf("create reviews table error: %w", err)
+	}
+
+	// 公開済みの評価の集計（商品一覧に出品者の評価を載せるため）
+	queryReputation := `
+    CREATE TABLE IF NOT EXISTS user_reputation (
+        user_id VARCHAR(255) PRIMARY KEY,
+        rating_sum INT NOT NULL DEFAULT 0,
+        rating_count INT NOT NULL DEFAULT 0,
+        good INT NOT NULL DEFAULT 0,
+        normal INT NOT NULL DEFAULT 0,
+        bad INT NOT NULL DEFAULT 0
+    );`
+	if _, err := db.Exec(queryReputation); err != nil {
+		return fmt.Errorf("create user_reputation table error: %w", err)
+	}
+
+	// 通報（open_flag は未対応のみ 1、同じ人が同じ対象を重複して通報できない）
+	queryReports := `
+    CREATE TABLE IF NOT EXISTS reports (
+        id VARCHAR(255) PRIMARY KEY,
+        target_type VARCHAR(32) NOT NULL,
+        target_id VARCHAR(255) NOT NULL,
+        reporter_id VARCHAR(255) NOT NULL,
+        reason VARCHAR(32) NOT NULL,
+        note TEXT NOT NULL,
+        status VARCHAR(32) NOT NULL,
+        claimed_by VARCHAR(255) NOT NULL DEFAULT '',
+        open_flag TINYINT NULL,
+        created_at DATETIME NOT NULL,
+        updated_at DATETIME NOT NULL,
+        UNIQUE KEY uq_reports_open (target_type, target_id, reporter_id, open_flag),
+        INDEX idx_reports_status (status, created_at)
+    );`
+	if _, err := db.Exec(queryReports); err != nil {
+		return fmt.Errorf("create reports table error: %w", err)
+	}
+
+	// モデレーション操作の監査ログ
+	queryModActions := `
+    CREATE TABLE IF NOT EXISTS moderation_actions (
+        id VARCHAR(255) PRIMARY KEY,
+        report_id VARCHAR(255) NOT NULL DEFAULT '',
+        target_type VARCHAR(32) NOT NULL,
+        target_id VARCHAR(255) NOT NULL,
+        moderator_id VARCHAR(255) NOT NULL,
+        action VARCHAR(32) NOT NULL,
+        note TEXT NOT NULL,
+        created_at DATETIME NOT NULL,
+        INDEX idx_mod_actions_target (target_id)
+    );`
+	if _, err := db.Exec(queryModActions); err != nil {
+		return fmt.Errorf("create moderation_actions table error: %w", err)
+	}
+
+	// 通知（保存した検索の新着など）
+	queryNotifications := `
+    CREATE TABLE IF NOT EXISTS notifications (
+        id VARCHAR(255) PRIMARY KEY,
+        user_id VARCHAR(255) NOT NULL,
+        type VARCHAR(32) NOT NULL,
+        item_id VARCHAR(255) NOT NULL DEFAULT '',
+        source_id VARCHAR(255) NOT NULL DEFAULT '',
+        message TEXT NOT NULL,
+        dedup_key VARCHAR(255) NOT NULL,
+        read_at DATETIME NULL,
+        created_at DATETIME NOT NULL,
+        UNIQUE KEY uq_notifications_dedup (user_id, dedup_key),
+        INDEX idx_notifications_user (user_id, created_at),
+        INDEX idx_notifications_source (source_id, created_at)
+    );`
+	if _, err := db.Exec(queryNotifications); err != nil {
+		return fmt.Errorf("create notifications table error: %w", err)
+	}
+
+	// 商品の価格変更の履歴
+	queryPriceHistory := `
+    CREATE TABLE IF NOT EXISTS item_price_history (
+        id BIGINT AUTO_INCREMENT PRIMARY KEY,
+        item_id VARCHAR(255) NOT NULL,
+        old_price INT NOT NULL,
+        new_price INT NOT NULL,
+        changed_at DATETIME NOT NULL,
+        INDEX idx_item_price_history_item (item_id, changed_at)
+    );`
+	if _, err := db.Exec(queryPriceHistory); err != nil {
+		return fmt.Errorf("create item_price_history table error: %w", err)
+	}
+
+	// 保存した検索条件
+	querySavedSearches := `
+    CREATE TABLE IF NOT EXISTS saved_searches (
+        id VARCHAR(255) PRIMARY KEY,
+        user_id VARCHAR(255) NOT NULL,
+        keyword VARCHAR(255) NOT NULL DEFAULT '',
+        min_price INT NOT NULL DEFAULT 0,
+        max_price INT NOT NULL DEFAULT 0,
+        created_at DATETIME NOT NULL,
+        INDEX idx_saved_searches_user (user_id)
+    );`
+	if _, err := db.Exec(querySavedSearches); err != nil {
+		return fmt.Errorf("create saved_searches table error: %w", err)
+	}
+
+	// 商品の閲覧（同じ閲覧者・同じ日は1件）
+	queryItemViews := `
+    CREATE TABLE IF NOT EXISTS item_views (
+        item_id VARCHAR(255) NOT NULL,
+        viewer_key VARCHAR(255) NOT NULL,
+        view_date DATE NOT NULL,
+        created_at DATETIME NOT NULL,
+        PRIMARY KEY (item_id, viewer_key, view_date),
+        INDEX idx_item_views_date (view_date)
+    );`
+	if _, err := db.Exec(queryItemViews); err != nil {
+		return fmt.Errorf("create item_views table error: %w", err)
+	}
+
+	// 商品ごと・日ごとの集計（日次ロールアップ）
+	queryDailyStats := `
+    CREATE TABLE IF NOT EXISTS item_daily_stats (
+        item_id VARCHAR(255) NOT NULL,
+        seller_id VARCHAR(255) NOT NULL,
+        day DATE NOT NULL,
+        views INT NOT NULL DEFAULT 0,
+        likes INT NOT NULL DEFAULT 0,
+        threads INT NOT NULL DEFAULT 0,
+        orders INT NOT NULL DEFAULT 0,
+        PRIMARY KEY (item_id, day),
+        INDEX idx_item_daily_stats_seller (seller_id, day)
+    );`
+	if _, err := db.Exec(queryDailyStats); err != nil {
+		return fmt.Errorf("create item_daily_stats table error: %w", err)
+	}
+
+	// チャットのリアルタイム配信をサーバー間で中継するイベントログ（短期間だけ残す）
+	queryChatEvents := `
+    CREATE TABLE IF NOT EXISTS chat_events (
+        seq BIGINT AUTO_INCREMENT PRIMARY KEY,
+        topic VARCHAR(255) NOT NULL,
+        event_id VARCHAR(255) NOT NULL,
+        type VARCHAR(32) NOT NULL,
+        data MEDIUMTEXT NOT NULL,
+        created_at DATETIME(6) NOT NULL,
+        INDEX idx_chat_events_created (created_at)
+    );`
+	if _, err := db.Exec(queryChatEvents); err != nil {
+		return fmt.Errorf("create chat_events table error: %w", err)
+	}
+
+	// キャンペーンのクーポン（categories は slug の JSON 配列）
+	queryCoupons := `
+    CREATE TABLE IF NOT EXISTS coupons (
+        code VARCHAR(64) PRIMARY KEY,
+        description VARCHAR(255) NOT NULL DEFAULT '',
+        discount_type VARCHAR(16) NOT NULL,
+        amount INT NOT NULL,
+        max_discount INT NOT NULL DEFAULT 0,
+        min_price INT NOT NULL DEFAULT 0,
+        categories TEXT,
+        first_purchase_only BOOLEAN NOT NULL DEFAULT FALSE,
+        starts_at DATETIME NULL,
+        ends_at DATETIME NULL,
+        max_redemptions INT NOT NULL DEFAULT 0,
+        max_per_user INT NOT NULL DEFAULT 0,
+        redeemed_count INT NOT NULL DEFAULT 0,
+        active BOOLEAN NOT NULL DEFAULT TRUE,
+        created_at DATETIME NOT NULL
+    );`
+	if _, err := db.Exec(queryCoupons); err != nil {
+		return fmt.Errorf("create coupons table error: %w", err)
+	}
+
+	// クーポンの利用（1注文に1枚）
+	queryCouponRedemptions := `
+    CREATE TABLE IF NOT EXISTS coupon_redemptions (
+        id VARCHAR(255) PRIMARY KEY,
+        code VARCHAR(64) NOT NULL,
+        user_id VARCHAR(255) NOT NULL,
+        order_id VARCHAR(255) NOT NULL,
+        discount INT NOT NULL,
+        created_at DATETIME NOT NULL,
+        UNIQUE KEY uq_coupon_redemptions_order (order_id),
+        INDEX idx_coupon_redemptions_user (code, user_id)
+    );`
+	if _, err := db.Exec(queryCouponRedemptions); err != nil {
+		return fmt.Errorf("create coupon_redemptions table error: %w", err)
+	}
+
+	// 出品者のフォロー
+	queryFollows := `
+    CREATE TABLE IF NOT EXISTS follows (
+        follower_id VARCHAR(255) NOT NULL,
+        seller_id VARCHAR(255) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (follower_id, seller_id),
+        INDEX idx_follows_seller (seller_id)
+    );`
+	if _, err := db.Exec(queryFollows); err != nil {
+		return fmt.Errorf("create follows table error: %w", err)
+	}
+
+	// メッセージの添付画像（message_id は送信前は空）
+	queryAttachments := `
+    CREATE TABLE IF NOT EXISTS attachments (
+        id VARCHAR(255) PRIMARY KEY,
+        conversation_id VARCHAR(255) NOT NULL,
+        message_id VARCHAR(255) NOT NULL DEFAULT '',
+        uploader_id VARCHAR(255) NOT NULL,
+        content_type VARCHAR(64) NOT NULL,
+        size INT NOT NULL,
+        width INT NOT NULL,
+        height INT NOT NULL,
+        has_thumbnail BOOLEAN NOT NULL DEFAULT FALSE,
+        created_at DATETIME NOT NULL,
+        INDEX idx_attachments_message (message_id, created_at)
+    );`
+	if _, err := db.Exec(queryAttachments); err != nil {
+		return fmt.Errorf("create attachments table error: %w", err)
+	}
+
+	// UPLOAD_STORE=sql のときのアップロードの保存先
+	queryBlobs := `
+    CREATE TABLE IF NOT EXISTS blobs (
+        blob_key VARCHAR(255) PRIMARY KEY,
+        data MEDIUMBLOB NOT NULL,
+        created_at DATETIME NOT NULL
+    );`
+	if _, err := db.Exec(queryBlobs); err != nil {
+		return fmt.Errorf("create blobs table error: %w", err)
+	}
+
+	// 商品カテゴリの木（parent_slug が空なら最上位）
+	queryCategories := `
+    CREATE TABLE IF NOT EXISTS categories (
+        slug VARCHAR(64) PRIMARY KEY,
+        parent_slug VARCHAR(64) NOT NULL DEFAULT '',
+        name_ja VARCHAR(255) NOT NULL,
+        name_en VARCHAR(255) NOT NULL DEFAULT '',
+        aliases TEXT,
+        sort_order INT NOT NULL DEFAULT 0,
+        INDEX idx_categories_parent (parent_slug)
+    );`
+	if _, err := db.Exec(queryCategories); err != nil {
+		return fmt.Errorf("create categories table error: %w", err)
+	}
+
+	// 定期ジョブの処理済み位置
+	queryJobCursors := `
+    CREATE TABLE IF NOT EXISTS job_cursors (
+        name VARCHAR(64) PRIMARY KEY,
+        cursor_at DATETIME(6) NOT NULL
+    );`
+	if _, err := db.Exec(queryJobCursors); err != nil {
+		return fmt.Errorf("create job_cursors table error: %w", err)
+	}
+
 	// 検索を高速化するためのインデックス
 	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_messages_item_id ON messages (item_id);"); err != nil {
 		log.Printf("Note: index creation (messages) might affect: %v", err)
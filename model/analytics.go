@@ -0,0 +1,94 @@
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+// ItemDailyStats: 商品ごと・日ごとの集計（日次ロールアップの1行）
+type ItemDailyStats struct {
+	ItemID   string `json:"item_id"`
+	SellerID string `json:"-"`
+	Day      string `json:"day"` // YYYY-MM-DD
+	Views    int    `json:"views"`
+	Likes    int    `json:"likes"`
+	Threads  int    `json:"threads"` // 新しく問い合わせてきた人数
+	Orders   int    `json:"orders"`
+}
+
+// StatsSummary: 期間中の閲覧・いいね・問い合わせ・購入の合計
+type StatsSummary struct {
+	Views      int     `json:"views"`
+	Likes      int     `json:"likes"`
+	Threads    int     `json:"threads"`
+	Orders     int     `json:"orders"`
+	Conversion float64 `json:"conversion"` // 購入数 / 閲覧数
+}
+
+// ItemSummary: 商品ごとの期間中の合計
+type ItemSummary struct {
+	ItemID string `json:"item_id"`
+	Name   string `json:"name"`
+	StatsSummary
+}
+
+// DaySummary: 日ごとの全商品の合計
+type DaySummary struct {
+	Day string `json:"day"`
+	StatsSummary
+}
+
+// SellerAnalytics: 出品者向けの分析
+type SellerAnalytics struct {
+	From   string         `json:"from"`
+	To     string         `json:"to"`
+	Totals StatsSummary   `json:"totals"`
+	Items  []*ItemSummary `json:"items"`
+	Daily  []*DaySummary  `json:"daily"`
+}
+
+// ConversionRate: 閲覧数に対する購入数の割合（小数第4位まで）
+func ConversionRate(orders, views int) float64 {
+	if views == 0 {
+		return 0
+	}
+	return math.Round(float64(orders)/float64(views)*10000) / 10000
+}
+
+func (s *StatsSummary) add(row *ItemDailyStats) {
+	s.Views += row.Views
+	s.Likes += row.Likes
+	s.Threads += row.Threads
+	s.Orders += row.Orders
+	s.Conversion = ConversionRate(s.Orders, s.Views)
+}
+
+// SummarizeStats: 日次の集計を、合計・商品ごと（閲覧数の多い順）・日ごと（日付順）にまとめる
+// names は商品IDから商品名への対応
+func SummarizeStats(rows []*ItemDailyStats, names map[string]string, from, to string) *SellerAnalytics {
+	a := &SellerAnalytics{From: from, To: to, Items: []*ItemSummary{}, Daily: []*DaySummary{}}
+	items := map[string]*ItemSummary{}
+	days := map[string]*DaySummary{}
+	for _, row := range rows {
+		a.Totals.add(row)
+		if items[row.ItemID] == nil {
+			items[row.ItemID] = &ItemSummary{ItemID: row.ItemID, Name: names[row.ItemID]}
+			a.Items = append(a.Items, items[row.ItemID])
+		}
+		items[row.ItemID].add(row)
+		if days[row.Day] == nil {
+			days[row.Day] = &DaySummary{Day: row.Day}
+			a.Daily = append(a.Daily, days[row.Day])
+		}
+		days[row.Day].add(row)
+	}
+
+	sort.SliceStable(a.Items, func(i, j int) bool {
+		if a.Items[i].Views != a.Items[j].Views {
+			return a.Items[i].Views > a.Items[j].Views
+		}
+		return a.Items[i].ItemID < a.Items[j].ItemID
+	})
+	sort.Slice(a.Daily, func(i, j int) bool { return a.Daily[i].Day < a.Daily[j].Day })
+	return a
+}
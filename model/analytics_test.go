@@ -0,0 +1,23 @@
+package model
+
+import "testing"
+
+// TestSummarizeStats は日次集計のまとめ方のテスト
+func TestSummarizeStats(t *testing.T) {
+	rows := []*ItemDailyStats{
+		{ItemID: "a", Day: "2026-10-02", Views: 10, Likes: 2, Threads: 1, Orders: 1},
+		{ItemID: "b", Day: "2026-10-01", Views: 30, Likes: 1},
+		{ItemID: "a", Day: "2026-10-01", Views: 10, Likes: 1, Threads: 2},
+	}
+	got := SummarizeStats(rows, map[string]string{"a": "カメラ", "b": "レンズ"}, "2026-10-01", "2026-10-02")
+
+	if got.Totals.Views != 50 || got.Totals.Orders != 1 || got.Totals.Conversion != 0.02 {
+		t.Errorf("Totals = %+v, want views 50, orders 1, conversion 0.02", got.Totals)
+	}
+	if len(got.Items) != 2 || got.Items[0].ItemID != "b" || got.Items[1].Name != "カメラ" || got.Items[1].Conversion != 0.05 {
+		t.Errorf("Items = %+v, %+v", got.Items[0], got.Items[1])
+	}
+	if len(got.Daily) != 2 || got.Daily[0].Day != "2026-10-01" || got.Daily[0].Views != 40 {
+		t.Errorf("Daily = %+v, %+v", got.Daily[0], got.Daily[1])
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Attachment: メッセージに添付した画像
+// 先にアップロードしておき、メッセージの送信時にそのメッセージに結びつける
+type Attachment struct {
+	ID             string `json:"id"`
+	ConversationID string `json:"conversation_id"`
+	// MessageID: 添付したメッセージ（送信前は空）
+	MessageID    string    `json:"message_id,omitempty"`
+	UploaderID   string    `json:"uploader_id"`
+	ContentType  string    `json:"content_type"`
+	Size         int       `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	HasThumbnail bool      `json:"has_thumbnail"`
+	CreatedAt    time.Time `json:"created_at"`
+	// URL・ThumbnailURL: 取得用の API のパス（当事者の user_id をつけて取得する）
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// SetURLs: 取得用のパスを設定する
+func (a *Attachment) SetURLs() {
+	a.URL = "/attachments/" + a.ID
+	a.ThumbnailURL = ""
+	if a.HasThumbnail {
+		a.ThumbnailURL = a.URL + "/thumbnail"
+	}
+}
+
+// CanView: ユーザーがこの添付を見られるか（送信前は本人だけ、送信後はやりとりの当事者）
+func (a *Attachment) CanView(c *Conversation, userID string) bool {
+	if a.MessageID == "" {
+		return userID != "" && userID == a.UploaderID
+	}
+	return c.IsParticipant(userID)
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Block: ユーザーのブロック（ブロックした側・された側の間では、メッセージ・いいね・値下げ交渉・購入ができない）
+type Block struct {
+	BlockerID   string    `json:"blocker_id"`
+	BlockedID   string    `json:"blocked_id"`
+	BlockedName string    `json:"blocked_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+)
+
+var (
+	ErrInvalidCategorySlug = errors.New("slug must be lowercase letters, digits and hyphens")
+	ErrCategoryNameMissing = errors.New("name_ja and name_en are required")
+)
+
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Category: 商品カテゴリ（親子関係を持つ）
+// 商品の Category にはカテゴリの Slug を保存する
+type Category struct {
+	Slug       string      `json:"slug"`
+	ParentSlug string      `json:"parent,omitempty"`
+	NameJa     string      `json:"name_ja"`
+	NameEn     string      `json:"name_en"`
+	Aliases    []string    `json:"aliases,omitempty"` // AI の提案などを対応付けるための別名
+	SortOrder  int         `json:"sort_order"`
+	Children   []*Category `json:"children,omitempty"`
+}
+
+// Validate: slug と名前を検証する
+func (c *Category) Validate() error {
+	if !categorySlugPattern.MatchString(c.Slug) || len(c.Slug) > 64 {
+		return ErrInvalidCategorySlug
+	}
+	if c.NameJa == "" || c.NameEn == "" {
+		return ErrCategoryNameMissing
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package model
+
+import "encoding/json"
+
+// ChatEventType: チャットでリアルタイムに配信するイベントの種類
+type ChatEventType string
+
+const (
+	ChatEventMessage ChatEventType = "message" // 新しいメッセージ
+	ChatEventRead    ChatEventType = "read"    // 相手がメッセージを読んだ（既読）
+	ChatEventEdit    ChatEventType = "edit"    // メッセージが編集された
+	ChatEventUnsend  ChatEventType = "unsend"  // メッセージの送信が取り消された
+	ChatEventClosed  ChatEventType = "closed"  // 当事者のどちらかがブロックした（サーバー内の通知で、クライアントには送らない）
+)
+
+// ChatEvent: チャットの購読者に配信するイベント
+type ChatEvent struct {
+	// Seq: 複数サーバー間で共有するイベントログ上の通し番号（ローカル配信では 0）
+	Seq int64 `json:"-"`
+	// Topic: 配信先のチャット（やりとりの ID）
+	Topic string `json:"topic"`
+	// ID: 再接続時の再開位置（SSE の id。メッセージ ID。既読・編集・送信取り消しの通知では空）
+	ID   string          `json:"id"`
+	Type ChatEventType   `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
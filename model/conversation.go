@@ -0,0 +1,134 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxMessageLength: 1通のメッセージの最大文字数
+const MaxMessageLength = 2000
+
+// MaxAttachments: 1通のメッセージに添付できる画像の数
+const MaxAttachments = 4
+
+var (
+	// ErrInvalidMessage: 空（本文も添付もない）、または長すぎるメッセージ
+	ErrInvalidMessage = errors.New("message must be 1-2000 characters or have an attachment")
+	// ErrTooManyAttachments: 添付の数が多すぎる
+	ErrTooManyAttachments = errors.New("too many attachments (max 4)")
+)
+
+// Conversation: 商品についての購入希望者と出品者の1対1のやりとり
+type Conversation struct {
+	ID        string    `json:"id"`
+	ItemID    string    `json:"item_id"`
+	BuyerID   string    `json:"buyer_id"`
+	SellerID  string    `json:"seller_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt: 最後にメッセージが送られた日時（受信箱の並び順）
+	UpdatedAt time.Time `json:"updated_at"`
+	// MessageSeq: 最後のメッセージの通し番号。BuyerReadSeq・SellerReadSeq はそれぞれが読んだ最後の通し番号
+	MessageSeq    int `json:"message_seq"`
+	BuyerReadSeq  int `json:"buyer_read_seq"`
+	SellerReadSeq int `json:"seller_read_seq"`
+	// UnreadCount: 閲覧者にとっての未読数
+	UnreadCount int `json:"unread_count"`
+
+	// 受信箱の表示用
+	ItemName     string   `json:"item_name,omitempty"`
+	ItemImageURL string   `json:"item_image_url,omitempty"`
+	LastMessage  *Message `json:"last_message,omitempty"`
+}
+
+// IsParticipant: ユーザーがこのやりとりの当事者か
+func (c *Conversation) IsParticipant(userID string) bool {
+	return userID != "" && (userID == c.BuyerID || userID == c.SellerID)
+}
+
+// ReadSeqOf: ユーザーが読んだ最後のメッセージの通し番号（当事者でなければ 0）
+func (c *Conversation) ReadSeqOf(userID string) int {
+	switch {
+	case userID == "":
+		return 0
+	case userID == c.BuyerID:
+		return c.BuyerReadSeq
+	case userID == c.SellerID:
+		return c.SellerReadSeq
+	}
+	return 0
+}
+
+// UnreadFor: ユーザーの未読数
+// 自分がメッセージを送るとそこまでは既読になるので、既読位置より後はすべて相手のメッセージ
+func (c *Conversation) UnreadFor(userID string) int {
+	if !c.IsParticipant(userID) {
+		return 0
+	}
+	if n := c.MessageSeq - c.ReadSeqOf(userID); n > 0 {
+		return n
+	}
+	return 0
+}
+
+// SetReadStatus: 各メッセージに、相手（送信者でない方）が読んだかどうかを設定する
+func (c *Conversation) SetReadStatus(messages []*Message) {
+	for _, m := range messages {
+		peerReadSeq := c.SellerReadSeq
+		if m.SenderID == c.SellerID {
+			peerReadSeq = c.BuyerReadSeq
+		}
+		m.Read = m.Seq > 0 && m.Seq <= peerReadSeq
+	}
+}
+
+// ValidateMessage: 送信できるメッセージか（画像を添付するなら本文は空でもよい）
+func ValidateMessage(content string, attachments int) error {
+	if attachments > MaxAttachments {
+		return ErrTooManyAttachments
+	}
+	n := len([]rune(strings.TrimSpace(content)))
+	if n > MaxMessageLength || (n == 0 && attachments == 0) {
+		return ErrInvalidMessage
+	}
+	return nil
+}
+
+// AssignLegacyMessages: 商品ごとの共有チャットのメッセージを、購入希望者ごとのやりとりに振り分ける
+// 戻り値はメッセージ ID → 購入希望者 ID。
+// 出品者のメッセージは直前に書き込んだ購入希望者への返信とみなし、
+// それより前なら最初の購入希望者に振り分ける（購入希望者がいなければ振り分けない）。
+// 出品者が分からない（sellerID が空）ときは、どのメッセージも送信者自身のやりとりに振り分ける
+func AssignLegacyMessages(messages []*Message, sellerID string) map[string]string {
+	sorted := append([]*Message(nil), messages...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	firstBuyer := ""
+	for _, m := range sorted {
+		if m.SenderID != sellerID {
+			firstBuyer = m.SenderID
+			break
+		}
+	}
+
+	assigned := map[string]string{}
+	lastBuyer := ""
+	for _, m := range sorted {
+		switch {
+		case m.SenderID != sellerID:
+			lastBuyer = m.SenderID
+			assigned[m.ID] = m.SenderID
+		case lastBuyer != "":
+			assigned[m.ID] = lastBuyer
+		case firstBuyer != "":
+			assigned[m.ID] = firstBuyer
+		}
+	}
+	return assigned
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAssignLegacyMessages は商品ごとのチャットを購入希望者ごとのやりとりに振り分けることを確認する
+func TestAssignLegacyMessages(t *testing.T) {
+	at := func(hour, min int) time.Time { return time.Date(2026, 1, 1, hour, min, 0, 0, time.UTC) }
+	messages := []*Message{
+		{ID: "1", SenderID: "seller", CreatedAt: at(9, 0)},
+		{ID: "2", SenderID: "alice", CreatedAt: at(10, 0)},
+		{ID: "3", SenderID: "seller", CreatedAt: at(10, 5)},
+		{ID: "5", SenderID: "seller", CreatedAt: at(11, 30)},
+		{ID: "4", SenderID: "bob", CreatedAt: at(11, 0)},
+		{ID: "6", SenderID: "alice", CreatedAt: at(12, 0)},
+	}
+
+	got := AssignLegacyMessages(messages, "seller")
+	want := map[string]string{
+		"1": "alice", // 購入希望者より前の出品者のメッセージは最初の購入希望者へ
+		"2": "alice",
+		"3": "alice",
+		"4": "bob",
+		"5": "bob", // 並び順は作成日時で決まる
+		"6": "alice",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AssignLegacyMessages() = %v, want %v", got, want)
+	}
+	for id, buyer := range want {
+		if got[id] != buyer {
+			t.Errorf("message %s assigned to %q, want %q", id, got[id], buyer)
+		}
+	}
+
+	if got := AssignLegacyMessages([]*Message{{ID: "1", SenderID: "seller"}}, "seller"); len(got) != 0 {
+		t.Errorf("seller-only chat assigned %v, want none", got)
+	}
+
+	// 出品者を記録する前の商品は、どのメッセージも送信者自身のやりとりに残す
+	got = AssignLegacyMessages(messages, "")
+	if len(got) != len(messages) {
+		t.Fatalf("AssignLegacyMessages(seller unknown) = %v, want every message assigned", got)
+	}
+	for _, m := range messages {
+		if got[m.ID] != m.SenderID {
+			t.Errorf("seller unknown: message %s assigned to %q, want sender %q", m.ID, got[m.ID], m.SenderID)
+		}
+	}
+}
+
+// TestValidateMessage はメッセージの本文と添付の数のチェックを確認する
+func TestValidateMessage(t *testing.T) {
+	long := make([]rune, MaxMessageLength+1)
+	for i := range long {
+		long[i] = 'あ'
+	}
+	testCases := []struct {
+		name        string
+		content     string
+		attachments int
+		wantErr     bool
+	}{
+		{name: "普通のメッセージ", content: "まだ購入できますか？", attachments: 0},
+		{name: "空", content: "", attachments: 0, wantErr: true},
+		{name: "空白だけ", content: "  \n ", attachments: 0, wantErr: true},
+		{name: "ちょうど上限", content: string(long[:MaxMessageLength]), attachments: 0},
+		{name: "長すぎる", content: string(long), attachments: 0, wantErr: true},
+		{name: "画像だけ", content: "", attachments: 1},
+		{name: "添付が多すぎる", content: "写真です", attachments: MaxAttachments + 1, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateMessage(tc.content, tc.attachments); (err != nil) != tc.wantErr {
+				t.Errorf("ValidateMessage() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestConversationUnread は既読位置から未読の数を数えることを確認する
+func TestConversationUnread(t *testing.T) {
+	c := &Conversation{BuyerID: "buyer", SellerID: "seller", MessageSeq: 5, BuyerReadSeq: 5, SellerReadSeq: 2}
+
+	testCases := []struct {
+		name   string
+		userID string
+		want   int
+	}{
+		{name: "購入希望者はすべて既読", userID: "buyer", want: 0},
+		{name: "出品者は既読位置より後が未読", userID: "seller", want: 3},
+		{name: "当事者以外は0", userID: "other", want: 0},
+		{name: "未ログインは0", userID: "", want: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := c.UnreadFor(tc.userID); got != tc.want {
+				t.Errorf("UnreadFor(%q) = %d, want %d", tc.userID, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSetReadStatus は自分が送ったメッセージに相手が読んだかを付けることを確認する
+func TestSetReadStatus(t *testing.T) {
+	c := &Conversation{BuyerID: "buyer", SellerID: "seller", MessageSeq: 4, BuyerReadSeq: 4, SellerReadSeq: 2}
+	messages := []*Message{
+		{ID: "1", SenderID: "buyer", Seq: 1},
+		{ID: "2", SenderID: "seller", Seq: 2},
+		{ID: "3", SenderID: "buyer", Seq: 3},
+		{ID: "4", SenderID: "seller", Seq: 4},
+		{ID: "legacy", SenderID: "buyer"},
+	}
+	c.SetReadStatus(messages)
+
+	want := map[string]bool{"1": true, "2": true, "3": false, "4": true, "legacy": false}
+	for _, m := range messages {
+		if m.Read != want[m.ID] {
+			t.Errorf("message %s read = %v, want %v", m.ID, m.Read, want[m.ID])
+		}
+	}
+}
+
+// TestMessageCheckModifiable は編集・送信取り消しができる期限と状態を確認する
+func TestMessageCheckModifiable(t *testing.T) {
+	sent := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := 15 * time.Minute
+
+	testCases := []struct {
+		name    string
+		msg     *Message
+		now     time.Time
+		wantErr error
+	}{
+		{name: "送信直後", msg: &Message{CreatedAt: sent}, now: sent.Add(time.Minute)},
+		{name: "ちょうど期限", msg: &Message{CreatedAt: sent}, now: sent.Add(window)},
+		{name: "期限切れ", msg: &Message{CreatedAt: sent}, now: sent.Add(window + time.Second), wantErr: ErrEditWindowClosed},
+		{name: "取り消し済み", msg: &Message{CreatedAt: sent, Unsent: true}, now: sent.Add(time.Minute), wantErr: ErrMessageUnsent},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.msg.CheckModifiable(tc.now, window); err != tc.wantErr {
+				t.Errorf("CheckModifiable() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestMessageTombstone は送信取り消ししたメッセージから本文と添付を消すことを確認する
+func TestMessageTombstone(t *testing.T) {
+	edited := time.Date(2026, 1, 1, 12, 5, 0, 0, time.UTC)
+	m := &Message{ID: "1", SenderID: "buyer", Content: "まだありますか", Attachments: []*Attachment{{ID: "a"}}, EditedAt: &edited}
+	m.Tombstone()
+
+	if !m.Unsent || m.Content != "" || m.Attachments != nil || m.EditedAt != nil {
+		t.Errorf("Tombstone() = %+v, want unsent message without content", m)
+	}
+	if m.ID != "1" || m.SenderID != "buyer" {
+		t.Errorf("Tombstone() changed id or sender: %+v", m)
+	}
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DiscountType: クーポンの割引方法
+type DiscountType string
+
+const (
+	DiscountFixed   DiscountType = "fixed"   // 定額（円）
+	DiscountPercent DiscountType = "percent" // 定率（%）
+)
+
+// MinChargeAmount: 割引後も決済できる最低金額（カード決済の円の下限）
+const MinChargeAmount = 50
+
+var (
+	ErrInvalidCoupon           = errors.New("invalid coupon definition")
+	ErrCouponNotActive         = errors.New("coupon is not active")
+	ErrCouponNotApplicable     = errors.New("coupon cannot be applied to this item")
+	ErrCouponFirstPurchaseOnly = errors.New("coupon is only valid for your first purchase")
+	ErrCouponExhausted         = errors.New("coupon has reached its usage limit")
+	ErrCouponUserLimit         = errors.New("you have already used this coupon the maximum number of times")
+)
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,64}$`)
+
+// Coupon: キャンペーンのクーポン（割引額はプラットフォームが負担し、出品者の売上は減らない）
+type Coupon struct {
+	Code         string       `json:"code"`
+	Description  string       `json:"description"`
+	DiscountType DiscountType `json:"discount_type"`
+	// Amount: 定額なら円、定率なら % (1〜100)
+	Amount int `json:"amount"`
+	// MaxDiscount: 定率割引の上限（0 なら上限なし）
+	MaxDiscount int `json:"max_discount"`
+	// MinPrice: 使える最低の商品価格（0 なら制限なし）
+	MinPrice int `json:"min_price"`
+	// Categories: 使えるカテゴリ（子孫カテゴリを含む。空ならすべて）
+	Categories        []string   `json:"categories"`
+	FirstPurchaseOnly bool       `json:"first_purchase_only"`
+	StartsAt          *time.Time `json:"starts_at,omitempty"`
+	EndsAt            *time.Time `json:"ends_at,omitempty"`
+	// MaxRedemptions: 全体での利用上限（0 なら無制限）
+	MaxRedemptions int `json:"max_redemptions"`
+	// MaxPerUser: 1人あたりの利用上限（0 なら無制限）
+	MaxPerUser    int       `json:"max_per_user"`
+	RedeemedCount int       `json:"redeemed_count"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NormalizeCouponCode: 入力されたクーポンコードを大文字・前後の空白なしに揃える
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate: クーポンの定義が正しいか
+func (c *Coupon) Validate() error {
+	switch {
+	case !couponCodePattern.MatchString(c.Code):
+		return fmt.Errorf("%w: code must be 3-64 characters of A-Z, 0-9, _ or -", ErrInvalidCoupon)
+	case c.DiscountType == DiscountFixed && c.Amount <= 0:
+		return fmt.Errorf("%w: fixed discount must be positive", ErrInvalidCoupon)
+	case c.DiscountType == DiscountPercent && (c.Amount <= 0 || c.Amount > 100):
+		return fmt.Errorf("%w: percent discount must be between 1 and 100", ErrInvalidCoupon)
+	case c.DiscountType != DiscountFixed && c.DiscountType != DiscountPercent:
+		return fmt.Errorf("%w: discount_type must be fixed or percent", ErrInvalidCoupon)
+	case c.MaxDiscount < 0 || c.MinPrice < 0 || c.MaxRedemptions < 0 || c.MaxPerUser < 0:
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidCoupon)
+	case c.StartsAt != nil && c.EndsAt != nil && !c.EndsAt.After(*c.StartsAt):
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidCoupon)
+	}
+	return nil
+}
+
+// Discount: 価格 price の商品に使ったときの割引額（1円未満切り捨て）
+// 支払額が MinChargeAmount を下回らないように割引を抑え、割引できなければ ErrCouponNotApplicable
+//
+// categoryPath は商品のカテゴリと、その祖先カテゴリの slug
+func (c *Coupon) Discount(price int, categoryPath []string, now time.Time) (int, error) {
+	if !c.Active || (c.StartsAt != nil && now.Before(*c.StartsAt)) || (c.EndsAt != nil && !now.Before(*c.EndsAt)) {
+		return 0, ErrCouponNotActive
+	}
+	if price < c.MinPrice || !c.coversCategory(categoryPath) {
+		return 0, ErrCouponNotApplicable
+	}
+
+	discount := c.Amount
+	if c.DiscountType == DiscountPercent {
+		discount = price * c.Amount / 100
+		if c.MaxDiscount > 0 && discount > c.MaxDiscount {
+			discount = c.MaxDiscount
+		}
+	}
+	if discount > price-MinChargeAmount {
+		discount = price - MinChargeAmount
+	}
+	if discount <= 0 {
+		return 0, ErrCouponNotApplicable
+	}
+	return discount, nil
+}
+
+func (c *Coupon) coversCategory(categoryPath []string) bool {
+	if len(c.Categories) == 0 {
+		return true
+	}
+	for _, allowed := range c.Categories {
+		for _, slug := range categoryPath {
+			if allowed == slug {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CouponRedemption: クーポンの利用記録（注文がキャンセルされたら取り消す）
+type CouponRedemption struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	UserID    string    `json:"user_id"`
+	OrderID   string    `json:"order_id"`
+	Discount  int       `json:"discount"`
+	CreatedAt time.Time `json:"created_at"`
+}
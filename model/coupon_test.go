@@ -0,0 +1,72 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestCouponDiscount はクーポンの割引額と、使えない条件の判定を確認する
+func TestCouponDiscount(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	testCases := []struct {
+		name    string
+		coupon  Coupon
+		price   int
+		path    []string
+		want    int
+		wantErr error
+	}{
+		{name: "定額", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, Active: true}, price: 3000, want: 500},
+		{name: "定率は切り捨て", coupon: Coupon{DiscountType: DiscountPercent, Amount: 10, Active: true}, price: 2999, want: 299},
+		{name: "定率の上限", coupon: Coupon{DiscountType: DiscountPercent, Amount: 50, MaxDiscount: 1000, Active: true}, price: 10000, want: 1000},
+		{name: "支払額は最低金額を残す", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, Active: true}, price: 300, want: 300 - MinChargeAmount},
+		{name: "最低金額以下の商品には使えない", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, Active: true}, price: MinChargeAmount, want: 0, wantErr: ErrCouponNotApplicable},
+		{name: "最低価格に届かない", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, MinPrice: 3000, Active: true}, price: 2000, want: 0, wantErr: ErrCouponNotApplicable},
+		{name: "親カテゴリ指定で子カテゴリの商品に使える", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, Categories: []string{"tools"}, Active: true}, price: 3000, path: []string{"tools", "repair-parts"}, want: 500},
+		{name: "対象外のカテゴリ", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, Categories: []string{"tools"}, Active: true}, price: 3000, path: []string{"fashion", "shoes"}, want: 0, wantErr: ErrCouponNotApplicable},
+		{name: "停止中", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500}, price: 3000, want: 0, wantErr: ErrCouponNotActive},
+		{name: "開始前", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, StartsAt: &future, Active: true}, price: 3000, want: 0, wantErr: ErrCouponNotActive},
+		{name: "期限切れ", coupon: Coupon{DiscountType: DiscountFixed, Amount: 500, EndsAt: &past, Active: true}, price: 3000, want: 0, wantErr: ErrCouponNotActive},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.coupon.Discount(tc.price, tc.path, now)
+			if !errors.Is(err, tc.wantErr) || got != tc.want {
+				t.Errorf("Discount() = %d, %v, want %d, %v", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestCouponValidate は管理者が登録するクーポンの入力チェックを確認する
+func TestCouponValidate(t *testing.T) {
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(-time.Hour)
+
+	testCases := []struct {
+		name    string
+		coupon  Coupon
+		wantErr bool
+	}{
+		{name: "定額", coupon: Coupon{Code: "REPAIR500", DiscountType: DiscountFixed, Amount: 500}},
+		{name: "定率", coupon: Coupon{Code: "FIRST10", DiscountType: DiscountPercent, Amount: 10}},
+		{name: "小文字のコード", coupon: Coupon{Code: "first10", DiscountType: DiscountPercent, Amount: 10}, wantErr: true},
+		{name: "100% を超える", coupon: Coupon{Code: "FIRST10", DiscountType: DiscountPercent, Amount: 101}, wantErr: true},
+		{name: "割引方法がない", coupon: Coupon{Code: "FIRST10", Amount: 10}, wantErr: true},
+		{name: "終了が開始より前", coupon: Coupon{Code: "FIRST10", DiscountType: DiscountPercent, Amount: 10, StartsAt: &start, EndsAt: &end}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.coupon.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidCoupon) {
+				t.Errorf("Validate() error = %v, want ErrInvalidCoupon", err)
+			}
+		})
+	}
+}
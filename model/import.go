@@ -0,0 +1,215 @@
+package model
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ImportFormat: 一括出品のファイル形式
+type ImportFormat string
+
+const (
+	ImportCSV   ImportFormat = "csv"
+	ImportJSONL ImportFormat = "jsonl"
+)
+
+// ImportMaxPrice: 一括出品で受け付ける価格の上限
+const ImportMaxPrice = 9999999
+
+// ImportRowStatus: 1行ごとの取り込み結果
+type ImportRowStatus string
+
+const (
+	ImportCreated     ImportRowStatus = "created"
+	ImportWouldCreate ImportRowStatus = "would_create" // dry-run で登録できると判定
+	ImportSkipped     ImportRowStatus = "skipped"      // 同じ SKU が登録済み
+	ImportInvalid     ImportRowStatus = "invalid"
+	ImportFailed      ImportRowStatus = "failed" // DB エラーなど
+)
+
+var ErrUnknownImportFormat = errors.New("format must be csv or jsonl")
+
+// ImportRow: ファイルの1行（Line は CSV ならヘッダーを1行目とした行番号）
+type ImportRow struct {
+	Line        int    `json:"line"`
+	SKU         string `json:"sku"`
+	Name        string `json:"name"`
+	Price       int    `json:"price"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	// ParseError: 行を読めなかった理由（読めた場合は空）
+	ParseError string `json:"-"`
+}
+
+// ImportRowResult: 1行ごとの結果
+type ImportRowResult struct {
+	Line   int             `json:"line"`
+	SKU    string          `json:"sku,omitempty"`
+	Status ImportRowStatus `json:"status"`
+	ItemID string          `json:"item_id,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// ImportReport: 一括出品の結果
+type ImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"` // invalid と failed の合計
+	Rows    []*ImportRowResult `json:"rows"`
+}
+
+// Add: 行の結果を追加して件数を数える
+func (r *ImportReport) Add(res *ImportRowResult) {
+	r.Rows = append(r.Rows, res)
+	r.Total++
+	switch res.Status {
+	case ImportCreated, ImportWouldCreate:
+		r.Created++
+	case ImportSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+}
+
+// Validate: 1行の内容を検証する
+func (row *ImportRow) Validate() error {
+	if row.ParseError != "" {
+		return errors.New(row.ParseError)
+	}
+	if strings.TrimSpace(row.Name) == "" {
+		return errors.New("name is required")
+	}
+	if len([]rune(row.Name)) > 255 {
+		return errors.New("name is too long")
+	}
+	if row.Price <= 0 || row.Price > ImportMaxPrice {
+		return fmt.Errorf("price must be between 1 and %d", ImportMaxPrice)
+	}
+	if len(row.SKU) > 255 {
+		return errors.New("sku is too long")
+	}
+	if row.ImageURL != "" && !strings.HasPrefix(row.ImageURL, "https://") && !strings.HasPrefix(row.ImageURL, "http://") {
+		return errors.New("image_url must be an http(s) URL")
+	}
+	return nil
+}
+
+// ParseImport: CSV（ヘッダー行あり）または JSONL を行ごとに読む
+// 読めない行は ParseError を付けて返し、他の行の取り込みは続ける
+func ParseImport(r io.Reader, format ImportFormat, maxRows int) ([]*ImportRow, error) {
+	switch format {
+	case ImportCSV:
+		return parseImportCSV(r, maxRows)
+	case ImportJSONL:
+		return parseImportJSONL(r, maxRows)
+	}
+	return nil, ErrUnknownImportFormat
+}
+
+// importColumns: CSV のヘッダー名（別名を含む）と項目の対応
+var importColumns = map[string]string{
+	"sku": "sku", "external_sku": "sku",
+	"name": "name", "title": "name",
+	"price":       "price",
+	"description": "description",
+	"image_url":   "image_url", "image": "image_url",
+}
+
+func parseImportCSV(r io.Reader, maxRows int) ([]*ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))) // Excel の BOM
+		if field, ok := importColumns[h]; ok {
+			cols[field] = i
+		}
+	}
+	if _, ok := cols["name"]; !ok {
+		return nil, errors.New("csv header must include name")
+	}
+	if _, ok := cols["price"]; !ok {
+		return nil, errors.New("csv header must include price")
+	}
+
+	var rows []*ImportRow
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) >= maxRows {
+			return nil, fmt.Errorf("too many rows (max %d)", maxRows)
+		}
+		row := &ImportRow{Line: line}
+		if err != nil {
+			row.ParseError = err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		get := func(field string) string {
+			if i, ok := cols[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row.SKU, row.Name, row.Description, row.ImageURL = get("sku"), get("name"), get("description"), get("image_url")
+		if row.Price, err = strconv.Atoi(strings.ReplaceAll(get("price"), ",", "")); err != nil {
+			row.ParseError = "price must be an integer"
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseImportJSONL(r io.Reader, maxRows int) ([]*ImportRow, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []*ImportRow
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) >= maxRows {
+			return nil, fmt.Errorf("too many rows (max %d)", maxRows)
+		}
+		var v struct {
+			SKU         string      `json:"sku"`
+			Name        string      `json:"name"`
+			Price       json.Number `json:"price"`
+			Description string      `json:"description"`
+			ImageURL    string      `json:"image_url"`
+		}
+		row := &ImportRow{Line: line}
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			row.ParseError = "invalid JSON: " + err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		row.SKU, row.Name, row.Description, row.ImageURL = strings.TrimSpace(v.SKU), strings.TrimSpace(v.Name), v.Description, strings.TrimSpace(v.ImageURL)
+		price, err := strconv.Atoi(v.Price.String())
+		if err != nil {
+			row.ParseError = "price must be an integer"
+		}
+		row.Price = price
+		rows = append(rows, row)
+	}
+	return rows, sc.Err()
+}
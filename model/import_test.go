@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseImport は一括出品ファイルの読み込みと検証のテスト
+func TestParseImport(t *testing.T) {
+	csvText := "\ufeffSKU,Name,Price,Description,Image\n" +
+		"A-1,ジャンク カメラ,\"3,000\",動作未確認,https://example.com/a.jpg\n" +
+		"A-2,,1000,,\n" +
+		"A-3,レンズ,abc,,\n" +
+		"A-4,三脚,500,,ftp://example.com/x.jpg\n"
+	jsonlText := `{"sku":"B-1","name":"ラジオ","price":1200}` + "\n\n" +
+		`{"sku":"B-2","name":"時計","price":12.5}` + "\n" +
+		`{broken` + "\n"
+
+	testCases := []struct {
+		name      string
+		format    ImportFormat
+		text      string
+		wantLines []int
+		wantValid []bool
+	}{
+		{name: "CSV", format: ImportCSV, text: csvText, wantLines: []int{2, 3, 4, 5}, wantValid: []bool{true, false, false, false}},
+		{name: "JSONL（空行は飛ばす）", format: ImportJSONL, text: jsonlText, wantLines: []int{1, 3, 4}, wantValid: []bool{true, false, false}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := ParseImport(strings.NewReader(tc.text), tc.format, 100)
+			if err != nil {
+				t.Fatalf("ParseImport() error = %v", err)
+			}
+			if len(rows) != len(tc.wantLines) {
+				t.Fatalf("ParseImport() returned %d rows, want %d", len(rows), len(tc.wantLines))
+			}
+			for i, row := range rows {
+				if row.Line != tc.wantLines[i] {
+					t.Errorf("rows[%d].Line = %d, want %d", i, row.Line, tc.wantLines[i])
+				}
+				if err := row.Validate(); (err == nil) != tc.wantValid[i] {
+					t.Errorf("rows[%d].Validate() = %v, want valid %v", i, err, tc.wantValid[i])
+				}
+			}
+		})
+	}
+
+	if rows, _ := ParseImport(strings.NewReader(csvText), ImportCSV, 100); rows[0].Price != 3000 {
+		t.Errorf("カンマ区切りの価格 = %d, want 3000", rows[0].Price)
+	}
+	if _, err := ParseImport(strings.NewReader(csvText), ImportCSV, 2); err == nil {
+		t.Error("行数の上限を超えてもエラーにならない")
+	}
+}
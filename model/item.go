@@ -1,5 +1,24 @@
 package model
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrListingIncomplete: 公開に必要な項目が足りない
+var ErrListingIncomplete = errors.New("listing is incomplete")
+
+// ItemStatus: 出品の状態
+type ItemStatus string
+
+const (
+	ItemDraft     ItemStatus = "draft"     // 下書き（予約公開を含む）
+	ItemPublished ItemStatus = "published" // 公開中
+	ItemWithdrawn ItemStatus = "withdrawn" // 出品取り下げ
+	ItemSold      ItemStatus = "sold"      // 売り切れ
+)
+
 type Item struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
@@ -9,4 +28,60 @@ type Item struct {
 	ImageURL    string `json:"image_url"`
 	// ★追加: いいねの数を格納するフィールド
 	LikeCount int `json:"like_count"`
+	// 出品者のユーザーID
+	SellerID string `json:"seller_id"`
+	// 値下げ交渉で合意した購入者のための確保（期限切れなら無効）
+	ReservedFor   string     `json:"reserved_for,omitempty"`
+	ReservedPrice int        `json:"reserved_price,omitempty"`
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
+	// 通報により非表示
+	Hidden bool `json:"hidden,omitempty"`
+	// 出品の状態と公開日時（PublishAt は予約公開の日時）
+	Status      ItemStatus `json:"status"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	// カテゴリとタグ（似ている商品の判定に使う）
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	// 一括出品で指定された出品者側の商品コード
+	ExternalSKU string `json:"external_sku,omitempty"`
+	// 出品者の評価（公開済みのもののみ）
+	SellerRating      float64 `json:"seller_rating"`
+	SellerReviewCount int     `json:"seller_review_count"`
+}
+
+// ReservedAt: now の時点で誰かのために確保されているか
+func (i *Item) ReservedAt(now time.Time) bool {
+	return i.ReservedFor != "" && i.ReservedUntil != nil && now.Before(*i.ReservedUntil)
+}
+
+// ListedAt: 新着順の並びに使う日時（公開日時、なければ登録日時。どちらもない古い商品は 1970-01-01）
+func (i *Item) ListedAt() time.Time {
+	switch {
+	case i.PublishedAt != nil:
+		return *i.PublishedAt
+	case i.CreatedAt != nil:
+		return *i.CreatedAt
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// ValidateForPublish: 公開に必要な項目がそろっているか（下書きは未入力でもよい）
+func (i *Item) ValidateForPublish() error {
+	if i.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrListingIncomplete)
+	}
+	if i.Price <= 0 {
+		return fmt.Errorf("%w: price must be positive", ErrListingIncomplete)
+	}
+	return nil
+}
+
+// PriceChange: 商品の価格変更の履歴
+type PriceChange struct {
+	ItemID    string    `json:"item_id"`
+	OldPrice  int       `json:"old_price"`
+	NewPrice  int       `json:"new_price"`
+	ChangedAt time.Time `json:"changed_at"`
 }
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor: ページ送りのカーソルが読めない
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ItemCursor: 新しい順の商品一覧で、最後に返した商品の位置（公開日時と ID）
+// 同じ公開日時の商品があっても ID で順序が決まるので、取りこぼしや重複が起きない
+type ItemCursor struct {
+	At time.Time
+	ID string
+}
+
+// Encode: クライアントにそのまま返せる文字列にする
+func (c ItemCursor) Encode() string {
+	raw := c.At.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseItemCursor: Encode した文字列を読む（空文字なら先頭から）
+func ParseItemCursor(s string) (*ItemCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &ItemCursor{At: t, ID: id}, nil
+}
+
+// ItemPage: ページ送りする商品一覧（NextCursor が空なら最後のページ）
+type ItemPage struct {
+	Items      []*Item `json:"items"`
+	NextCursor string  `json:"next_cursor"`
+}
+
+// NewItemPage: ListedAt の新しい順に limit+1 件取得した結果から1ページ分を作る
+func NewItemPage(items []*Item, limit int) *ItemPage {
+	page := &ItemPage{Items: items}
+	if page.Items == nil {
+		page.Items = []*Item{}
+	}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = ItemCursor{At: last.ListedAt(), ID: last.ID}.Encode()
+	}
+	return page
+}
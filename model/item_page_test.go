@@ -0,0 +1,76 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+// TestItemCursor は商品一覧のカーソルが往復でき、不正な文字列を拒否することを確認する
+func TestItemCursor(t *testing.T) {
+	at := time.Date(2026, 4, 1, 12, 30, 0, 123456000, time.FixedZone("JST", 9*60*60))
+	c := ItemCursor{At: at, ID: "01HXYZ"}
+
+	got, err := ParseItemCursor(c.Encode())
+	if err != nil {
+		t.Fatalf("ParseItemCursor() error = %v", err)
+	}
+	if !got.At.Equal(at) || got.ID != c.ID {
+		t.Errorf("ParseItemCursor() = %+v, want %+v", got, c)
+	}
+
+	if got, err := ParseItemCursor(""); got != nil || err != nil {
+		t.Errorf("ParseItemCursor(\"\") = %v, %v, want nil, nil", got, err)
+	}
+
+	testCases := []struct {
+		name string
+		s    string
+	}{
+		{name: "base64 でない", s: "!!!"},
+		{name: "区切りがない", s: "MjAyNi0wNC0wMVQxMjozMDowMFo"},
+		{name: "日時が読めない", s: "bm90LWEtdGltZXxpZA"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseItemCursor(tc.s); err != ErrInvalidCursor {
+				t.Errorf("ParseItemCursor(%q) error = %v, want ErrInvalidCursor", tc.s, err)
+			}
+		})
+	}
+}
+
+// TestNewItemPage は1件多く読んだ結果から、ページと次のカーソルを作ることを確認する
+func TestNewItemPage(t *testing.T) {
+	base := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	var items []*Item
+	for i := 0; i < 3; i++ {
+		at := base.Add(-time.Duration(i) * time.Hour)
+		items = append(items, &Item{ID: string(rune('a' + i)), PublishedAt: &at})
+	}
+
+	testCases := []struct {
+		name     string
+		items    []*Item
+		limit    int
+		wantLen  int
+		wantNext bool
+	}{
+		{name: "次のページがある", items: items, limit: 2, wantLen: 2, wantNext: true},
+		{name: "ちょうど最後のページ", items: items, limit: 3, wantLen: 3},
+		{name: "空", limit: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page := NewItemPage(tc.items, tc.limit)
+			if len(page.Items) != tc.wantLen || (page.NextCursor != "") != tc.wantNext {
+				t.Fatalf("NewItemPage() = %d items, next %q", len(page.Items), page.NextCursor)
+			}
+			if tc.wantNext {
+				c, err := ParseItemCursor(page.NextCursor)
+				if err != nil || c.ID != "b" || !c.At.Equal(*items[1].PublishedAt) {
+					t.Errorf("NextCursor = %+v, %v, want item b", c, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package model
+
+import "time"
+
+// 勘定科目
+const (
+	AccountExternal   = "external"            // 外部（購入者のカード・出品者の銀行口座）
+	AccountEscrow     = "platform:escrow"     // 購入代金の預かり
+	AccountFees       = "platform:fees"       // 販売手数料収入
+	AccountPayouts    = "platform:payouts"    // 振込申請済み・未送金
+	AccountPromotions = "platform:promotions" // クーポン割引の負担（マイナスが販促費）
+)
+
+// SellerAccount: 出品者ごとの売上残高の勘定
+func SellerAccount(userID string) string {
+	return "seller:" + userID
+}
+
+// LedgerEntryType: 仕訳の種類
+type LedgerEntryType string
+
+const (
+	EntryPayment     LedgerEntryType = "payment"      // 購入代金の受領（預かり）
+	EntrySale        LedgerEntryType = "sale"         // 出品者への売上計上
+	EntryPlatformFee LedgerEntryType = "platform_fee" // 販売手数料
+	EntryRefund      LedgerEntryType = "refund"       // 購入者への返金
+	EntryPayout      LedgerEntryType = "payout"       // 出品者への振込
+	EntryPayoutPaid  LedgerEntryType = "payout_paid"  // 振込の送金完了
+	EntryPromotion   LedgerEntryType = "promotion"    // クーポン割引の補填
+)
+
+// LedgerEntry: 複式簿記の1行（同じ TxnID の行の合計は必ず 0）
+type LedgerEntry struct {
+	ID        string          `json:"id"`
+	TxnID     string          `json:"txn_id"`
+	Account   string          `json:"account"`
+	Amount    int             `json:"amount"` // 正: 勘定残高の増加 / 負: 減少
+	EntryType LedgerEntryType `json:"entry_type"`
+	OrderID   string          `json:"order_id,omitempty"`
+	PayoutID  string          `json:"payout_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PayoutStatus: 振込申請の状態
+type PayoutStatus string
+
+const (
+	PayoutRequested PayoutStatus = "requested"
+	PayoutPaid      PayoutStatus = "paid"
+)
+
+type PayoutRequest struct {
+	ID        string       `json:"id"`
+	SellerID  string       `json:"seller_id"`
+	Amount    int          `json:"amount"`
+	Status    PayoutStatus `json:"status"`
+	CreatedAt time.Time    `json:"created_at"`
+	PaidAt    *time.Time   `json:"paid_at,omitempty"`
+}
+
+// PlatformFee: 販売価格に手数料率 (ベーシスポイント, 1000 = 10%) を掛けた手数料（1円未満切り捨て）
+func PlatformFee(price, rateBps int) int {
+	if rateBps <= 0 || price <= 0 {
+		return 0
+	}
+	return price * rateBps / 10000
+}
+
+// transfer: from から to へ amount を移す仕訳の組を作る
+func transfer(txnID string, entryType LedgerEntryType, from, to string, amount int, at time.Time) []*LedgerEntry {
+	return []*LedgerEntry{
+		{ID: NewID(), TxnID: txnID, Account: from, Amount: -amount, EntryType: entryType, CreatedAt: at},
+		{ID: NewID(), TxnID: txnID, Account: to, Amount: amount, EntryType: entryType, CreatedAt: at},
+	}
+}
+
+func withOrder(entries []*LedgerEntry, orderID string) []*LedgerEntry {
+	for _, e := range entries {
+		e.OrderID = orderID
+	}
+	return entries
+}
+
+// PaymentEntries: 購入代金を預かったときの仕訳
+// クーポンの割引分はプラットフォームが補填し、預かり金は常に販売価格と一致させる
+func PaymentEntries(o *Order, at time.Time) []*LedgerEntry {
+	txnID := "payment:" + o.ID
+	entries := transfer(txnID, EntryPayment, AccountExternal, AccountEscrow, o.ChargedAmount(), at)
+	if o.Discount > 0 {
+		entries = append(entries, transfer(txnID, EntryPromotion, AccountPromotions, AccountEscrow, o.Discount, at)...)
+	}
+	return withOrder(entries, o.ID)
+}
+
+// SaleEntries: 預かり金を出品者に解放し、手数料を差し引く仕訳
+func SaleEntries(o *Order, feeRateBps int, at time.Time) []*LedgerEntry {
+	txnID := "sale:" + o.ID
+	entries := transfer(txnID, EntrySale, AccountEscrow, SellerAccount(o.SellerID), o.Price, at)
+	if fee := PlatformFee(o.Price, feeRateBps); fee > 0 {
+		entries = append(entries, transfer(txnID, EntryPlatformFee, SellerAccount(o.SellerID), AccountFees, fee, at)...)
+	}
+	return withOrder(entries, o.ID)
+}
+
+// RefundEntries: 預かり金を購入者に返金する仕訳
+// 購入者には支払額だけを返し、割引の補填分はプラットフォームに戻す
+func RefundEntries(o *Order, at time.Time) []*LedgerEntry {
+	txnID := "refund:" + o.ID
+	entries := transfer(txnID, EntryRefund, AccountEscrow, AccountExternal, o.ChargedAmount(), at)
+	if o.Discount > 0 {
+		entries = append(entries, transfer(txnID, EntryPromotion, AccountEscrow, AccountPromotions, o.Discount, at)...)
+	}
+	return withOrder(entries, o.ID)
+}
+
+// PayoutEntries: 売上残高から振込申請分を差し引く仕訳
+func PayoutEntries(p *PayoutRequest) []*LedgerEntry {
+	entries := transfer("payout:"+p.ID, EntryPayout, SellerAccount(p.SellerID), AccountPayouts, p.Amount, p.CreatedAt)
+	for _, e := range entries {
+		e.PayoutID = p.ID
+	}
+	return entries
+}
+
+// PayoutPaidEntries: 振込申請分を実際に送金したときの仕訳（未送金の勘定から外部へ）
+func PayoutPaidEntries(p *PayoutRequest, at time.Time) []*LedgerEntry {
+	entries := transfer("payout_paid:"+p.ID, EntryPayoutPaid, AccountPayouts, AccountExternal, p.Amount, at)
+	for _, e := range entries {
+		e.PayoutID = p.ID
+	}
+	return entries
+}
+
+// LedgerReport: 管理者向けの照合レポート
+type LedgerReport struct {
+	// Total: 全仕訳の合計（複式簿記なので常に 0 のはず）
+	Total int `json:"total"`
+	// UnbalancedTxns: 合計が 0 にならない取引
+	UnbalancedTxns map[string]int `json:"unbalanced_txns"`
+	// Accounts: プラットフォーム側の勘定残高と出品者残高の合計
+	Accounts map[string]int `json:"accounts"`
+	// EscrowBalance と HeldOrdersTotal（預かり中の注文の合計）は一致するはず
+	EscrowBalance   int  `json:"escrow_balance"`
+	HeldOrdersTotal int  `json:"held_orders_total"`
+	OK              bool `json:"ok"`
+}
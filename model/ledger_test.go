@@ -0,0 +1,84 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPlatformFee は手数料計算のテスト
+func TestPlatformFee(t *testing.T) {
+	testCases := []struct {
+		name    string
+		price   int
+		rateBps int
+		want    int
+	}{
+		{name: "10%", price: 3000, rateBps: 1000, want: 300},
+		{name: "端数は切り捨て", price: 999, rateBps: 1000, want: 99},
+		{name: "手数料なし", price: 3000, rateBps: 0, want: 0},
+		{name: "0円", price: 0, rateBps: 1000, want: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := PlatformFee(tc.price, tc.rateBps); got != tc.want {
+				t.Errorf("PlatformFee(%d, %d) = %d, want %d", tc.price, tc.rateBps, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestLedgerEntries_Balanced は各仕訳の合計が 0 になることを確認する
+func TestLedgerEntries_Balanced(t *testing.T) {
+	now := time.Now()
+	o := &Order{ID: "o1", SellerID: "s1", Price: 4999}
+	p := &PayoutRequest{ID: "p1", SellerID: "s1", Amount: 1000, CreatedAt: now}
+
+	sets := map[string][]*LedgerEntry{
+		"payment":     PaymentEntries(o, now),
+		"sale":        SaleEntries(o, 1000, now),
+		"refund":      RefundEntries(o, now),
+		"payout":      PayoutEntries(p),
+		"payout_paid": PayoutPaidEntries(p, now),
+	}
+	for name, entries := range sets {
+		sum := 0
+		for _, e := range entries {
+			sum += e.Amount
+			if e.TxnID != entries[0].TxnID {
+				t.Errorf("%s: mixed txn ids %s / %s", name, e.TxnID, entries[0].TxnID)
+			}
+		}
+		if sum != 0 {
+			t.Errorf("%s: entries sum to %d, want 0", name, sum)
+		}
+	}
+
+	// クーポンの割引があっても仕訳は釣り合い、預かり金は販売価格と一致する
+	d := &Order{ID: "o2", SellerID: "s1", Price: 4999, Discount: 500}
+	for name, entries := range map[string][]*LedgerEntry{"payment": PaymentEntries(d, now), "refund": RefundEntries(d, now)} {
+		sum, escrow, external := 0, 0, 0
+		for _, e := range entries {
+			sum += e.Amount
+			switch e.Account {
+			case AccountEscrow:
+				escrow += e.Amount
+			case AccountExternal:
+				external += e.Amount
+			}
+		}
+		if sum != 0 || (escrow != 4999 && escrow != -4999) || (external != 4499 && external != -4499) {
+			t.Errorf("%s with discount: sum %d, escrow %d, external %d", name, sum, escrow, external)
+		}
+	}
+
+	// 出品者の手取り = 価格 - 手数料
+	seller := 0
+	for _, e := range SaleEntries(o, 1000, now) {
+		if e.Account == SellerAccount("s1") {
+			seller += e.Amount
+		}
+	}
+	if want := 4999 - 499; seller != want {
+		t.Errorf("seller net = %d, want %d", seller, want)
+	}
+}
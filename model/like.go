@@ -0,0 +1,7 @@
+package model
+
+// Like: ユーザーが商品にいいねした記録
+type Like struct {
+	UserID string `json:"user_id"`
+	ItemID string `json:"item_id"`
+}
@@ -1,9 +1,82 @@
 package model
 
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrEditWindowClosed: 送信から時間がたち、もう編集・送信取り消しができない
+	ErrEditWindowClosed = errors.New("message can no longer be edited or unsent")
+	// ErrMessageUnsent: 送信を取り消したメッセージは編集できない
+	ErrMessageUnsent = errors.New("message has been unsent")
+)
+
 type Message struct {
-	ID        string `json:"id"`
-	ItemID    string `json:"item_id"`
-	SenderID  string `json:"sender_id"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
+	ID             string `json:"id"`
+	ConversationID string `json:"conversation_id"`
+	ItemID         string `json:"item_id"`
+	SenderID       string `json:"sender_id"`
+	Content        string `json:"content"`
+	// CreatedAt: 送信日時（UTC・マイクロ秒まで。JSON では RFC 3339）
+	CreatedAt time.Time `json:"created_at"`
+	// Seq: やりとりの中での通し番号（既読の判定に使う）
+	Seq int `json:"seq"`
+	// Read: 相手が読んだかどうか（送信者から見た既読）
+	Read bool `json:"read"`
+	// Attachments: 添付した画像
+	Attachments []*Attachment `json:"attachments,omitempty"`
+	// EditedAt: 最後に編集した日時（編集していなければ nil。画面の「編集済み」表示に使う）
+	EditedAt *time.Time `json:"edited_at,omitempty"`
+	// Unsent: 送信を取り消したか（本文と添付は当事者にも返さない）
+	Unsent bool `json:"unsent"`
+	// Hidden: 通報で非表示にしたか（当事者には返さない）
+	Hidden bool `json:"-"`
+}
+
+// CheckModifiable: 送信から window 以内で、送信を取り消していなければ編集・送信取り消しができる
+// （送信者本人かどうかは呼び出し側で確認する）
+func (m *Message) CheckModifiable(now time.Time, window time.Duration) error {
+	if m.Unsent {
+		return ErrMessageUnsent
+	}
+	if now.Sub(m.CreatedAt) > window {
+		return ErrEditWindowClosed
+	}
+	return nil
+}
+
+// Tombstone: 送信を取り消した状態にする（本文と添付を消し、取り消した跡だけ残す）
+func (m *Message) Tombstone() {
+	m.Unsent = true
+	m.Content = ""
+	m.Attachments = nil
+	m.EditedAt = nil
+}
+
+// MessageRevisionAction: 本文の履歴を残した操作
+type MessageRevisionAction string
+
+const (
+	MessageRevisionEdit   MessageRevisionAction = "edit"   // 編集
+	MessageRevisionUnsend MessageRevisionAction = "unsend" // 送信取り消し
+)
+
+// MessageRevision: 編集・送信取り消しの前の本文（モデレーターが確認するための履歴。当事者には見せない）
+type MessageRevision struct {
+	ID        string                `json:"id"`
+	MessageID string                `json:"message_id"`
+	Action    MessageRevisionAction `json:"action"`
+	// Content: 操作する前の本文
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MessagePage: メッセージ履歴の1ページ（古い順）
+type MessagePage struct {
+	Messages []*Message `json:"messages"`
+	// Before: さらに古いメッセージを取るためのカーソル（これより古いものがなければ空）
+	Before string `json:"before,omitempty"`
+	// After: これより新しいメッセージを取るためのカーソル（ページの最後のメッセージ）
+	After string `json:"after,omitempty"`
 }
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// NotificationType: 通知の種類
+type NotificationType string
+
+const (
+	NotifySavedSearch NotificationType = "saved_search" // 保存した検索条件に合う新着
+	NotifyPriceDrop   NotificationType = "price_drop"   // いいねした商品の値下げ
+	NotifyItemSold    NotificationType = "item_sold"    // いいねした商品が売れた
+)
+
+// Notification: ユーザーへの通知
+// DedupKey が同じ通知は同じユーザーに二度作られない
+type Notification struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"user_id"`
+	Type      NotificationType `json:"type"`
+	ItemID    string           `json:"item_id,omitempty"`
+	SourceID  string           `json:"source_id,omitempty"` // 通知のきっかけ（保存した検索など）
+	Message   string           `json:"message"`
+	DedupKey  string           `json:"-"`
+	ReadAt    *time.Time       `json:"read_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
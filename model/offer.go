@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// OfferStatus: 値下げ交渉の状態
+type OfferStatus string
+
+const (
+	OfferPending   OfferStatus = "pending"   // 購入者の提示額に対して出品者の返答待ち
+	OfferCountered OfferStatus = "countered" // 出品者の逆提案に対して購入者の返答待ち
+	OfferAccepted  OfferStatus = "accepted"  // 合意済み（商品は購入者のために確保される）
+	OfferDeclined  OfferStatus = "declined"
+	OfferExpired   OfferStatus = "expired"
+)
+
+// OfferAction: 交渉に対する操作
+type OfferAction string
+
+const (
+	OfferActionCounter OfferAction = "counter"
+	OfferActionAccept  OfferAction = "accept"
+	OfferActionDecline OfferAction = "decline"
+)
+
+var (
+	ErrIllegalOfferAction = errors.New("illegal offer action")
+	ErrInvalidOfferAmount = errors.New("offer amount must be between 1 and the listed price")
+)
+
+type Offer struct {
+	ID        string      `json:"id"`
+	ItemID    string      `json:"item_id"`
+	BuyerID   string      `json:"buyer_id"`
+	SellerID  string      `json:"seller_id"`
+	Amount    int         `json:"amount"` // 現在テーブルに乗っている金額
+	Status    OfferStatus `json:"status"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// IsActive: まだ交渉中かどうか（購入者ごとに1件まで）
+func (s OfferStatus) IsActive() bool {
+	return s == OfferPending || s == OfferCountered
+}
+
+// NextOfferStatus: status の交渉に role が action を行った後の状態
+// 返答待ちの側だけが逆提案・承諾でき、辞退はどちらからでもできる
+func NextOfferStatus(status OfferStatus, action OfferAction, role OrderActor) (OfferStatus, error) {
+	var waitingFor OrderActor
+	switch status {
+	case OfferPending:
+		waitingFor = OrderActorSeller
+	case OfferCountered:
+		waitingFor = OrderActorBuyer
+	default:
+		return "", ErrIllegalOfferAction
+	}
+	if role != OrderActorBuyer && role != OrderActorSeller {
+		return "", ErrIllegalOfferAction
+	}
+
+	switch action {
+	case OfferActionDecline:
+		return OfferDeclined, nil
+	case OfferActionAccept:
+		if role == waitingFor {
+			return OfferAccepted, nil
+		}
+	case OfferActionCounter:
+		if role == waitingFor {
+			if role == OrderActorSeller {
+				return OfferCountered, nil
+			}
+			return OfferPending, nil
+		}
+	}
+	return "", ErrIllegalOfferAction
+}
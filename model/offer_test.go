@@ -0,0 +1,37 @@
+package model
+
+import "testing"
+
+// TestNextOfferStatus は値下げ交渉の状態遷移のテスト
+func TestNextOfferStatus(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  OfferStatus
+		action  OfferAction
+		role    OrderActor
+		want    OfferStatus
+		wantErr bool
+	}{
+		{name: "出品者が承諾", status: OfferPending, action: OfferActionAccept, role: OrderActorSeller, want: OfferAccepted},
+		{name: "出品者が逆提案", status: OfferPending, action: OfferActionCounter, role: OrderActorSeller, want: OfferCountered},
+		{name: "購入者が再提案", status: OfferCountered, action: OfferActionCounter, role: OrderActorBuyer, want: OfferPending},
+		{name: "購入者が逆提案を承諾", status: OfferCountered, action: OfferActionAccept, role: OrderActorBuyer, want: OfferAccepted},
+		{name: "購入者が取り下げ", status: OfferPending, action: OfferActionDecline, role: OrderActorBuyer, want: OfferDeclined},
+		{name: "購入者は自分の提示を承諾できない", status: OfferPending, action: OfferActionAccept, role: OrderActorBuyer, wantErr: true},
+		{name: "出品者は自分の逆提案を承諾できない", status: OfferCountered, action: OfferActionAccept, role: OrderActorSeller, wantErr: true},
+		{name: "合意後は操作できない", status: OfferAccepted, action: OfferActionDecline, role: OrderActorSeller, wantErr: true},
+		{name: "システムは操作できない", status: OfferPending, action: OfferActionAccept, role: OrderActorSystem, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NextOfferStatus(tc.status, tc.action, tc.role)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("NextOfferStatus() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("NextOfferStatus() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
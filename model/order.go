@@ -0,0 +1,137 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// OrderStatus: 取引の状態
+type OrderStatus string
+
+const (
+	OrderStatusPaid      OrderStatus = "paid"      // 支払い済み（発送待ち）
+	OrderStatusShipped   OrderStatus = "shipped"   // 発送済み
+	OrderStatusReceived  OrderStatus = "received"  // 受取済み
+	OrderStatusCompleted OrderStatus = "completed" // 取引完了
+	OrderStatusCancelled OrderStatus = "cancelled" // キャンセル
+)
+
+// OrderActor: 状態遷移を行う主体
+type OrderActor string
+
+const (
+	OrderActorBuyer  OrderActor = "buyer"
+	OrderActorSeller OrderActor = "seller"
+	OrderActorSystem OrderActor = "system"
+)
+
+// EscrowStatus: 購入代金の預かり状態
+type EscrowStatus string
+
+const (
+	EscrowAuthorized EscrowStatus = "authorized" // 与信確保のみ
+	EscrowHeld       EscrowStatus = "held"       // 売上確定済み・プラットフォームが預かり中
+	EscrowReleased   EscrowStatus = "released"   // 受取確認により出品者へ解放済み
+	EscrowRefunded   EscrowStatus = "refunded"   // 購入者へ返金済み
+	EscrowVoided     EscrowStatus = "voided"     // 与信取消
+)
+
+// SystemActorID: 自動処理による遷移の記録に使う ID
+const SystemActorID = "system"
+
+var (
+	ErrIllegalTransition   = errors.New("illegal order transition")
+	ErrTransitionForbidden = errors.New("actor is not allowed to perform this transition")
+)
+
+// orderTransitions: 許可された遷移と、それを実行できる主体
+var orderTransitions = map[OrderStatus]map[OrderStatus][]OrderActor{
+	OrderStatusPaid: {
+		OrderStatusShipped:   {OrderActorSeller},
+		OrderStatusCancelled: {OrderActorBuyer, OrderActorSeller, OrderActorSystem}, // system は決済失敗時
+	},
+	OrderStatusShipped: {
+		OrderStatusReceived:  {OrderActorBuyer},
+		OrderStatusCompleted: {OrderActorSystem}, // 受取連絡がないまま N 日経過
+	},
+	OrderStatusReceived: {
+		OrderStatusCompleted: {OrderActorSeller, OrderActorSystem},
+	},
+}
+
+type Order struct {
+	ID       string      `json:"id"`
+	ItemID   string      `json:"item_id"`
+	BuyerID  string      `json:"buyer_id"`
+	SellerID string      `json:"seller_id"`
+	Price    int         `json:"price"`
+	Status   OrderStatus `json:"status"`
+	// クーポンの割引（プラットフォーム負担。購入者の支払額は Price - Discount）
+	Discount   int    `json:"discount"`
+	CouponCode string `json:"coupon_code,omitempty"`
+	// 決済プロバイダ上の支払いIDと預かり状態
+	PaymentID    string       `json:"payment_id,omitempty"`
+	EscrowStatus EscrowStatus `json:"escrow_status,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	PaidAt       *time.Time   `json:"paid_at,omitempty"`
+	ShippedAt    *time.Time   `json:"shipped_at,omitempty"`
+	ReceivedAt   *time.Time   `json:"received_at,omitempty"`
+	CompletedAt  *time.Time   `json:"completed_at,omitempty"`
+	CancelledAt  *time.Time   `json:"cancelled_at,omitempty"`
+}
+
+// OrderEvent: 状態遷移の監査ログ
+type OrderEvent struct {
+	ID         string      `json:"id"`
+	OrderID    string      `json:"order_id"`
+	FromStatus OrderStatus `json:"from_status"`
+	ToStatus   OrderStatus `json:"to_status"`
+	ActorID    string      `json:"actor_id"`
+	ActorRole  OrderActor  `json:"actor_role"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// RoleOf: ユーザーがこの取引でどの立場かを返す（無関係なら空文字）
+// system の立場はユーザーには与えない（自動処理は OrderUsecase の内部からだけ遷移させる）
+func (o *Order) RoleOf(userID string) OrderActor {
+	switch {
+	case userID == SystemActorID:
+		return ""
+	case userID != "" && userID == o.BuyerID:
+		return OrderActorBuyer
+	case userID != "" && userID == o.SellerID:
+		return OrderActorSeller
+	}
+	return ""
+}
+
+// ReviewOpen: 取引完了から revealAfter 以内なら評価できる
+// それを過ぎると先に書かれた評価が公開されるので、読んでから仕返しの評価を書けないように締め切る
+func (o *Order) ReviewOpen(now time.Time, revealAfter time.Duration) bool {
+	return o.Status == OrderStatusCompleted && o.CompletedAt != nil && !now.After(o.CompletedAt.Add(revealAfter))
+}
+
+// ChargedAmount: 購入者が支払う金額（クーポンの割引後）
+func (o *Order) ChargedAmount() int {
+	return o.Price - o.Discount
+}
+
+// IsTerminal: これ以上遷移しない状態かどうか
+func (s OrderStatus) IsTerminal() bool {
+	return s == OrderStatusCompleted || s == OrderStatusCancelled
+}
+
+// CanTransition: from -> to の遷移を actor が行えるか検証する
+func CanTransition(from, to OrderStatus, actor OrderActor) error {
+	allowed, ok := orderTransitions[from][to]
+	if !ok {
+		return ErrIllegalTransition
+	}
+	for _, a := range allowed {
+		if a == actor {
+			return nil
+		}
+	}
+	return ErrTransitionForbidden
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestCanTransition は注文の状態遷移ルールの単体テスト
+func TestCanTransition(t *testing.T) {
+	testCases := []struct {
+		name    string
+		from    OrderStatus
+		to      OrderStatus
+		actor   OrderActor
+		wantErr error
+	}{
+		{name: "出品者が発送", from: OrderStatusPaid, to: OrderStatusShipped, actor: OrderActorSeller},
+		{name: "購入者は発送できない", from: OrderStatusPaid, to: OrderStatusShipped, actor: OrderActorBuyer, wantErr: ErrTransitionForbidden},
+		{name: "発送前なら購入者がキャンセル", from: OrderStatusPaid, to: OrderStatusCancelled, actor: OrderActorBuyer},
+		{name: "決済失敗による自動キャンセル", from: OrderStatusPaid, to: OrderStatusCancelled, actor: OrderActorSystem},
+		{name: "発送後はキャンセル不可", from: OrderStatusShipped, to: OrderStatusCancelled, actor: OrderActorSeller, wantErr: ErrIllegalTransition},
+		{name: "購入者が受取", from: OrderStatusShipped, to: OrderStatusReceived, actor: OrderActorBuyer},
+		{name: "受取連絡なしの自動完了", from: OrderStatusShipped, to: OrderStatusCompleted, actor: OrderActorSystem},
+		{name: "出品者は受取前に完了できない", from: OrderStatusShipped, to: OrderStatusCompleted, actor: OrderActorSeller, wantErr: ErrTransitionForbidden},
+		{name: "受取後に出品者が完了", from: OrderStatusReceived, to: OrderStatusCompleted, actor: OrderActorSeller},
+		{name: "完了後は遷移不可", from: OrderStatusCompleted, to: OrderStatusCancelled, actor: OrderActorSystem, wantErr: ErrIllegalTransition},
+		{name: "同じ状態への遷移は不可", from: OrderStatusPaid, to: OrderStatusPaid, actor: OrderActorSeller, wantErr: ErrIllegalTransition},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CanTransition(tc.from, tc.to, tc.actor)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("CanTransition(%s, %s, %s) error = %v, want %v", tc.from, tc.to, tc.actor, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestOrder_RoleOf は取引における立場の判定テスト
+func TestOrder_RoleOf(t *testing.T) {
+	o := &Order{BuyerID: "buyer", SellerID: "seller"}
+	testCases := []struct {
+		userID string
+		want   OrderActor
+	}{
+		{"buyer", OrderActorBuyer},
+		{"seller", OrderActorSeller},
+		{SystemActorID, ""}, // リクエストで system を名乗っても自動処理の権限は得られない
+		{"someone", ""},
+		{"", ""},
+	}
+	for _, tc := range testCases {
+		if got := o.RoleOf(tc.userID); got != tc.want {
+			t.Errorf("RoleOf(%q) = %q, want %q", tc.userID, got, tc.want)
+		}
+	}
+}
+
+// TestOrder_ReviewOpen は評価の受付期限のテスト
+func TestOrder_ReviewOpen(t *testing.T) {
+	completed := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	revealAfter := 14 * 24 * time.Hour
+	testCases := []struct {
+		name  string
+		order *Order
+		now   time.Time
+		want  bool
+	}{
+		{"取引完了直後", &Order{Status: OrderStatusCompleted, CompletedAt: &completed}, completed.Add(time.Hour), true},
+		{"ちょうど期限", &Order{Status: OrderStatusCompleted, CompletedAt: &completed}, completed.Add(revealAfter), true},
+		{"期限切れ（先の評価が公開される）", &Order{Status: OrderStatusCompleted, CompletedAt: &completed}, completed.Add(revealAfter + time.Second), false},
+		{"取引完了前", &Order{Status: OrderStatusReceived}, completed, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.order.ReviewOpen(tc.now, revealAfter); got != tc.want {
+				t.Errorf("ReviewOpen() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
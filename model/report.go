@@ -0,0 +1,111 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// ReportTarget: 通報の対象の種類
+type ReportTarget string
+
+const (
+	ReportTargetItem    ReportTarget = "item"
+	ReportTargetMessage ReportTarget = "message"
+	ReportTargetUser    ReportTarget = "user"
+)
+
+// ReportReason: 通報理由コード
+type ReportReason string
+
+const (
+	ReasonCounterfeit   ReportReason = "counterfeit"   // 偽物・ブランド品のコピー
+	ReasonProhibited    ReportReason = "prohibited"    // 出品禁止物
+	ReasonScam          ReportReason = "scam"          // 詐欺・外部取引への誘導
+	ReasonHarassment    ReportReason = "harassment"    // 嫌がらせ・暴言
+	ReasonSpam          ReportReason = "spam"          // スパム
+	ReasonInappropriate ReportReason = "inappropriate" // 不適切な画像・表現
+	ReasonOther         ReportReason = "other"
+)
+
+var reportReasons = map[ReportReason]bool{
+	ReasonCounterfeit: true, ReasonProhibited: true, ReasonScam: true, ReasonHarassment: true,
+	ReasonSpam: true, ReasonInappropriate: true, ReasonOther: true,
+}
+
+// Valid: 既知の通報理由コードか
+func (r ReportReason) Valid() bool {
+	return reportReasons[r]
+}
+
+// ReportStatus: 通報の処理状況
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"      // 未対応
+	ReportClaimed   ReportStatus = "claimed"   // 担当者が確認中
+	ReportResolved  ReportStatus = "resolved"  // 違反と判断（非表示のまま）
+	ReportDismissed ReportStatus = "dismissed" // 問題なし（表示を戻す）
+)
+
+// ModerationActionType: 監査ログに残す操作
+type ModerationActionType string
+
+const (
+	ModActionClaim    ModerationActionType = "claim"
+	ModActionResolve  ModerationActionType = "resolve"
+	ModActionDismiss  ModerationActionType = "dismiss"
+	ModActionAutoHide ModerationActionType = "auto_hide" // 通報数がしきい値に達した
+	ModActionHide     ModerationActionType = "hide"
+	ModActionUnhide   ModerationActionType = "unhide"
+)
+
+var (
+	ErrInvalidReportTarget = errors.New("target_type must be item, message or user")
+	ErrInvalidReportReason = errors.New("unknown reason code")
+)
+
+type Report struct {
+	ID         string       `json:"id"`
+	TargetType ReportTarget `json:"target_type"`
+	TargetID   string       `json:"target_id"`
+	ReporterID string       `json:"reporter_id"`
+	Reason     ReportReason `json:"reason"`
+	Note       string       `json:"note"`
+	Status     ReportStatus `json:"status"`
+	ClaimedBy  string       `json:"claimed_by,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// ModerationAction: モデレーターの操作の監査ログ
+type ModerationAction struct {
+	ID          string               `json:"id"`
+	ReportID    string               `json:"report_id,omitempty"`
+	TargetType  ReportTarget         `json:"target_type"`
+	TargetID    string               `json:"target_id"`
+	ModeratorID string               `json:"moderator_id"`
+	Action      ModerationActionType `json:"action"`
+	Note        string               `json:"note"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// Validate: 通報の対象と理由を検証する
+func (r *Report) Validate() error {
+	switch r.TargetType {
+	case ReportTargetItem, ReportTargetMessage, ReportTargetUser:
+	default:
+		return ErrInvalidReportTarget
+	}
+	if !r.Reason.Valid() {
+		return ErrInvalidReportReason
+	}
+	if r.TargetID == "" {
+		return errors.New("target_id is required")
+	}
+	return nil
+}
+
+// IsOpen: まだ対応が終わっていないか
+func (s ReportStatus) IsOpen() bool {
+	return s == ReportOpen || s == ReportClaimed
+}
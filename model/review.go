@@ -0,0 +1,60 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrInvalidRating = errors.New("rating must be 1-5 or good/normal/bad")
+
+// Review: 取引完了後の相互評価
+// 両者が評価するか公開期限が来るまで、相手の評価は見えない (ダブルブラインド)
+type Review struct {
+	ID           string     `json:"id"`
+	OrderID      string     `json:"order_id"`
+	ReviewerID   string     `json:"reviewer_id"`
+	RevieweeID   string     `json:"reviewee_id"`
+	ReviewerRole OrderActor `json:"reviewer_role"`
+	Rating       int        `json:"rating"` // 1〜5
+	Comment      string     `json:"comment"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevealedAt   *time.Time `json:"revealed_at,omitempty"`
+}
+
+// Reputation: 公開済みの評価の集計
+type Reputation struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+	Good    int     `json:"good"`   // 4〜5
+	Normal  int     `json:"normal"` // 3
+	Bad     int     `json:"bad"`    // 1〜2
+}
+
+// ParseRating: "good" / "normal" / "bad" または 1〜5 の数値を評価値に変換する
+func ParseRating(v interface{}) (int, error) {
+	switch r := v.(type) {
+	case string:
+		switch r {
+		case "good":
+			return 5, nil
+		case "normal":
+			return 3, nil
+		case "bad":
+			return 1, nil
+		}
+	case float64: // JSON の数値
+		if r == math.Trunc(r) && r >= 1 && r <= 5 {
+			return int(r), nil
+		}
+	}
+	return 0, ErrInvalidRating
+}
+
+// RatingAverage: 合計と件数から小数第1位までの平均を求める
+func RatingAverage(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(float64(sum)/float64(count)*10) / 10
+}
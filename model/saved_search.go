@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSavedSearch = errors.New("keyword or price range is required")
+
+// SavedSearch: 新着通知を受け取るために保存した検索条件
+// MinPrice / MaxPrice は 0 なら条件なし
+type SavedSearch struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Keyword   string    `json:"keyword"`
+	MinPrice  int       `json:"min_price"`
+	MaxPrice  int       `json:"max_price"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate: 何にでも一致する条件や、逆転した価格帯は保存しない
+func (s *SavedSearch) Validate() error {
+	s.Keyword = strings.TrimSpace(s.Keyword)
+	if s.MinPrice < 0 || s.MaxPrice < 0 || (s.MaxPrice > 0 && s.MinPrice > s.MaxPrice) {
+		return errors.New("invalid price range")
+	}
+	if s.Keyword == "" && s.MinPrice == 0 && s.MaxPrice == 0 {
+		return ErrInvalidSavedSearch
+	}
+	return nil
+}
+
+// Matches: 商品が検索条件に合うか（キーワードは商品名・説明文の部分一致、大文字小文字は区別しない）
+// 自分の出品は対象外
+func (s *SavedSearch) Matches(item *Item) bool {
+	if item.SellerID != "" && item.SellerID == s.UserID {
+		return false
+	}
+	if s.MinPrice > 0 && item.Price < s.MinPrice {
+		return false
+	}
+	if s.MaxPrice > 0 && item.Price > s.MaxPrice {
+		return false
+	}
+	if s.Keyword == "" {
+		return true
+	}
+	text := strings.ToLower(item.Name + " " + item.Description)
+	for _, word := range strings.Fields(strings.ToLower(s.Keyword)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
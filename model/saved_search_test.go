@@ -0,0 +1,30 @@
+package model
+
+import "testing"
+
+// TestSavedSearchMatches は保存した検索条件と新着商品の照合のテスト
+func TestSavedSearchMatches(t *testing.T) {
+	item := &Item{Name: "ジャンク ThinkPad X220", Description: "液晶割れ 部品取りに", Price: 3000, SellerID: "seller"}
+
+	testCases := []struct {
+		name   string
+		search SavedSearch
+		want   bool
+	}{
+		{name: "キーワードが商品名に一致", search: SavedSearch{UserID: "u", Keyword: "thinkpad"}, want: true},
+		{name: "複数キーワードはすべて含む必要がある", search: SavedSearch{UserID: "u", Keyword: "ThinkPad 部品取り"}, want: true},
+		{name: "一部のキーワードが含まれない", search: SavedSearch{UserID: "u", Keyword: "ThinkPad X230"}, want: false},
+		{name: "価格帯の範囲内", search: SavedSearch{UserID: "u", MinPrice: 1000, MaxPrice: 5000}, want: true},
+		{name: "上限より高い", search: SavedSearch{UserID: "u", Keyword: "ジャンク", MaxPrice: 2000}, want: false},
+		{name: "下限より安い", search: SavedSearch{UserID: "u", MinPrice: 5000}, want: false},
+		{name: "自分の出品は通知しない", search: SavedSearch{UserID: "seller", Keyword: "ThinkPad"}, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.search.Matches(item); got != tc.want {
+				t.Errorf("Matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
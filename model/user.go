@@ -2,10 +2,6 @@ package model
 
 import (
 	"errors"
-	"math/rand"
-	"time"
-
-	"github.com/oklog/ulid/v2"
 )
 
 type User struct {
@@ -17,11 +13,8 @@ type User struct {
 
 func NewUser(name, email, password string) (*User, error) {
 	// ID生成 (ULID)
-	entropy := ulid.Monotonic(rand.New(rand.NewSource(time.Now().UnixNano())), 0)
-	newID := ulid.MustNew(ulid.Timestamp(time.Now()), entropy).String()
-
 	user := &User{
-		ID:       newID,
+		ID:       NewID(),
 		Name:     name,
 		Email:    email,
 		Password: password,
@@ -13,39 +13,24 @@ func TestUser_Validate(t *testing.T) {
 	}{
 		{
 			name:    "正常なケース",
-			user:    User{Name: "Taro", Age: 30},
+			user:    User{Name: "Taro", Email: "taro@example.com", Password: "secret"},
 			wantErr: false, // エラーは期待しない
 		},
 		{
 			name:    "エラー: 名前が空",
-			user:    User{Name: "", Age: 30},
+			user:    User{Name: "", Email: "taro@example.com", Password: "secret"},
 			wantErr: true, // エラーを期待する
 		},
 		{
-			name:    "エラー: 名前が長すぎる (51文字)",
-			user:    User{Name: "123456789012345678901234567890123456789012345678901", Age: 30},
+			name:    "エラー: メールアドレスが空",
+			user:    User{Name: "Jiro", Email: "", Password: "secret"},
 			wantErr: true, // エラーを期待する
 		},
 		{
-			name:    "エラー: 年齢が若すぎる (19歳)",
-			user:    User{Name: "Jiro", Age: 19},
+			name:    "エラー: パスワードが空",
+			user:    User{Name: "Saburo", Email: "saburo@example.com", Password: ""},
 			wantErr: true, // エラーを期待する
 		},
-		{
-			name:    "エラー: 年齢が高すぎる (81歳)",
-			user:    User{Name: "Saburo", Age: 81},
-			wantErr: true, // エラーを期待する
-		},
-		{
-			name:    "正常: 境界値 (20歳)",
-			user:    User{Name: "Shiro", Age: 20},
-			wantErr: false, // エラーは期待しない
-		},
-		{
-			name:    "正常: 境界値 (80歳)",
-			user:    User{Name: "Goro", Age: 80},
-			wantErr: false, // エラーは期待しない
-		},
 	}
 
 	// 各テストケースをループで実行
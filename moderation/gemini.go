@@ -0,0 +1,106 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hackathon-backend/model"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const geminiEndpoint = "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:generateContent"
+
+// GeminiChecker: Gemini に内容を判定させる
+type GeminiChecker struct {
+	APIKey string
+	// Endpoint: generateContent の URL（テスト用に差し替えられる）
+	Endpoint string
+	Client   *http.Client
+}
+
+func NewGeminiChecker(apiKey string) *GeminiChecker {
+	return &GeminiChecker{APIKey: apiKey, Endpoint: geminiEndpoint, Client: &http.Client{}}
+}
+
+// geminiPrompt: 本文は JSON の文字列として埋め込み、指示の一部として読まれないようにする
+const geminiPrompt = `あなたはフリマアプリの取引メッセージの審査担当です。
+次の JSON 文字列のメッセージを判定し、以下の JSON 形式のみで答えてください。
+{"verdict": "safe | borderline | unsafe", "reason": "harassment | scam | spam | inappropriate | other", "detail": "判定の理由（短く）"}
+・unsafe: 攻撃的・暴力的・差別的な表現、脅迫、性的な嫌がらせ
+・borderline: 外部での取引や連絡先交換への誘導、強い口調など、人の確認が必要なもの
+・safe: 通常の取引のやりとり
+メッセージ: %s`
+
+// Check: Gemini の判定を Result に変換する
+func (g *GeminiChecker) Check(ctx context.Context, text string) (*Result, error) {
+	quoted, _ := json.Marshal(text)
+	body, _ := json.Marshal(map[string]interface{}{
+		"contents":         []map[string]interface{}{{"parts": []map[string]string{{"text": fmt.Sprintf(geminiPrompt, quoted)}}}},
+		"generationConfig": map[string]string{"responseMimeType": "application/json"},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.Endpoint+"?key="+g.APIKey, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gemini API error (%d): %s", resp.StatusCode, strings.ReplaceAll(string(respBody), "\n", " "))
+	}
+	var gr struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(respBody, &gr); err != nil {
+		return nil, err
+	}
+	if len(gr.Candidates) == 0 || len(gr.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("gemini returned no content")
+	}
+	return parseGeminiVerdict(gr.Candidates[0].Content.Parts[0].Text)
+}
+
+// parseGeminiVerdict: Gemini が返した JSON（コードブロックで囲まれていてもよい）を読む
+func parseGeminiVerdict(text string) (*Result, error) {
+	text = strings.ReplaceAll(text, "```json", "")
+	text = strings.ReplaceAll(text, "```", "")
+	var v struct {
+		Verdict string `json:"verdict"`
+		Reason  string `json:"reason"`
+		Detail  string `json:"detail"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &v); err != nil {
+		return nil, fmt.Errorf("invalid gemini verdict: %w", err)
+	}
+
+	res := &Result{Source: "ai", Reason: model.ReportReason(v.Reason), Detail: v.Detail}
+	switch strings.ToLower(v.Verdict) {
+	case "safe":
+		return &Result{Verdict: Allow, Source: "ai"}, nil
+	case "borderline":
+		res.Verdict = Flag
+	case "unsafe":
+		res.Verdict = Block
+	default:
+		return nil, fmt.Errorf("invalid gemini verdict %q", v.Verdict)
+	}
+	if !res.Reason.Valid() {
+		res.Reason = model.ReasonOther
+	}
+	return res, nil
+}
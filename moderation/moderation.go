@@ -0,0 +1,95 @@
+// Package moderation: メッセージなどの投稿内容の審査（NG ワード・正規表現のルールと AI の判定を組み合わせる）
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hackathon-backend/model"
+	"log"
+	"time"
+)
+
+// Verdict: 審査の結果
+type Verdict string
+
+const (
+	Allow Verdict = "allow" // 問題なし
+	Flag  Verdict = "flag"  // 際どい（保存するが、モデレーターに確認してもらう）
+	Block Verdict = "block" // 送信させない
+)
+
+// severity: 結果の重さ（重い方を採用する）
+func (v Verdict) severity() int {
+	switch v {
+	case Block:
+		return 2
+	case Flag:
+		return 1
+	}
+	return 0
+}
+
+// ErrUnavailable: AI の判定ができず、fail-closed の設定のため送信させない
+var ErrUnavailable = errors.New("content moderation is temporarily unavailable")
+
+// defaultTimeout: AI の判定を待つ時間の既定値
+const defaultTimeout = 5 * time.Second
+
+// Result: 審査の結果と理由
+type Result struct {
+	Verdict Verdict `json:"verdict"`
+	// Reason: 理由（通報理由コードと同じ）
+	Reason model.ReportReason `json:"reason,omitempty"`
+	// Source: 判定したもの（"rules" または "ai"）
+	Source string `json:"source,omitempty"`
+	// Detail: 一致したルールや AI の説明（モデレーター向け。利用者には返さない）
+	Detail string `json:"-"`
+}
+
+// Checker: AI などの外部の判定
+type Checker interface {
+	Check(ctx context.Context, text string) (*Result, error)
+}
+
+// Moderator: ルールで判定し、送信を止めるほどでなければ AI にも判定させる
+type Moderator struct {
+	Rules *RuleSet
+	// AI: nil なら AI の判定は使わない
+	AI Checker
+	// FailOpen: AI の判定に失敗したとき、ルールの結果だけで通すか（false なら ErrUnavailable）
+	FailOpen bool
+	// Timeout: AI の判定を待つ時間（0 なら 5 秒）
+	Timeout time.Duration
+}
+
+// Check: text を審査する
+func (m *Moderator) Check(ctx context.Context, text string) (*Result, error) {
+	res := &Result{Verdict: Allow}
+	if m.Rules != nil {
+		res = m.Rules.Check(text)
+	}
+	if res.Verdict == Block || m.AI == nil {
+		return res, nil
+	}
+
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ai, err := m.AI.Check(ctx, text)
+	if err != nil {
+		if !m.FailOpen {
+			return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+		log.Printf("fail: ai moderation, using rules only, %v\n", err)
+		return res, nil
+	}
+	if ai.Verdict.severity() > res.Verdict.severity() {
+		return ai, nil
+	}
+	return res, nil
+}
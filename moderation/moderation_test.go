@@ -0,0 +1,158 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"hackathon-backend/model"
+	"testing"
+)
+
+// TestNormalize は照合の前に全角・カタカナ・大文字・空白をそろえることを確認する
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "全角英字と全角空白", in: "ＬＩＮＥ　ＩＤ", want: "lineid"},
+		{name: "カタカナはひらがなに", in: "シネ", want: "しね"},
+		{name: "大文字と空白", in: "Hello World", want: "helloworld"},
+		{name: "全角数字", in: "０９０-１２３４", want: "090-1234"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Normalize(tc.in); got != tc.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestDefaultRules は既定のルールで暴言を止め、外部への誘導を確認に回すことを確認する
+func TestDefaultRules(t *testing.T) {
+	rules, err := DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := NewRuleSet(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		text   string
+		want   Verdict
+		reason model.ReportReason
+	}{
+		{name: "通常のやりとり", text: "購入を検討しています。発送はいつ頃になりますか？", want: Allow},
+		{name: "暴言は送信させない", text: "死ね", want: Block, reason: model.ReasonHarassment},
+		{name: "全角・空白を入れてもすり抜けない", text: "ころ す ぞ", want: Block, reason: model.ReasonHarassment},
+		{name: "外部への誘導は確認に回す", text: "ＬＩＮＥ ＩＤ教えてください", want: Flag, reason: model.ReasonScam},
+		{name: "電話番号", text: "090-1234-5678 に連絡ください", want: Flag, reason: model.ReasonScam},
+		{name: "URL", text: "詳しくは https://example.com で", want: Flag, reason: model.ReasonSpam},
+		{name: "重い方を採用する", text: "直接取引しないなら殺すぞ", want: Block, reason: model.ReasonHarassment},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := set.Check(tc.text)
+			if got.Verdict != tc.want || got.Reason != tc.reason {
+				t.Errorf("Check(%q) = %s/%s, want %s/%s", tc.text, got.Verdict, got.Reason, tc.want, tc.reason)
+			}
+		})
+	}
+}
+
+// TestNewRuleSetRejectsInvalidRules は不正なルールを読み込まないことを確認する
+func TestNewRuleSetRejectsInvalidRules(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule Rule
+	}{
+		{name: "verdict がない", rule: Rule{Word: "x", Reason: model.ReasonSpam}},
+		{name: "理由が不明", rule: Rule{Word: "x", Verdict: Block, Reason: "unknown"}},
+		{name: "正規表現が不正", rule: Rule{Pattern: "(", Verdict: Block, Reason: model.ReasonSpam}},
+		{name: "照合するものがない", rule: Rule{Verdict: Block, Reason: model.ReasonSpam}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewRuleSet([]Rule{tc.rule}); err == nil {
+				t.Errorf("NewRuleSet(%+v) succeeded, want error", tc.rule)
+			}
+		})
+	}
+}
+
+// fakeChecker: 決まった結果を返し、呼ばれた回数を数える AI 判定
+type fakeChecker struct {
+	res   *Result
+	err   error
+	calls int
+}
+
+func (f *fakeChecker) Check(ctx context.Context, text string) (*Result, error) {
+	f.calls++
+	return f.res, f.err
+}
+
+// TestModerator はルールと AI の結果の組み合わせ方と、AI の障害時の扱いを確認する
+func TestModerator(t *testing.T) {
+	rules, _ := NewRuleSet([]Rule{
+		{Word: "ng", Verdict: Block, Reason: model.ReasonHarassment},
+		{Word: "http", Verdict: Flag, Reason: model.ReasonSpam},
+	})
+	aiBlock := &Result{Verdict: Block, Reason: model.ReasonHarassment, Source: "ai"}
+	aiAllow := &Result{Verdict: Allow, Source: "ai"}
+	aiDown := errors.New("timeout")
+
+	testCases := []struct {
+		name     string
+		text     string
+		ai       *fakeChecker
+		failOpen bool
+		want     Verdict
+		wantErr  error
+		aiCalls  int
+	}{
+		{name: "ルールで止めたら AI に聞かない", text: "ng", ai: &fakeChecker{res: aiAllow}, failOpen: true, want: Block, aiCalls: 0},
+		{name: "AI が止める", text: "hello", ai: &fakeChecker{res: aiBlock}, failOpen: true, want: Block, aiCalls: 1},
+		{name: "ルールの確認は AI が問題なしでも残す", text: "http", ai: &fakeChecker{res: aiAllow}, failOpen: true, want: Flag, aiCalls: 1},
+		{name: "fail-open はルールの結果で通す", text: "http", ai: &fakeChecker{err: aiDown}, failOpen: true, want: Flag, aiCalls: 1},
+		{name: "fail-closed は送信させない", text: "hello", ai: &fakeChecker{err: aiDown}, wantErr: ErrUnavailable, aiCalls: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &Moderator{Rules: rules, AI: tc.ai, FailOpen: tc.failOpen}
+			got, err := m.Check(context.Background(), tc.text)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Check() error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && got.Verdict != tc.want {
+				t.Errorf("Check() = %s, want %s", got.Verdict, tc.want)
+			}
+			if tc.ai.calls != tc.aiCalls {
+				t.Errorf("AI called %d times, want %d", tc.ai.calls, tc.aiCalls)
+			}
+		})
+	}
+}
+
+// TestParseGeminiVerdict は Gemini の応答から判定を読み取ることを確認する
+func TestParseGeminiVerdict(t *testing.T) {
+	got, err := parseGeminiVerdict("```json\n{\"verdict\": \"borderline\", \"reason\": \"scam\", \"detail\": \"外部への誘導\"}\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Verdict != Flag || got.Reason != model.ReasonScam || got.Source != "ai" {
+		t.Errorf("parseGeminiVerdict() = %+v", got)
+	}
+
+	got, err = parseGeminiVerdict(`{"verdict": "unsafe", "reason": "rude"}`)
+	if err != nil || got.Reason != model.ReasonOther {
+		t.Errorf("unknown reason = %+v, %v, want other", got, err)
+	}
+
+	if _, err := parseGeminiVerdict(`{"verdict": "maybe"}`); err == nil {
+		t.Error("unknown verdict accepted")
+	}
+}
@@ -0,0 +1,124 @@
+package moderation
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"hackathon-backend/model"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//go:embed rules.json
+var defaultRulesJSON []byte
+
+// Rule: NG ワードまたは正規表現と、一致したときの結果
+// どちらも正規化した本文（Normalize）に対して照合するので、Word・Pattern も正規化後の形で書く
+type Rule struct {
+	Word    string             `json:"word,omitempty"`
+	Pattern string             `json:"pattern,omitempty"`
+	Verdict Verdict            `json:"verdict"`
+	Reason  model.ReportReason `json:"reason"`
+}
+
+// RuleSet: 照合の準備をしたルールの集まり
+type RuleSet struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// DefaultRules: 組み込みのルール
+func DefaultRules() ([]Rule, error) {
+	var rules []Rule
+	if err := json.Unmarshal(defaultRulesJSON, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// LoadRules: JSON ファイルからルールを読み込む（運用で追加する NG ワードなど）
+func LoadRules(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// NewRuleSet: ルールを検証し、正規表現をコンパイルする
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	s := &RuleSet{}
+	for i, r := range rules {
+		if r.Verdict != Flag && r.Verdict != Block {
+			return nil, fmt.Errorf("rule %d: verdict must be flag or block", i)
+		}
+		if !r.Reason.Valid() {
+			return nil, fmt.Errorf("rule %d: unknown reason %q", i, r.Reason)
+		}
+		c := compiledRule{Rule: r}
+		switch {
+		case r.Pattern != "":
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			c.re = re
+		case r.Word != "":
+			c.Word = Normalize(r.Word)
+		default:
+			return nil, fmt.Errorf("rule %d: word or pattern is required", i)
+		}
+		s.rules = append(s.rules, c)
+	}
+	return s, nil
+}
+
+// Check: 一致したルールのうち最も重い結果（一致しなければ Allow）
+func (s *RuleSet) Check(text string) *Result {
+	normalized := Normalize(text)
+	res := &Result{Verdict: Allow}
+	for _, r := range s.rules {
+		matched := false
+		if r.re != nil {
+			matched = r.re.MatchString(normalized)
+		} else {
+			matched = strings.Contains(normalized, r.Word)
+		}
+		if matched && r.Verdict.severity() > res.Verdict.severity() {
+			detail := r.Word
+			if r.re != nil {
+				detail = r.Pattern
+			}
+			res = &Result{Verdict: r.Verdict, Reason: r.Reason, Source: "rules", Detail: detail}
+		}
+	}
+	return res
+}
+
+// Normalize: 照合用に本文をそろえる
+// 全角英数字・記号を半角に、英字を小文字に、カタカナをひらがなにし、空白を取り除く
+func Normalize(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r >= 0xFF01 && r <= 0xFF5E: // 全角 ASCII
+			r -= 0xFEE0
+		case r >= 0x30A1 && r <= 0x30F6: // カタカナ
+			r -= 0x60
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
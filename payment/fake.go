@@ -0,0 +1,135 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPaymentForgotten: 開発用プロバイダの再起動前の支払いで、もう状態が分からない（ErrPaymentNotFound でもある）
+var ErrPaymentForgotten = errors.New("payment belongs to an earlier run of the fake provider")
+
+// FakeProvider: テストやローカル開発用の決済プロバイダ
+// ID は連番で振られるため、結果が決定的になる。支払いはメモリにしかないので、再起動すると以前の支払いは ErrPaymentForgotten になる
+type FakeProvider struct {
+	mu            sync.Mutex
+	seq           int
+	payments      map[string]*Payment
+	webhookSecret string
+	// DeclineAmount: この金額以上の与信は拒否する（0 なら拒否しない）
+	DeclineAmount int
+	// Now: 署名検証に使う現在時刻（nil なら time.Now）
+	Now func() time.Time
+	// Run: ID に含める起動ごとの識別子（再起動後に連番が以前の支払いの ID と重ならないようにする。空なら含めない）
+	Run string
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		payments:      make(map[string]*Payment),
+		webhookSecret: webhookSecret,
+	}
+}
+
+func (p *FakeProvider) nextID(prefix string) string {
+	p.seq++
+	if p.Run != "" {
+		return fmt.Sprintf("%s_fake_%s_%06d", prefix, p.Run, p.seq)
+	}
+	return fmt.Sprintf("%s_fake_%06d", prefix, p.seq)
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.Amount <= 0 || (p.DeclineAmount > 0 && req.Amount >= p.DeclineAmount) {
+		return nil, ErrDeclined
+	}
+	pay := &Payment{ID: p.nextID("pi"), Amount: req.Amount, Currency: req.Currency, Status: StatusAuthorized}
+	p.payments[pay.ID] = pay
+	copied := *pay
+	return &copied, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, paymentID string) (*Payment, error) {
+	return p.update(paymentID, StatusAuthorized, StatusCaptured)
+}
+
+func (p *FakeProvider) Cancel(ctx context.Context, paymentID string) error {
+	_, err := p.update(paymentID, StatusAuthorized, StatusCancelled)
+	return err
+}
+
+// Refund: amount が 0 以下なら全額を返金する（支払った金額を超えると、実際のプロバイダと同じく ErrRefundTooLarge）
+func (p *FakeProvider) Refund(ctx context.Context, paymentID string, amount int) (*Refund, error) {
+	if pay, ok := p.Get(paymentID); ok && amount > pay.Amount {
+		return nil, fmt.Errorf("%w: %d > %d", ErrRefundTooLarge, amount, pay.Amount)
+	}
+	pay, err := p.update(paymentID, StatusCaptured, StatusRefunded)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		amount = pay.Amount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &Refund{ID: p.nextID("re"), PaymentID: paymentID, Amount: amount}, nil
+}
+
+func (p *FakeProvider) update(paymentID string, from, to Status) (*Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pay, ok := p.payments[paymentID]
+	if !ok {
+		if p.fromEarlierRun(paymentID) {
+			return nil, fmt.Errorf("%w: %w", ErrPaymentForgotten, ErrPaymentNotFound)
+		}
+		return nil, ErrPaymentNotFound
+	}
+	if pay.Status != from {
+		return nil, fmt.Errorf("payment %s is %s, expected %s", paymentID, pay.Status, from)
+	}
+	pay.Status = to
+	copied := *pay
+	return &copied, nil
+}
+
+// fromEarlierRun: paymentID が再起動前のこのプロバイダで振られた ID か
+func (p *FakeProvider) fromEarlierRun(paymentID string) bool {
+	if p.Run == "" {
+		return false
+	}
+	prefix, rest, ok := strings.Cut(paymentID, "_fake_")
+	return ok && prefix != "" && !strings.HasPrefix(rest, p.Run+"_")
+}
+
+// Get: テスト用に現在の状態を返す
+func (p *FakeProvider) Get(paymentID string) (*Payment, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pay, ok := p.payments[paymentID]
+	if !ok {
+		return nil, false
+	}
+	copied := *pay
+	return &copied, true
+}
+
+func (p *FakeProvider) VerifyWebhook(payload []byte, signatureHeader string) (*Event, error) {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	if err := verifySignature(p.webhookSecret, payload, signatureHeader, now); err != nil {
+		return nil, err
+	}
+	return parseEvent(payload)
+}
@@ -0,0 +1,170 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestFakeProvider は与信・確定・返金の流れが決定的に動くことを確認する
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeProvider("whsec_test")
+	p.DeclineAmount = 100000
+
+	pay, err := p.Authorize(ctx, AuthorizeRequest{Amount: 3000, Currency: "jpy", OrderID: "o1"})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if pay.ID != "pi_fake_000001" || pay.Status != StatusAuthorized {
+		t.Errorf("Authorize() = %+v", pay)
+	}
+	if _, err := p.Capture(ctx, "pi_fake_999999"); !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("Capture() unknown payment error = %v, want ErrPaymentNotFound", err)
+	}
+
+	if _, err := p.Refund(ctx, pay.ID, 0); err == nil {
+		t.Error("Refund() before capture should fail")
+	}
+	if _, err := p.Capture(ctx, pay.ID); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	re, err := p.Refund(ctx, pay.ID, 0)
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if re.Amount != 3000 {
+		t.Errorf("Refund().Amount = %d, want 3000", re.Amount)
+	}
+	over, _ := p.Authorize(ctx, AuthorizeRequest{Amount: 2500, Currency: "jpy", OrderID: "o2"})
+	p.Capture(ctx, over.ID)
+	if _, err := p.Refund(ctx, over.ID, 3000); !errors.Is(err, ErrRefundTooLarge) {
+		t.Errorf("Refund() over the payment error = %v, want ErrRefundTooLarge", err)
+	}
+	if got, _ := p.Get(pay.ID); got.Status != StatusRefunded {
+		t.Errorf("status = %s, want refunded", got.Status)
+	}
+
+	if _, err := p.Authorize(ctx, AuthorizeRequest{Amount: 100000, Currency: "jpy"}); !errors.Is(err, ErrDeclined) {
+		t.Errorf("Authorize() over limit error = %v, want ErrDeclined", err)
+	}
+}
+
+// TestFakeProvider_Restart は再起動前の支払いだけを ErrPaymentForgotten にすることを確認する
+func TestFakeProvider_Restart(t *testing.T) {
+	ctx := context.Background()
+	before := NewFakeProvider("whsec_test")
+	before.Run = "r1"
+	pay, err := before.Authorize(ctx, AuthorizeRequest{Amount: 3000, Currency: "jpy", OrderID: "o1"})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	after := NewFakeProvider("whsec_test")
+	after.Run = "r2"
+	_, err = after.Capture(ctx, pay.ID)
+	if !errors.Is(err, ErrPaymentForgotten) || !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("Capture() earlier payment error = %v, want ErrPaymentForgotten", err)
+	}
+	if _, err := after.Capture(ctx, "pi_fake_r2_999999"); errors.Is(err, ErrPaymentForgotten) || !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("Capture() unknown payment of this run error = %v, want ErrPaymentNotFound only", err)
+	}
+	if _, err := after.Capture(ctx, "pi_3Nxyz"); errors.Is(err, ErrPaymentForgotten) {
+		t.Errorf("Capture() non-fake payment error = %v, want not ErrPaymentForgotten", err)
+	}
+}
+
+// TestVerifyWebhook は署名検証のテスト
+func TestVerifyWebhook(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	p := NewFakeProvider("whsec_test")
+	p.Now = func() time.Time { return now }
+	payload := []byte(`{"id":"evt_1","type":"charge.refunded","data":{"object":{"id":"ch_1","payment_intent":"pi_1"}}}`)
+
+	testCases := []struct {
+		name    string
+		header  string
+		payload []byte
+		wantErr bool
+	}{
+		{name: "正しい署名", header: SignWebhook("whsec_test", payload, now), payload: payload},
+		{name: "秘密鍵が違う", header: SignWebhook("other", payload, now), payload: payload, wantErr: true},
+		{name: "本文の改ざん", header: SignWebhook("whsec_test", payload, now), payload: []byte(`{"id":"evt_2"}`), wantErr: true},
+		{name: "古いタイムスタンプ", header: SignWebhook("whsec_test", payload, now.Add(-time.Hour)), payload: payload, wantErr: true},
+		{name: "ヘッダーなし", header: "", payload: payload, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ev, err := p.VerifyWebhook(tc.payload, tc.header)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("VerifyWebhook() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err == nil && (ev.Type != EventChargeRefunded || ev.PaymentID != "pi_1") {
+				t.Errorf("VerifyWebhook() = %+v", ev)
+			}
+		})
+	}
+
+	// 秘密鍵が未設定なら、空の鍵で署名したものも含めてすべて拒否する
+	unset := NewFakeProvider("")
+	unset.Now = p.Now
+	if _, err := unset.VerifyWebhook(payload, SignWebhook("", payload, now)); !errors.Is(err, ErrWebhookSecretMissing) {
+		t.Errorf("VerifyWebhook() without secret error = %v, want ErrWebhookSecretMissing", err)
+	}
+}
+
+// TestStripeProvider はローカルのモックサーバーに対して API 呼び出しを確認する
+func TestStripeProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/payment_intents", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk_test" || r.Header.Get("Idempotency-Key") != "authorize-o1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"bad auth"}}`))
+			return
+		}
+		r.ParseForm()
+		if r.Form.Get("capture_method") != "manual" || r.Form.Get("amount") == "999999" {
+			w.WriteHeader(http.StatusPaymentRequired)
+			w.Write([]byte(`{"error":{"code":"card_declined","message":"Your card was declined."}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"pi_123","amount":` + r.Form.Get("amount") + `,"currency":"jpy","status":"requires_capture"}`))
+	})
+	mux.HandleFunc("POST /v1/payment_intents/{id}/capture", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"` + r.PathValue("id") + `","amount":3000,"currency":"jpy","status":"succeeded"}`))
+	})
+	mux.HandleFunc("POST /v1/refunds", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Write([]byte(`{"id":"re_1","amount":3000,"payment_intent":"` + r.Form.Get("payment_intent") + `","status":"succeeded"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	p := NewStripeProvider(srv.URL, "sk_test", "whsec")
+
+	pay, err := p.Authorize(ctx, AuthorizeRequest{Amount: 3000, Currency: "JPY", OrderID: "o1"})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if pay.ID != "pi_123" || pay.Status != StatusAuthorized || pay.Amount != 3000 {
+		t.Errorf("Authorize() = %+v", pay)
+	}
+
+	pay, err = p.Capture(ctx, "pi_123")
+	if err != nil || pay.Status != StatusCaptured {
+		t.Errorf("Capture() = %+v, %v", pay, err)
+	}
+
+	re, err := p.Refund(ctx, "pi_123", 0)
+	if err != nil || re.ID != "re_1" || re.PaymentID != "pi_123" {
+		t.Errorf("Refund() = %+v, %v", re, err)
+	}
+
+	if _, err := p.Authorize(ctx, AuthorizeRequest{Amount: 999999, Currency: "jpy", OrderID: "o1"}); !errors.Is(err, ErrDeclined) {
+		t.Errorf("Authorize() declined error = %v, want ErrDeclined", err)
+	}
+}
@@ -0,0 +1,142 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status: 決済の状態
+type Status string
+
+const (
+	StatusAuthorized Status = "authorized" // 与信確保済み（未確定）
+	StatusCaptured   Status = "captured"   // 売上確定（プラットフォームが預かっている）
+	StatusCancelled  Status = "cancelled"  // 与信取消
+	StatusRefunded   Status = "refunded"   // 返金済み
+)
+
+var (
+	ErrDeclined        = errors.New("payment declined")
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrRefundTooLarge: 支払った金額より多くは返金できない
+	ErrRefundTooLarge   = errors.New("refund amount exceeds the payment")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrWebhookSecretMissing: 秘密鍵が未設定（空の鍵の署名は誰でも作れるので、すべての Webhook を拒否する）
+	ErrWebhookSecretMissing = errors.New("webhook secret is not configured")
+)
+
+// Payment: 決済プロバイダ上の支払い
+type Payment struct {
+	ID       string
+	Amount   int
+	Currency string
+	Status   Status
+}
+
+// Refund: 返金
+type Refund struct {
+	ID        string
+	PaymentID string
+	Amount    int
+}
+
+// Event: Webhook で通知されるイベント
+type Event struct {
+	ID        string
+	Type      string
+	PaymentID string
+}
+
+// Webhook イベント種別（Stripe 互換）
+const (
+	EventPaymentSucceeded = "payment_intent.succeeded"
+	EventPaymentFailed    = "payment_intent.payment_failed"
+	EventPaymentCanceled  = "payment_intent.canceled"
+	EventChargeRefunded   = "charge.refunded"
+)
+
+// AuthorizeRequest: 与信確保のリクエスト
+type AuthorizeRequest struct {
+	Amount   int
+	Currency string
+	// OrderID: 冪等キーとメタデータに使う
+	OrderID string
+}
+
+// Provider: 決済プロバイダの抽象
+type Provider interface {
+	// Authorize: 金額の与信を確保する（まだ売上は確定しない）
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error)
+	// Capture: 与信を確定して売上にする
+	Capture(ctx context.Context, paymentID string) (*Payment, error)
+	// Cancel: 確定前の与信を取り消す
+	Cancel(ctx context.Context, paymentID string) error
+	// Refund: 確定済みの支払いを返金する
+	Refund(ctx context.Context, paymentID string, amount int) (*Refund, error)
+	// VerifyWebhook: 署名を検証してイベントを取り出す
+	VerifyWebhook(payload []byte, signatureHeader string) (*Event, error)
+}
+
+// signatureTolerance: Webhook 署名のタイムスタンプの許容誤差
+const signatureTolerance = 5 * time.Minute
+
+// SignWebhook: Stripe と同じ形式 ("t=...,v1=...") の署名ヘッダーを作る
+func SignWebhook(secret string, payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, computeSignature(secret, ts, payload))
+}
+
+// verifySignature: 署名ヘッダーを検証する
+func verifySignature(secret string, payload []byte, header string, now time.Time) error {
+	if secret == "" {
+		return ErrWebhookSecretMissing
+	}
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	if ts == "" || len(sigs) == 0 {
+		return ErrInvalidSignature
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > signatureTolerance || d < -signatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	expected := computeSignature(secret, ts, payload)
+	for _, s := range sigs {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func computeSignature(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
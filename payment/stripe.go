@@ -0,0 +1,173 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStripeBaseURL: 本番の Stripe API
+const DefaultStripeBaseURL = "https://api.stripe.com"
+
+// StripeProvider: Stripe 互換 API を使う決済プロバイダ
+// BaseURL を差し替えればローカルのモックサーバーに向けられる
+type StripeProvider struct {
+	BaseURL       string
+	SecretKey     string
+	WebhookSecret string
+	Client        *http.Client
+}
+
+func NewStripeProvider(baseURL, secretKey, webhookSecret string) *StripeProvider {
+	if baseURL == "" {
+		baseURL = DefaultStripeBaseURL
+	}
+	return &StripeProvider{
+		BaseURL:       strings.TrimRight(baseURL, "/"),
+		SecretKey:     secretKey,
+		WebhookSecret: webhookSecret,
+		Client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// stripeObject: PaymentIntent / Refund のレスポンスのうち使う項目
+type stripeObject struct {
+	ID            string `json:"id"`
+	Amount        int    `json:"amount"`
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	PaymentIntent string `json:"payment_intent"`
+	Error         *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *StripeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.Itoa(req.Amount))
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("capture_method", "manual")
+	form.Set("confirm", "true")
+	form.Set("metadata[order_id]", req.OrderID)
+
+	obj, err := p.post(ctx, "/v1/payment_intents", form, "authorize-"+req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return obj.toPayment(), nil
+}
+
+func (p *StripeProvider) Capture(ctx context.Context, paymentID string) (*Payment, error) {
+	obj, err := p.post(ctx, "/v1/payment_intents/"+url.PathEscape(paymentID)+"/capture", url.Values{}, "capture-"+paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return obj.toPayment(), nil
+}
+
+func (p *StripeProvider) Cancel(ctx context.Context, paymentID string) error {
+	_, err := p.post(ctx, "/v1/payment_intents/"+url.PathEscape(paymentID)+"/cancel", url.Values{}, "cancel-"+paymentID)
+	return err
+}
+
+func (p *StripeProvider) Refund(ctx context.Context, paymentID string, amount int) (*Refund, error) {
+	form := url.Values{}
+	form.Set("payment_intent", paymentID)
+	if amount > 0 {
+		form.Set("amount", strconv.Itoa(amount))
+	}
+
+	obj, err := p.post(ctx, "/v1/refunds", form, "refund-"+paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return &Refund{ID: obj.ID, PaymentID: paymentID, Amount: obj.Amount}, nil
+}
+
+func (p *StripeProvider) VerifyWebhook(payload []byte, signatureHeader string) (*Event, error) {
+	if err := verifySignature(p.WebhookSecret, payload, signatureHeader, time.Now()); err != nil {
+		return nil, err
+	}
+	return parseEvent(payload)
+}
+
+// post: フォーム形式で API を呼び出す（Idempotency-Key 付き）
+func (p *StripeProvider) post(ctx context.Context, path string, form url.Values, idempotencyKey string) (*stripeObject, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj stripeObject
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("stripe: invalid response (%d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if obj.Error != nil {
+			if resp.StatusCode == http.StatusPaymentRequired || obj.Error.Code == "card_declined" {
+				return nil, fmt.Errorf("%w: %s", ErrDeclined, obj.Error.Message)
+			}
+			if resp.StatusCode == http.StatusNotFound {
+				return nil, fmt.Errorf("%w: %s", ErrPaymentNotFound, obj.Error.Message)
+			}
+			return nil, fmt.Errorf("stripe API error (%d): %s", resp.StatusCode, obj.Error.Message)
+		}
+		return nil, fmt.Errorf("stripe API error (%d)", resp.StatusCode)
+	}
+	return &obj, nil
+}
+
+func (o *stripeObject) toPayment() *Payment {
+	status := Status(o.Status)
+	switch o.Status {
+	case "requires_capture":
+		status = StatusAuthorized
+	case "succeeded":
+		status = StatusCaptured
+	case "canceled":
+		status = StatusCancelled
+	}
+	return &Payment{ID: o.ID, Amount: o.Amount, Currency: o.Currency, Status: status}
+}
+
+// parseEvent: Stripe 形式のイベント JSON から必要な項目を取り出す
+func parseEvent(payload []byte) (*Event, error) {
+	var raw struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object stripeObject `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	// charge のイベントは payment_intent に紐づける
+	paymentID := raw.Data.Object.PaymentIntent
+	if paymentID == "" {
+		paymentID = raw.Data.Object.ID
+	}
+	return &Event{ID: raw.ID, Type: raw.Type, PaymentID: paymentID}, nil
+}
@@ -0,0 +1,128 @@
+// Package recommend: いいね履歴からのおすすめと、似ている商品の計算
+// DB にはアクセスせず、DAO から受け取ったデータだけで計算する（フィクスチャでテストできるように）
+package recommend
+
+import (
+	"hackathon-backend/model"
+	"math"
+	"sort"
+)
+
+// Neighbor: ある商品と一緒にいいねされやすい商品
+type Neighbor struct {
+	ItemID string
+	Score  float64
+}
+
+// Index: 商品ごとの近傍（スコアの高い順）
+type Index map[string][]Neighbor
+
+// BuildCoLikeIndex: いいね履歴から商品同士の類似度を求める
+// 類似度は、同じユーザーにいいねされた回数のコサイン類似度 co(i,j) / sqrt(n(i) * n(j))
+// maxPerUser より多くいいねしているユーザーは計算量を抑えるため除く、商品ごとに上位 k 件だけ残す
+func BuildCoLikeIndex(likes []model.Like, k, maxPerUser int) Index {
+	byUser := map[string][]string{}
+	count := map[string]int{}
+	for _, l := range likes {
+		byUser[l.UserID] = append(byUser[l.UserID], l.ItemID)
+		count[l.ItemID]++
+	}
+
+	co := map[string]map[string]int{}
+	for _, items := range byUser {
+		if len(items) < 2 || (maxPerUser > 0 && len(items) > maxPerUser) {
+			continue
+		}
+		for _, a := range items {
+			for _, b := range items {
+				if a == b {
+					continue
+				}
+				if co[a] == nil {
+					co[a] = map[string]int{}
+				}
+				co[a][b]++
+			}
+		}
+	}
+
+	index := Index{}
+	for a, others := range co {
+		neighbors := make([]Neighbor, 0, len(others))
+		for b, n := range others {
+			neighbors = append(neighbors, Neighbor{ItemID: b, Score: float64(n) / math.Sqrt(float64(count[a]*count[b]))})
+		}
+		sortNeighbors(neighbors)
+		if k > 0 && len(neighbors) > k {
+			neighbors = neighbors[:k]
+		}
+		index[a] = neighbors
+	}
+	return index
+}
+
+// Recommend: いいねした商品の近傍のスコアを足し合わせ、スコアの高い順に商品IDを返す
+// eligible が false の商品（売り切れ・自分の出品など）といいね済みの商品は除く
+func (idx Index) Recommend(liked []string, eligible func(itemID string) bool, limit int) []Neighbor {
+	likedSet := make(map[string]bool, len(liked))
+	for _, id := range liked {
+		likedSet[id] = true
+	}
+
+	scores := map[string]float64{}
+	for _, id := range liked {
+		for _, n := range idx[id] {
+			if likedSet[n.ItemID] || !eligible(n.ItemID) {
+				continue
+			}
+			scores[n.ItemID] += n.Score
+		}
+	}
+
+	result := make([]Neighbor, 0, len(scores))
+	for id, score := range scores {
+		result = append(result, Neighbor{ItemID: id, Score: score})
+	}
+	sortNeighbors(result)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// Popular: いいね数の多い順（同数なら新しい順）に商品を並べる（いいね履歴がないユーザー向け）
+func Popular(items []*model.Item, eligible func(itemID string) bool, limit int) []*model.Item {
+	var result []*model.Item
+	for _, item := range items {
+		if eligible(item.ID) {
+			result = append(result, item)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].LikeCount != result[j].LikeCount {
+			return result[i].LikeCount > result[j].LikeCount
+		}
+		return publishedAfter(result[i], result[j])
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// sortNeighbors: スコアの高い順、同点なら ID 順（結果を安定させる）
+func sortNeighbors(ns []Neighbor) {
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].Score != ns[j].Score {
+			return ns[i].Score > ns[j].Score
+		}
+		return ns[i].ItemID < ns[j].ItemID
+	})
+}
+
+func publishedAfter(a, b *model.Item) bool {
+	if a.PublishedAt == nil || b.PublishedAt == nil {
+		return a.PublishedAt != nil
+	}
+	return a.PublishedAt.After(*b.PublishedAt)
+}
@@ -0,0 +1,81 @@
+package recommend
+
+import (
+	"hackathon-backend/model"
+	"testing"
+)
+
+// likesFixture: a と b は一緒にいいねされやすく、c は a と一度だけ
+var likesFixture = []model.Like{
+	{UserID: "u1", ItemID: "a"}, {UserID: "u1", ItemID: "b"},
+	{UserID: "u2", ItemID: "a"}, {UserID: "u2", ItemID: "b"}, {UserID: "u2", ItemID: "c"},
+	{UserID: "u3", ItemID: "b"}, {UserID: "u3", ItemID: "d"},
+	{UserID: "u4", ItemID: "e"},
+}
+
+func allEligible(string) bool { return true }
+
+// TestBuildCoLikeIndex は共起いいねの類似度のテスト
+func TestBuildCoLikeIndex(t *testing.T) {
+	idx := BuildCoLikeIndex(likesFixture, 10, 0)
+
+	if got := idx["a"][0]; got.ItemID != "b" {
+		t.Fatalf("a の最も近い商品 = %s, want b", got.ItemID)
+	}
+	// co(a,b)=2, n(a)=2, n(b)=3 → 2/sqrt(6)
+	if got := idx["a"][0].Score; got < 0.816 || got > 0.817 {
+		t.Errorf("sim(a,b) = %f, want 0.8165", got)
+	}
+	if _, ok := idx["e"]; ok {
+		t.Errorf("1人にしかいいねされていない商品に近傍がある: %v", idx["e"])
+	}
+
+	// 上位 k 件だけ残す
+	if got := len(BuildCoLikeIndex(likesFixture, 1, 0)["b"]); got != 1 {
+		t.Errorf("k=1 の近傍の数 = %d, want 1", got)
+	}
+	// いいねが多すぎるユーザーは除く
+	if got := BuildCoLikeIndex(likesFixture, 10, 2)["c"]; len(got) != 0 {
+		t.Errorf("maxPerUser を超えたユーザーの共起が残っている: %v", got)
+	}
+}
+
+// TestRecommend はおすすめの並び順と除外のテスト
+func TestRecommend(t *testing.T) {
+	idx := BuildCoLikeIndex(likesFixture, 10, 0)
+
+	testCases := []struct {
+		name     string
+		liked    []string
+		eligible func(string) bool
+		want     []string
+	}{
+		{name: "共起の強い順", liked: []string{"a"}, eligible: allEligible, want: []string{"b", "c"}},
+		{name: "いいね済みは除く", liked: []string{"a", "b"}, eligible: allEligible, want: []string{"c", "d"}},
+		{name: "対象外の商品は除く", liked: []string{"a"}, eligible: func(id string) bool { return id != "b" }, want: []string{"c"}},
+		{name: "履歴がなければ空", liked: nil, eligible: allEligible, want: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := idx.Recommend(tc.liked, tc.eligible, 10)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Recommend() = %v, want %v", got, tc.want)
+			}
+			for i, n := range got {
+				if n.ItemID != tc.want[i] {
+					t.Errorf("Recommend()[%d] = %s, want %s", i, n.ItemID, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestPopular は人気順のフォールバックのテスト
+func TestPopular(t *testing.T) {
+	items := []*model.Item{{ID: "x", LikeCount: 1}, {ID: "y", LikeCount: 5}, {ID: "z", LikeCount: 3}}
+	got := Popular(items, func(id string) bool { return id != "z" }, 10)
+	if len(got) != 2 || got[0].ID != "y" || got[1].ID != "x" {
+		t.Errorf("Popular() = %v, want [y x]", got)
+	}
+}
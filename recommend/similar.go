@@ -0,0 +1,112 @@
+package recommend
+
+import (
+	"hackathon-backend/model"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// 似ている商品のスコアの重み（合計 1）
+const (
+	weightTags  = 0.45 // カテゴリ・タグの一致
+	weightText  = 0.35 // 商品名・説明文の文字 bigram の一致
+	weightPrice = 0.20 // 価格の近さ
+)
+
+// priceRatioLimit: 価格がこの倍率以上離れていたら価格の近さは 0
+const priceRatioLimit = 4.0
+
+// Similar: base に似ている商品をスコアの高い順に返す（base 自身と eligible が false の商品は除く）
+func Similar(base *model.Item, candidates []*model.Item, eligible func(*model.Item) bool, limit int) []Neighbor {
+	baseTags := tagSet(base)
+	baseGrams := bigrams(base.Name + " " + base.Description)
+
+	var result []Neighbor
+	for _, item := range candidates {
+		if item.ID == base.ID || !eligible(item) {
+			continue
+		}
+		score := weightTags*jaccard(baseTags, tagSet(item)) +
+			weightText*dice(baseGrams, bigrams(item.Name+" "+item.Description)) +
+			weightPrice*PriceProximity(base.Price, item.Price)
+		if score > 0 {
+			result = append(result, Neighbor{ItemID: item.ID, Score: score})
+		}
+	}
+	sortNeighbors(result)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// PriceProximity: 同じ価格なら 1、priceRatioLimit 倍離れると 0（比で比べるので安い商品同士の差も同じ重み）
+func PriceProximity(a, b int) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	p := 1 - math.Abs(math.Log(float64(a)/float64(b)))/math.Log(priceRatioLimit)
+	return math.Max(p, 0)
+}
+
+// tagSet: カテゴリとタグを正規化して集合にする（カテゴリはタグの1つとして扱う）
+func tagSet(item *model.Item) map[string]bool {
+	set := map[string]bool{}
+	for _, t := range append([]string{item.Category}, item.Tags...) {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			set[t] = true
+		}
+	}
+	return set
+}
+
+// bigrams: 空白・記号を除いた文字 bigram の集合（日本語は単語に区切らずに比べられる）
+func bigrams(text string) map[string]bool {
+	var runes []rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	set := map[string]bool{}
+	for i := 0; i+1 < len(runes); i++ {
+		set[string(runes[i:i+2])] = true
+	}
+	return set
+}
+
+func intersection(a, b map[string]bool) int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	n := 0
+	for k := range a {
+		if b[k] {
+			n++
+		}
+	}
+	return n
+}
+
+// jaccard: |A∩B| / |A∪B|
+func jaccard(a, b map[string]bool) float64 {
+	n := intersection(a, b)
+	if union := len(a) + len(b) - n; union > 0 {
+		return float64(n) / float64(union)
+	}
+	return 0
+}
+
+// dice: 2|A∩B| / (|A|+|B|)
+func dice(a, b map[string]bool) float64 {
+	if len(a)+len(b) == 0 {
+		return 0
+	}
+	return 2 * float64(intersection(a, b)) / float64(len(a)+len(b))
+}
+
+// TextSimilarity: 2つの文字列の文字 bigram の一致度（0〜1）
+func TextSimilarity(a, b string) float64 {
+	return dice(bigrams(a), bigrams(b))
+}
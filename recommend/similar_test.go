@@ -0,0 +1,51 @@
+package recommend
+
+import (
+	"hackathon-backend/model"
+	"testing"
+)
+
+// TestPriceProximity は価格の近さのテスト
+func TestPriceProximity(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b int
+		want float64
+	}{
+		{name: "同じ価格", a: 3000, b: 3000, want: 1},
+		{name: "2倍は半分", a: 1000, b: 2000, want: 0.5},
+		{name: "4倍以上は0", a: 1000, b: 5000, want: 0},
+		{name: "価格未設定は0", a: 0, b: 1000, want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := PriceProximity(tc.a, tc.b); got < tc.want-1e-9 || got > tc.want+1e-9 {
+				t.Errorf("PriceProximity(%d, %d) = %f, want %f", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSimilar は似ている商品の並び順のテスト
+func TestSimilar(t *testing.T) {
+	base := &model.Item{ID: "base", Name: "ジャンク iPhone 8 画面割れ", Category: "スマートフォン", Tags: []string{"Apple", "ジャンク"}, Price: 5000}
+	candidates := []*model.Item{
+		base,
+		{ID: "same", Name: "iPhone 8 ジャンク バッテリー劣化", Category: "スマートフォン", Tags: []string{"apple"}, Price: 6000},
+		{ID: "category", Name: "Pixel 4a", Category: "スマートフォン", Price: 8000},
+		{ID: "unrelated", Name: "木製チェア", Category: "家具", Price: 50000},
+		{ID: "sold", Name: "ジャンク iPhone 8", Category: "スマートフォン", Price: 5000, SoldOut: true},
+	}
+
+	got := Similar(base, candidates, func(i *model.Item) bool { return !i.SoldOut }, 10)
+	want := []string{"same", "category"}
+	if len(got) != len(want) {
+		t.Fatalf("Similar() = %v, want %v", got, want)
+	}
+	for i, n := range got {
+		if n.ItemID != want[i] {
+			t.Errorf("Similar()[%d] = %s, want %s", i, n.ItemID, want[i])
+		}
+	}
+}
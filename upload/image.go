@@ -0,0 +1,117 @@
+// Package upload: 画像のアップロード（形式の判定、大きさの制限、サムネイルの作成）と保存先
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+var (
+	// ErrUnsupportedType: 受け付けない形式（中身から判定するので、拡張子や Content-Type を偽っても通らない）
+	ErrUnsupportedType = errors.New("unsupported file type (jpeg, png or gif only)")
+	// ErrTooLarge: ファイルサイズ、または画素数が上限を超えている
+	ErrTooLarge = errors.New("file is too large")
+)
+
+const (
+	// ThumbnailSize: サムネイルの長辺
+	ThumbnailSize = 320
+	// maxPixels: 展開すると巨大になる画像を避けるための画素数の上限
+	maxPixels = 25_000_000
+)
+
+// allowedTypes: 受け付ける形式（中身から判定した MIME タイプ）
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Image: 検証済みの画像
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+	// Thumbnail: 長辺 ThumbnailSize の JPEG
+	Thumbnail []byte
+}
+
+// ProcessImage: 中身から形式を判定し、大きさを確かめてサムネイルを作る
+func ProcessImage(data []byte, maxBytes int) (*Image, error) {
+	if len(data) > maxBytes {
+		return nil, ErrTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, Thumbnail(src, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return &Image{ContentType: contentType, Data: data, Width: cfg.Width, Height: cfg.Height, Thumbnail: thumb.Bytes()}, nil
+}
+
+// Thumbnail: 長辺が maxSide 以下になるよう縮小し（拡大はしない）、透過部分は白にする
+// 縮小は元の画素の平均を取る
+func Thumbnail(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			tw, th = maxSide, max(1, h*maxSide/w)
+		} else {
+			tw, th = max(1, w*maxSide/h), maxSide
+		}
+	}
+
+	scaled := image.NewRGBA64(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			scaled.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+
+	dst := image.NewRGBA(scaled.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), scaled, image.Point{}, draw.Over)
+	return dst
+}
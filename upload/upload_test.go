@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// encodePNG: w×h の赤い PNG を作る
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestProcessImage は画像の形式と大きさを読み取り、サムネイルを作ることを確認する
+func TestProcessImage(t *testing.T) {
+	data := encodePNG(t, 800, 400)
+
+	img, err := ProcessImage(data, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.ContentType != "image/png" || img.Width != 800 || img.Height != 400 {
+		t.Errorf("ProcessImage() = %s %dx%d, want image/png 800x400", img.ContentType, img.Width, img.Height)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(img.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail is not a jpeg: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != ThumbnailSize || b.Dy() != ThumbnailSize/2 {
+		t.Errorf("thumbnail size = %dx%d, want %dx%d", b.Dx(), b.Dy(), ThumbnailSize, ThumbnailSize/2)
+	}
+}
+
+// TestProcessImageRejects は画像でないものと大きすぎるものを受け付けないことを確認する
+func TestProcessImageRejects(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		maxBytes int
+		want     error
+	}{
+		{name: "画像でない", data: []byte("<html><script>alert(1)</script></html>"), maxBytes: 1 << 20, want: ErrUnsupportedType},
+		{name: "PNG の先頭だけ", data: []byte("\x89PNG\r\n\x1a\n"), maxBytes: 1 << 20, want: ErrUnsupportedType},
+		{name: "サイズ超過", data: encodePNG(t, 10, 10), maxBytes: 10, want: ErrTooLarge},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ProcessImage(tc.data, tc.maxBytes); !errors.Is(err, tc.want) {
+				t.Errorf("ProcessImage() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+// TestThumbnailDoesNotUpscale は小さい画像を拡大せず、透過部分を白にすることを確認する
+func TestThumbnailDoesNotUpscale(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	if b := Thumbnail(src, ThumbnailSize).Bounds(); b.Dx() != 40 || b.Dy() != 20 {
+		t.Errorf("Thumbnail() = %dx%d, want 40x20", b.Dx(), b.Dy())
+	}
+	// 透過部分は白になる
+	if c := Thumbnail(src, ThumbnailSize).RGBAAt(0, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("transparent pixel = %v, want white", c)
+	}
+}
+
+// TestDirStore はディレクトリへの保存・読み込み・削除と、ディレクトリの外に書けないことを確認する
+func TestDirStore(t *testing.T) {
+	s := NewDirStore(t.TempDir())
+	if err := s.Put("attachments/a1", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get("attachments/a1")
+	if err != nil || string(got) != "data" {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+	if err := s.Delete("attachments/a1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("attachments/a1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := s.Put("../escape", []byte("x")); err == nil {
+		t.Error("Put() outside the directory succeeded")
+	}
+}
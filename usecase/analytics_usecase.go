@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"time"
+)
+
+// ErrViewerRequired: 閲覧の重複を判定するため user_id か session_id が必要
+var ErrViewerRequired = errors.New("user_id or session_id is required")
+
+// dayFormat: 日次集計の日付（created_at と同じく UTC で数える）
+const dayFormat = "2006-01-02"
+
+// AnalyticsUsecase: 閲覧の記録と出品者向けの分析を担当
+type AnalyticsUsecase struct {
+	AnalyticsDAO *dao.AnalyticsDAO
+	ItemDAO      *dao.ItemDAO
+}
+
+func NewAnalyticsUsecase(analyticsDAO *dao.AnalyticsDAO, itemDAO *dao.ItemDAO) *AnalyticsUsecase {
+	return &AnalyticsUsecase{AnalyticsDAO: analyticsDAO, ItemDAO: itemDAO}
+}
+
+// RecordView: 商品の閲覧を記録する（出品者本人の閲覧は数えない、同じ人の同じ日の閲覧は1回）
+func (uc *AnalyticsUsecase) RecordView(itemID, userID, sessionID string) (bool, error) {
+	viewer := ""
+	switch {
+	case userID != "":
+		viewer = "u:" + userID
+	case sessionID != "":
+		viewer = "s:" + sessionID
+	default:
+		return false, ErrViewerRequired
+	}
+
+	item, err := uc.ItemDAO.GetByID(itemID)
+	if err != nil {
+		return false, err
+	}
+	if item == nil || item.Hidden {
+		return false, ErrItemNotFound
+	}
+	if userID != "" && userID == item.SellerID {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	return uc.AnalyticsDAO.RecordView(item.ID, viewer, now.Format(dayFormat), now)
+}
+
+// Rollup: 前日と当日の日次集計を作り直す（定期実行用、当日分は途中経過）
+func (uc *AnalyticsUsecase) Rollup() error {
+	now := time.Now().UTC()
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		if _, err := uc.AnalyticsDAO.Rollup(day.Format(dayFormat)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SellerAnalytics: 出品者の直近 days 日間の閲覧・いいね・問い合わせ・購入
+func (uc *AnalyticsUsecase) SellerAnalytics(sellerID string, days int) (*model.SellerAnalytics, error) {
+	if sellerID == "" {
+		return nil, ErrNotAllowed
+	}
+	now := time.Now().UTC()
+	from, to := now.AddDate(0, 0, -(days-1)).Format(dayFormat), now.Format(dayFormat)
+
+	rows, names, err := uc.AnalyticsDAO.ListSellerStats(sellerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return model.SummarizeStats(rows, names, from, to), nil
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/upload"
+	"log"
+	"time"
+)
+
+// ErrAttachmentNotFound: 添付が見つからない
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// AttachmentUsecase: メッセージに添付する画像のアップロードと取得を担当
+// 画像はやりとりの当事者だけが取得できる（送信前はアップロードした本人だけ）
+type AttachmentUsecase struct {
+	AttachmentDAO *dao.AttachmentDAO
+	Chat          *ChatUsecase
+	Store         upload.Store
+	// MaxBytes: 1枚の画像の大きさの上限
+	MaxBytes int
+}
+
+func NewAttachmentUsecase(attachmentDAO *dao.AttachmentDAO, chat *ChatUsecase, store upload.Store, maxBytes int) *AttachmentUsecase {
+	return &AttachmentUsecase{AttachmentDAO: attachmentDAO, Chat: chat, Store: store, MaxBytes: maxBytes}
+}
+
+func attachmentKey(id string) string          { return "attachments/" + id }
+func attachmentThumbnailKey(id string) string { return "attachments/" + id + "_thumb" }
+
+// Upload: やりとりに添付する画像をアップロードする（メッセージの送信時に attachment_ids で指定する）
+func (uc *AttachmentUsecase) Upload(conversationID, userID string, data []byte) (*model.Attachment, error) {
+	if _, err := uc.Chat.Conversation(conversationID, userID); err != nil {
+		return nil, err
+	}
+	img, err := upload.ProcessImage(data, uc.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &model.Attachment{
+		ID:             model.NewID(),
+		ConversationID: conversationID,
+		UploaderID:     userID,
+		ContentType:    img.ContentType,
+		Size:           len(img.Data),
+		Width:          img.Width,
+		Height:         img.Height,
+		HasThumbnail:   len(img.Thumbnail) > 0,
+		CreatedAt:      time.Now(),
+	}
+	if err := uc.Store.Put(attachmentKey(a.ID), img.Data); err != nil {
+		return nil, err
+	}
+	if a.HasThumbnail {
+		if err := uc.Store.Put(attachmentThumbnailKey(a.ID), img.Thumbnail); err != nil {
+			uc.deleteFiles(a.ID)
+			return nil, err
+		}
+	}
+	if err := uc.AttachmentDAO.Insert(a); err != nil {
+		uc.deleteFiles(a.ID)
+		return nil, err
+	}
+	a.SetURLs()
+	return a, nil
+}
+
+// Open: 画像（thumbnail ならサムネイル）の中身と Content-Type を返す
+func (uc *AttachmentUsecase) Open(id, userID string, thumbnail bool) ([]byte, string, error) {
+	a, err := uc.AttachmentDAO.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if a == nil {
+		return nil, "", ErrAttachmentNotFound
+	}
+	c, err := uc.Chat.Conversation(a.ConversationID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !a.CanView(c, userID) {
+		return nil, "", ErrNotAllowed
+	}
+	if a.MessageID != "" {
+		// 送信を取り消したメッセージの画像は当事者にも見せない
+		msg, err := uc.Chat.MessageDAO.GetByID(a.MessageID)
+		if err != nil {
+			return nil, "", err
+		}
+		if msg == nil || msg.Unsent {
+			return nil, "", ErrAttachmentNotFound
+		}
+	}
+
+	key, contentType := attachmentKey(a.ID), a.ContentType
+	if thumbnail && a.HasThumbnail {
+		key, contentType = attachmentThumbnailKey(a.ID), "image/jpeg"
+	}
+	data, err := uc.Store.Get(key)
+	if errors.Is(err, upload.ErrNotFound) {
+		return nil, "", ErrAttachmentNotFound
+	}
+	return data, contentType, err
+}
+
+// CleanupUnattached: アップロードから maxAge 以上たっても送信されなかった画像を削除する
+func (uc *AttachmentUsecase) CleanupUnattached(maxAge time.Duration) (int, error) {
+	list, err := uc.AttachmentDAO.ListUnattached(time.Now().Add(-maxAge), 500)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, a := range list {
+		ok, err := uc.AttachmentDAO.DeleteUnattached(a.ID)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			uc.deleteFiles(a.ID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// deleteFiles: 保存先の画像とサムネイルを消す（失敗してもログだけ）
+func (uc *AttachmentUsecase) deleteFiles(id string) {
+	for _, key := range []string{attachmentKey(id), attachmentThumbnailKey(id)} {
+		if err := uc.Store.Delete(key); err != nil {
+			log.Printf("fail: delete %s, %v\n", key, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"time"
+)
+
+var (
+	// ErrBlocked: ブロックしている（されている）相手とはやりとりできない
+	ErrBlocked         = errors.New("interaction with this user is blocked")
+	ErrCannotBlockSelf = errors.New("cannot block yourself")
+)
+
+// BlockUsecase: ユーザーのブロックと、ブロックによる制限の判定を担当
+// メッセージ・いいね・値下げ交渉・購入の各ユースケースは、相手とのやりとりの前に CheckBetween / CheckItem を呼ぶ
+type BlockUsecase struct {
+	BlockDAO *dao.BlockDAO
+	UserDAO  *dao.UserDAO
+	// OnBlock: ブロックした直後に呼ぶ（配信中のチャットを閉じるため。nil なら何もしない）
+	OnBlock func(blockerID, blockedID string)
+}
+
+func NewBlockUsecase(blockDAO *dao.BlockDAO, userDAO *dao.UserDAO) *BlockUsecase {
+	return &BlockUsecase{BlockDAO: blockDAO, UserDAO: userDAO}
+}
+
+// Block: blockerID が blockedID をブロックする
+func (uc *BlockUsecase) Block(blockerID, blockedID string) error {
+	if blockerID == "" {
+		return ErrNotAllowed
+	}
+	if blockerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+	user, err := uc.UserDAO.GetUserByID(blockedID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if err := uc.BlockDAO.Block(blockerID, blockedID, time.Now()); err != nil {
+		return err
+	}
+	if uc.OnBlock != nil {
+		uc.OnBlock(blockerID, blockedID)
+	}
+	return nil
+}
+
+// Unblock: ブロックを解除する（ブロックしていなくてもエラーにしない）
+func (uc *BlockUsecase) Unblock(blockerID, blockedID string) error {
+	if blockerID == "" {
+		return ErrNotAllowed
+	}
+	_, err := uc.BlockDAO.Unblock(blockerID, blockedID)
+	return err
+}
+
+// List: ブロックしているユーザーの一覧
+func (uc *BlockUsecase) List(blockerID string) ([]*model.Block, error) {
+	if blockerID == "" {
+		return nil, ErrNotAllowed
+	}
+	return uc.BlockDAO.ListByBlocker(blockerID)
+}
+
+// CheckBetween: 2人の間にブロックがあれば ErrBlocked（どちらがブロックしていても同じ）
+func (uc *BlockUsecase) CheckBetween(a, b string) error {
+	if a == "" || b == "" || a == b {
+		return nil
+	}
+	blocked, err := uc.BlockDAO.IsBlockedBetween(a, b)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// BlockedWith: userID とのあいだにブロックがあるユーザーの集合（一覧から除くため。userID が空なら空）
+func (uc *BlockUsecase) BlockedWith(userID string) (map[string]bool, error) {
+	blocked := map[string]bool{}
+	if userID == "" {
+		return blocked, nil
+	}
+	ids, err := uc.BlockDAO.ListBlockedWith(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
+
+// CheckItem: userID が item の出品者とやりとりできるか
+func (uc *BlockUsecase) CheckItem(userID string, item *model.Item) error {
+	return uc.CheckBetween(userID, item.SellerID)
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/category"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"log"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCategoryHasCycle  = errors.New("parent cannot be the category itself or its descendant")
+	ErrParentNotFound    = errors.New("parent category not found")
+	ErrCannotDeleteOther = errors.New("the fallback category cannot be deleted")
+)
+
+// CategoryUsecase: カテゴリの木の管理と、自由入力のカテゴリ名の対応付けを担当
+// 木はメモリに持ち、変更後と定期的な Reload で読み直す
+type CategoryUsecase struct {
+	CategoryDAO *dao.CategoryDAO
+	ItemDAO     *dao.ItemDAO
+
+	mu   sync.RWMutex
+	tree *category.Tree
+}
+
+func NewCategoryUsecase(categoryDAO *dao.CategoryDAO, itemDAO *dao.ItemDAO) *CategoryUsecase {
+	return &CategoryUsecase{CategoryDAO: categoryDAO, ItemDAO: itemDAO, tree: category.NewTree(nil)}
+}
+
+// Init: 初回は初期カテゴリを登録し、木を読み込んで、既存の商品のカテゴリを対応付ける
+func (uc *CategoryUsecase) Init() error {
+	seed, err := category.Seed()
+	if err != nil {
+		return err
+	}
+	if seeded, err := uc.CategoryDAO.SeedIfEmpty(seed); err != nil {
+		return err
+	} else if seeded {
+		log.Printf("Seeded %d categories", len(seed))
+	}
+	if err := uc.Reload(); err != nil {
+		return err
+	}
+	return uc.NormalizeItems()
+}
+
+// Reload: DB からカテゴリの木を読み直す（定期実行用）
+func (uc *CategoryUsecase) Reload() error {
+	list, err := uc.CategoryDAO.List()
+	if err != nil {
+		return err
+	}
+	tree := category.NewTree(list)
+
+	uc.mu.Lock()
+	uc.tree = tree
+	uc.mu.Unlock()
+	return nil
+}
+
+// Tree: 現在のカテゴリの木
+func (uc *CategoryUsecase) Tree() *category.Tree {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.tree
+}
+
+// Match: 自由入力のカテゴリ名を最も近いカテゴリの slug にする（空なら空のまま）
+func (uc *CategoryUsecase) Match(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	return uc.Tree().Match(text)
+}
+
+// Descendants: カテゴリとその子孫の slug（見つからなければ空）
+func (uc *CategoryUsecase) Descendants(slug string) []string {
+	return uc.Tree().Descendants(slug)
+}
+
+// NormalizeItems: カテゴリの木にない値（以前の自由入力など）を持つ商品を、最も近いカテゴリに付け替える
+func (uc *CategoryUsecase) NormalizeItems() error {
+	values, err := uc.ItemDAO.DistinctCategories()
+	if err != nil {
+		return err
+	}
+	tree := uc.Tree()
+	for _, v := range values {
+		if tree.Get(v) != nil {
+			continue
+		}
+		slug := tree.Match(v)
+		if slug == "" {
+			continue
+		}
+		n, err := uc.ItemDAO.RenameCategory(v, slug)
+		if err != nil {
+			return err
+		}
+		log.Printf("Mapped category %q -> %s (%d items)", v, slug, n)
+	}
+	return nil
+}
+
+// Create: カテゴリを追加する
+func (uc *CategoryUsecase) Create(c *model.Category) error {
+	if err := uc.validate(c); err != nil {
+		return err
+	}
+	if err := uc.CategoryDAO.Insert(c); err != nil {
+		return err
+	}
+	return uc.Reload()
+}
+
+// Update: カテゴリの親・名前・別名・並び順を変える
+func (uc *CategoryUsecase) Update(c *model.Category) error {
+	if uc.Tree().Get(c.Slug) == nil {
+		return dao.ErrCategoryNotFound
+	}
+	if err := uc.validate(c); err != nil {
+		return err
+	}
+	if err := uc.CategoryDAO.Update(c); err != nil {
+		return err
+	}
+	return uc.Reload()
+}
+
+// Delete: カテゴリを削除する（子カテゴリは親へ繰り上げ、商品は親カテゴリかその他へ移す）
+func (uc *CategoryUsecase) Delete(slug string) error {
+	if slug == category.FallbackSlug {
+		return ErrCannotDeleteOther
+	}
+	c := uc.Tree().Get(slug)
+	if c == nil {
+		return dao.ErrCategoryNotFound
+	}
+	itemCategory := c.ParentSlug
+	if itemCategory == "" {
+		itemCategory = category.FallbackSlug
+	}
+	if err := uc.CategoryDAO.Delete(slug, c.ParentSlug, itemCategory); err != nil {
+		return err
+	}
+	return uc.Reload()
+}
+
+// validate: 入力と、親子関係が循環しないことを確認する
+func (uc *CategoryUsecase) validate(c *model.Category) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if c.ParentSlug == "" {
+		return nil
+	}
+	tree := uc.Tree()
+	if tree.Get(c.ParentSlug) == nil {
+		return ErrParentNotFound
+	}
+	for _, s := range tree.Descendants(c.Slug) {
+		if s == c.ParentSlug {
+			return ErrCategoryHasCycle
+		}
+	}
+	return nil
+}
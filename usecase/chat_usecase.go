@@ -0,0 +1,585 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hackathon-backend/chathub"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/moderation"
+	"log"
+	"time"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrConversationRequired = errors.New("conversation_id is required")
+	ErrCannotMessageSelf    = errors.New("cannot start a conversation about your own item")
+	ErrMessageNotFound      = errors.New("message not found")
+)
+
+// MessageRejectedError: 審査でメッセージの送信を拒否した
+type MessageRejectedError struct {
+	Result *moderation.Result
+}
+
+func (e *MessageRejectedError) Error() string {
+	return fmt.Sprintf("message rejected by moderation (%s)", e.Result.Reason)
+}
+
+// ChatUsecase: 商品ごと・購入希望者ごとのやりとりと、メッセージの送信・配信を担当
+// やりとりのメッセージを読み書きできるのは当事者（購入希望者と出品者）だけ
+type ChatUsecase struct {
+	ConversationDAO *dao.ConversationDAO
+	MessageDAO      *dao.MessageDAO
+	ItemDAO         *dao.ItemDAO
+	AttachmentDAO   *dao.AttachmentDAO
+	// ReportDAO: 審査で際どいと判定したメッセージをモデレーターの確認待ちに入れる
+	ReportDAO *dao.ReportDAO
+	// Hub: 新しいメッセージをリアルタイムに配信する
+	Hub *chathub.Hub
+	// Moderator: 送信するメッセージの審査
+	Moderator *moderation.Moderator
+	// Blocks: ブロックした（された）相手とのやりとりは隠し、新しく始めさせない
+	Blocks *BlockUsecase
+	// EditWindow: 送信後、編集・送信取り消しができる期間
+	EditWindow time.Duration
+}
+
+func NewChatUsecase(conversationDAO *dao.ConversationDAO, messageDAO *dao.MessageDAO, itemDAO *dao.ItemDAO, attachmentDAO *dao.AttachmentDAO,
+	reportDAO *dao.ReportDAO, hub *chathub.Hub, moderator *moderation.Moderator, blocks *BlockUsecase, editWindow time.Duration) *ChatUsecase {
+	return &ChatUsecase{ConversationDAO: conversationDAO, MessageDAO: messageDAO, ItemDAO: itemDAO, AttachmentDAO: attachmentDAO,
+		ReportDAO: reportDAO, Hub: hub, Moderator: moderator, Blocks: blocks, EditWindow: editWindow}
+}
+
+// StartConversation: 購入希望者が商品の出品者とのやりとりを始める（既にあればそれを返す）
+func (uc *ChatUsecase) StartConversation(itemID, buyerID string) (*model.Conversation, error) {
+	if buyerID == "" {
+		return nil, ErrNotAllowed
+	}
+	if existing, err := uc.ConversationDAO.GetByItemAndBuyer(itemID, buyerID); err != nil || existing != nil {
+		if err == nil {
+			err = uc.Blocks.CheckBetween(existing.BuyerID, existing.SellerID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	item, err := uc.ItemDAO.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.Hidden || item.Status == model.ItemDraft || item.Status == model.ItemWithdrawn {
+		return nil, ErrItemNotFound
+	}
+	if item.SellerID == buyerID {
+		return nil, ErrCannotMessageSelf
+	}
+	if err := uc.Blocks.CheckItem(buyerID, item); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return uc.ConversationDAO.GetOrCreate(&model.Conversation{
+		ID:        model.NewID(),
+		ItemID:    item.ID,
+		BuyerID:   buyerID,
+		SellerID:  item.SellerID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// Inbox: ユーザーのやりとりの一覧（新しくメッセージが届いた順）
+func (uc *ChatUsecase) Inbox(userID string, limit int) ([]*model.Conversation, error) {
+	if userID == "" {
+		return nil, ErrNotAllowed
+	}
+	list, err := uc.ConversationDAO.ListByUser(userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range list {
+		c.UnreadCount = c.UnreadFor(userID)
+	}
+	return list, nil
+}
+
+// UnreadSummary: ユーザーの未読メッセージの合計と、未読のあるやりとりの数
+type UnreadSummary struct {
+	Total         int `json:"total"`
+	Conversations int `json:"conversations"`
+}
+
+// Unread: ユーザーの未読数
+func (uc *ChatUsecase) Unread(userID string) (*UnreadSummary, error) {
+	if userID == "" {
+		return nil, ErrNotAllowed
+	}
+	total, conversations, err := uc.ConversationDAO.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &UnreadSummary{Total: total, Conversations: conversations}, nil
+}
+
+// MarkRead: seq 番目のメッセージまでを既読にする（seq が 0 ならやりとりのすべて）
+// 相手には既読になったことをリアルタイムに知らせる
+func (uc *ChatUsecase) MarkRead(conversationID, userID string, seq int) (*model.Conversation, error) {
+	c, err := uc.Conversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if seq <= 0 || seq > c.MessageSeq {
+		seq = c.MessageSeq
+	}
+	if seq > c.ReadSeqOf(userID) {
+		if err := uc.ConversationDAO.MarkRead(conversationID, userID, seq); err != nil {
+			return nil, err
+		}
+		uc.publishRead(conversationID, userID, seq)
+	}
+	return uc.Conversation(conversationID, userID)
+}
+
+// Conversation: 当事者であればやりとりを返す（当事者の間にブロックがあれば隠す）
+func (uc *ChatUsecase) Conversation(id, userID string) (*model.Conversation, error) {
+	c, err := uc.ConversationDAO.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrConversationNotFound
+	}
+	if !c.IsParticipant(userID) {
+		return nil, ErrNotAllowed
+	}
+	if err := uc.CheckOpen(c); err != nil {
+		return nil, err
+	}
+	c.UnreadCount = c.UnreadFor(userID)
+	return c, nil
+}
+
+// CheckOpen: 当事者のどちらかがブロックしていれば、やりとりは見えないものとして ErrConversationNotFound
+// 配信中のストリームでも、イベントを送る前に確かめ直すために使う
+func (uc *ChatUsecase) CheckOpen(c *model.Conversation) error {
+	if err := uc.Blocks.CheckBetween(c.BuyerID, c.SellerID); errors.Is(err, ErrBlocked) {
+		return ErrConversationNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Messages: やりとりのメッセージを最大 limit 件（当事者のみ。相手が読んだかどうかつき）
+// before・after はメッセージ ID のカーソルで、どちらもなければ最新のメッセージ
+func (uc *ChatUsecase) Messages(conversationID, userID, before, after string, limit int) (*model.MessagePage, error) {
+	if before != "" && after != "" {
+		return nil, fmt.Errorf("%w: before and after cannot be combined", model.ErrInvalidCursor)
+	}
+	c, err := uc.Conversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	msgs, more, err := uc.MessageDAO.ListPage(conversationID, before, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.loadAttachments(msgs); err != nil {
+		return nil, err
+	}
+	c.SetReadStatus(msgs)
+
+	page := &model.MessagePage{Messages: msgs, After: after}
+	if msgs == nil {
+		page.Messages = []*model.Message{}
+	}
+	if len(msgs) > 0 {
+		page.After = msgs[len(msgs)-1].ID
+		// after で新しい側を読んでいるときは、古い側には常にメッセージがある
+		if more || after != "" {
+			page.Before = msgs[0].ID
+		}
+	}
+	return page, nil
+}
+
+// MissedMessages: afterID のメッセージより後のメッセージ（リアルタイム配信の再接続用。当事者の確認は呼び出し側で行う）
+func (uc *ChatUsecase) MissedMessages(conversationID, afterID string) ([]*model.Message, error) {
+	msgs, err := uc.MessageDAO.ListAfter(conversationID, afterID)
+	if err != nil {
+		return nil, err
+	}
+	return msgs, uc.loadAttachments(msgs)
+}
+
+// loadAttachments: メッセージに添付を読み込む（送信を取り消したメッセージには付けない）
+func (uc *ChatUsecase) loadAttachments(msgs []*model.Message) error {
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	byMessage, err := uc.AttachmentDAO.ListByMessages(ids)
+	if err != nil {
+		return err
+	}
+	for _, m := range msgs {
+		if !m.Unsent {
+			m.Attachments = byMessage[m.ID]
+		}
+	}
+	return nil
+}
+
+// Post: メッセージを送る（attachmentIDs は先にアップロードした画像）
+// conversationID が空なら、送信者を購入希望者として itemID のやりとりに送る。
+// 審査で拒否したら MessageRejectedError、際どいものは送信したうえでモデレーターの確認待ちに入れる
+func (uc *ChatUsecase) Post(conversationID, itemID, senderID, content string, attachmentIDs []string) (*model.Message, error) {
+	if err := model.ValidateMessage(content, len(attachmentIDs)); err != nil {
+		return nil, err
+	}
+
+	var c *model.Conversation
+	var err error
+	if conversationID != "" {
+		c, err = uc.Conversation(conversationID, senderID)
+	} else if itemID != "" {
+		c, err = uc.StartConversation(itemID, senderID)
+	} else {
+		return nil, ErrConversationRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := uc.attachmentsFor(c, senderID, attachmentIDs)
+	if err != nil {
+		return nil, err
+	}
+	return uc.send(c, senderID, content, attachments)
+}
+
+// send: 本文を審査してからメッセージを保存・配信する（拒否したものは保存しない）
+func (uc *ChatUsecase) send(c *model.Conversation, senderID, content string, attachments []*model.Attachment) (*model.Message, error) {
+	verdict, err := uc.moderate(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// DATETIME(6) に合わせてマイクロ秒に切り捨て、保存した値と返す値をそろえる
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	msg := &model.Message{
+		ID:             model.NewID(),
+		ConversationID: c.ID,
+		ItemID:         c.ItemID,
+		SenderID:       senderID,
+		Content:        content,
+		CreatedAt:      now,
+		Attachments:    attachments,
+	}
+	if err := uc.MessageDAO.Insert(msg, now); err != nil {
+		return nil, err
+	}
+	if verdict.Verdict == moderation.Flag {
+		uc.flagForReview(msg, verdict, now)
+	}
+	uc.publish(msg)
+	return msg, nil
+}
+
+// moderate: 本文を審査する（拒否なら MessageRejectedError。本文が空なら審査しない）
+func (uc *ChatUsecase) moderate(content string) (*moderation.Result, error) {
+	if content == "" {
+		return &moderation.Result{Verdict: moderation.Allow}, nil
+	}
+	verdict, err := uc.Moderator.Check(context.Background(), content)
+	if err != nil {
+		return nil, err
+	}
+	if verdict.Verdict == moderation.Block {
+		return nil, &MessageRejectedError{Result: verdict}
+	}
+	return verdict, nil
+}
+
+// Edit: 送信者が EditWindow 以内のメッセージの本文を書き換える（前の本文はモデレーター向けの履歴に残す）
+// 書き換えた本文も送信時と同じように審査する
+func (uc *ChatUsecase) Edit(messageID, userID, content string) (*model.Message, error) {
+	msg, c, err := uc.modifiableMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.loadAttachments([]*model.Message{msg}); err != nil {
+		return nil, err
+	}
+	if err := model.ValidateMessage(content, len(msg.Attachments)); err != nil {
+		return nil, err
+	}
+	if content == msg.Content {
+		return msg, nil
+	}
+
+	verdict, err := uc.moderate(content)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if err := uc.MessageDAO.Edit(msg.ID, content, now); err != nil {
+		return nil, err
+	}
+	msg.Content = content
+	msg.EditedAt = &now
+	c.SetReadStatus([]*model.Message{msg})
+	if verdict.Verdict == moderation.Flag {
+		uc.flagForReview(msg, verdict, now)
+	}
+	uc.publishChange(model.ChatEventEdit, msg)
+	return msg, nil
+}
+
+// Unsend: 送信者が EditWindow 以内のメッセージの送信を取り消す（当事者には取り消した跡だけが残る）
+func (uc *ChatUsecase) Unsend(messageID, userID string) (*model.Message, error) {
+	msg, c, err := uc.modifiableMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.MessageDAO.Unsend(msg.ID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	msg.Tombstone()
+	c.SetReadStatus([]*model.Message{msg})
+	uc.publishChange(model.ChatEventUnsend, msg)
+	return msg, nil
+}
+
+// modifiableMessage: userID が編集・送信取り消しできるメッセージと、そのやりとり
+func (uc *ChatUsecase) modifiableMessage(messageID, userID string) (*model.Message, *model.Conversation, error) {
+	msg, err := uc.MessageDAO.GetByID(messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg == nil || msg.Hidden || msg.ConversationID == "" {
+		return nil, nil, ErrMessageNotFound
+	}
+	c, err := uc.Conversation(msg.ConversationID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.SenderID != userID {
+		return nil, nil, ErrNotAllowed
+	}
+	if err := msg.CheckModifiable(time.Now(), uc.EditWindow); err != nil {
+		return nil, nil, err
+	}
+	return msg, c, nil
+}
+
+// MessageHistory: モデレーター向けのメッセージと本文の履歴（送信取り消し・非表示のものも含む）
+type MessageHistory struct {
+	Message   *model.Message           `json:"message"`
+	Revisions []*model.MessageRevision `json:"revisions"`
+}
+
+// History: メッセージの編集・送信取り消しの履歴（管理者の確認は呼び出し側で行う）
+func (uc *ChatUsecase) History(messageID string) (*MessageHistory, error) {
+	msg, err := uc.MessageDAO.GetByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, ErrMessageNotFound
+	}
+	byMessage, err := uc.AttachmentDAO.ListByMessages([]string{msg.ID})
+	if err != nil {
+		return nil, err
+	}
+	msg.Attachments = byMessage[msg.ID]
+	revisions, err := uc.MessageDAO.ListRevisions(msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []*model.MessageRevision{}
+	}
+	return &MessageHistory{Message: msg, Revisions: revisions}, nil
+}
+
+// purgeBatchSize: 保持期限切れのやりとりを一度に取り出す件数
+const purgeBatchSize = 100
+
+// PurgeClosed: 終わったやりとりのうち、最後の動きから retention 以上たったものをメッセージごと削除する
+// 期限切れのものがなくなるまで purgeBatchSize 件ずつ繰り返す
+func (uc *ChatUsecase) PurgeClosed(retention time.Duration) (int, error) {
+	before := time.Now().Add(-retention)
+	purged := 0
+	for {
+		ids, err := uc.ConversationDAO.ListClosedBefore(before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		for _, id := range ids {
+			n, err := uc.ConversationDAO.Purge(id)
+			if err != nil {
+				return purged, err
+			}
+			purged += n
+		}
+		if len(ids) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// attachmentsFor: 送信者がこのやりとりにアップロードし、まだ送っていない添付
+// （保存時にも同じ条件で結びつけるので、同時に同じ添付を送っても二重にはならない）
+func (uc *ChatUsecase) attachmentsFor(c *model.Conversation, senderID string, ids []string) ([]*model.Attachment, error) {
+	var list []*model.Attachment
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		a, err := uc.AttachmentDAO.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if a == nil || a.ConversationID != c.ID || a.UploaderID != senderID || a.MessageID != "" {
+			return nil, dao.ErrAttachmentUnavailable
+		}
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+// flagForReview: 審査で際どいと判定したメッセージを、システムからの通報としてモデレーターの確認待ちに入れる
+// （表示はそのまま。失敗しても送信・編集自体は成功扱い）
+func (uc *ChatUsecase) flagForReview(msg *model.Message, verdict *moderation.Result, at time.Time) {
+	reason := verdict.Reason
+	if !reason.Valid() {
+		reason = model.ReasonOther
+	}
+	report := &model.Report{
+		ID:         model.NewID(),
+		TargetType: model.ReportTargetMessage,
+		TargetID:   msg.ID,
+		ReporterID: model.SystemActorID,
+		Reason:     reason,
+		Note:       fmt.Sprintf("auto-flagged by %s: %s", verdict.Source, verdict.Detail),
+		Status:     model.ReportOpen,
+		CreatedAt:  at,
+		UpdatedAt:  at,
+	}
+	_, err := uc.ReportDAO.Create(report, 0)
+	if errors.Is(err, dao.ErrReportExists) {
+		// 編集前の本文の通報がまだ確認待ちなら、モデレーターはその通報から最新の本文と履歴を確認できる
+		return
+	}
+	if err != nil {
+		log.Printf("fail: flag message for review, %v\n", err)
+	}
+}
+
+// publish: 保存したメッセージを購読中のクライアントに配信する（失敗しても送信自体は成功扱い）
+func (uc *ChatUsecase) publish(msg *model.Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	ev := model.ChatEvent{Topic: msg.ConversationID, ID: msg.ID, Type: model.ChatEventMessage, Data: data}
+	if err := uc.Hub.Publish(ev); err != nil {
+		log.Printf("fail: publish chat event, %v\n", err)
+	}
+}
+
+// publishChange: 編集・送信取り消しを配信する（再開位置は新しいメッセージだけなので ID はつけない）
+func (uc *ChatUsecase) publishChange(typ model.ChatEventType, msg *model.Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	ev := model.ChatEvent{Topic: msg.ConversationID, Type: typ, Data: data}
+	if err := uc.Hub.Publish(ev); err != nil {
+		log.Printf("fail: publish chat event, %v\n", err)
+	}
+}
+
+// CloseBetween: a と b のやりとりを購読しているストリームに、ブロックで閉じられたことを知らせる
+func (uc *ChatUsecase) CloseBetween(a, b string) {
+	ids, err := uc.ConversationDAO.ListBetween(a, b)
+	if err != nil {
+		log.Printf("fail: list conversations to close, %v\n", err)
+		return
+	}
+	for _, id := range ids {
+		ev := model.ChatEvent{Topic: id, Type: model.ChatEventClosed, Data: json.RawMessage("{}")}
+		if err := uc.Hub.Publish(ev); err != nil {
+			log.Printf("fail: publish chat event, %v\n", err)
+		}
+	}
+}
+
+// publishRead: userID が seq 番目のメッセージまで読んだことを配信する
+func (uc *ChatUsecase) publishRead(conversationID, userID string, seq int) {
+	data, err := json.Marshal(map[string]interface{}{"user_id": userID, "read_seq": seq})
+	if err != nil {
+		return
+	}
+	ev := model.ChatEvent{Topic: conversationID, Type: model.ChatEventRead, Data: data}
+	if err := uc.Hub.Publish(ev); err != nil {
+		log.Printf("fail: publish chat event, %v\n", err)
+	}
+}
+
+// MigrateLegacy: 商品ごとの共有チャット時代のメッセージを、購入希望者ごとのやりとりに振り分ける
+// 振り分け済みのメッセージは対象外なので、何度実行してもよい
+func (uc *ChatUsecase) MigrateLegacy() (int, error) {
+	// 並び順を正しくするため、先に送信日時を UTC の DATETIME に移す
+	if n, err := uc.MessageDAO.MigrateCreatedAt(); err != nil {
+		return 0, err
+	} else if n > 0 {
+		log.Printf("converted created_at of %d messages to UTC", n)
+	}
+
+	itemIDs, err := uc.ConversationDAO.ListLegacyItemIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, itemID := range itemIDs {
+		item, err := uc.ItemDAO.GetByID(itemID)
+		if err != nil {
+			return moved, err
+		}
+		// 出品者を記録する前からある商品（と削除済みの商品）は出品者が分からないので、
+		// 出品者は空のまま、送信者ごとのやりとりに移す（履歴を消さないため）
+		sellerID := ""
+		if item != nil {
+			sellerID = item.SellerID
+		}
+		if sellerID == "" {
+			log.Printf("chat migration for item %s: seller unknown, keeping messages per sender", itemID)
+		}
+		messages, err := uc.ConversationDAO.ListLegacyMessages(itemID)
+		if err != nil {
+			return moved, err
+		}
+		n, err := uc.ConversationDAO.AssignLegacy(itemID, sellerID, model.AssignLegacyMessages(messages, sellerID), time.Now())
+		if err != nil {
+			return moved, err
+		}
+		moved += n
+	}
+
+	// 既読管理を始める前のメッセージに通し番号を振る
+	if _, err := uc.ConversationDAO.BackfillSeq(); err != nil {
+		return moved, err
+	}
+	return moved, nil
+}
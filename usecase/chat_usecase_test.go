@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hackathon-backend/model"
+	"hackathon-backend/moderation"
+	"testing"
+)
+
+// stubChecker: 決まった結果を返す AI 判定
+type stubChecker struct {
+	result *moderation.Result
+	err    error
+}
+
+func (c stubChecker) Check(ctx context.Context, text string) (*moderation.Result, error) {
+	return c.result, c.err
+}
+
+// TestSend_Rejected は審査で止めたメッセージが保存されずにエラーになることを確認する
+// （MessageDAO を持たせていないので、保存しようとすると panic する）
+func TestSend_Rejected(t *testing.T) {
+	conv := &model.Conversation{ID: "c1", ItemID: "i1", BuyerID: "buyer", SellerID: "seller"}
+	testCases := []struct {
+		name       string
+		checker    stubChecker
+		wantReason model.ReportReason
+		wantErr    error
+	}{
+		{
+			name:       "AI が拒否",
+			checker:    stubChecker{result: &moderation.Result{Verdict: moderation.Block, Reason: model.ReasonScam, Source: "ai"}},
+			wantReason: model.ReasonScam,
+		},
+		{
+			name:    "AI の障害（fail-closed）",
+			checker: stubChecker{err: errors.New("timeout")},
+			wantErr: moderation.ErrUnavailable,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("send() tried to store the message: %v", r)
+				}
+			}()
+			uc := &ChatUsecase{Moderator: &moderation.Moderator{AI: tc.checker}}
+
+			msg, err := uc.send(conv, "buyer", "外部のサイトで直接取引しませんか", nil)
+			if msg != nil {
+				t.Errorf("send() = %+v, want nil", msg)
+			}
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("send() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			var rejected *MessageRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("send() error = %v, want MessageRejectedError", err)
+			}
+			if rejected.Result.Reason != tc.wantReason {
+				t.Errorf("rejection reason = %q, want %q", rejected.Result.Reason, tc.wantReason)
+			}
+		})
+	}
+}
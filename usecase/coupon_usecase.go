@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"time"
+)
+
+// CouponUsecase: キャンペーンのクーポンの管理と、購入時の割引額の計算を担当
+// 利用上限の最終的な確認は、注文を作るトランザクションの中で行う（dao.OrderDAO.Create）
+type CouponUsecase struct {
+	CouponDAO  *dao.CouponDAO
+	Categories *CategoryUsecase
+}
+
+func NewCouponUsecase(couponDAO *dao.CouponDAO, categories *CategoryUsecase) *CouponUsecase {
+	return &CouponUsecase{CouponDAO: couponDAO, Categories: categories}
+}
+
+// Create: クーポンを登録する
+func (uc *CouponUsecase) Create(c *model.Coupon) error {
+	c.Code = model.NormalizeCouponCode(c.Code)
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	c.RedeemedCount = 0
+	c.CreatedAt = time.Now()
+	return uc.CouponDAO.Create(c)
+}
+
+// Update: クーポンの条件を変更する（active を false にすると停止）
+func (uc *CouponUsecase) Update(c *model.Coupon) (*model.Coupon, error) {
+	c.Code = model.NormalizeCouponCode(c.Code)
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if err := uc.CouponDAO.Update(c); err != nil {
+		return nil, err
+	}
+	return uc.CouponDAO.GetByCode(c.Code)
+}
+
+// Quote: buyerID が price の item にクーポンを使ったときの割引額
+// 利用回数・初回購入の条件もここで確認するが、同時に使われた場合は購入時に改めて弾かれる
+func (uc *CouponUsecase) Quote(code string, item *model.Item, buyerID string, price int) (*model.Coupon, int, error) {
+	coupon, err := uc.CouponDAO.GetByCode(model.NormalizeCouponCode(code))
+	if err != nil {
+		return nil, 0, err
+	}
+	if coupon == nil {
+		return nil, 0, dao.ErrCouponNotFound
+	}
+
+	var path []string
+	for _, c := range uc.Categories.Tree().Path(item.Category) {
+		path = append(path, c.Slug)
+	}
+	discount, err := coupon.Discount(price, path, time.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if coupon.MaxRedemptions > 0 && coupon.RedeemedCount >= coupon.MaxRedemptions {
+		return nil, 0, model.ErrCouponExhausted
+	}
+	if coupon.MaxPerUser > 0 {
+		used, err := uc.CouponDAO.CountRedemptions(coupon.Code, buyerID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if used >= coupon.MaxPerUser {
+			return nil, 0, model.ErrCouponUserLimit
+		}
+	}
+	if coupon.FirstPurchaseOnly {
+		purchased, err := uc.CouponDAO.HasPurchased(buyerID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if purchased {
+			return nil, 0, model.ErrCouponFirstPurchaseOnly
+		}
+	}
+	return coupon, discount, nil
+}
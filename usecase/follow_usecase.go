@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrCannotFollowSelf = errors.New("cannot follow yourself")
+	ErrInvalidShopTab   = errors.New("tab must be active or sold")
+)
+
+// ShopTab: 出品者のショップのタブ
+type ShopTab string
+
+const (
+	ShopTabActive ShopTab = "active"
+	ShopTabSold   ShopTab = "sold"
+)
+
+// Storefront: 出品者のショップ（1ページ分）
+type Storefront struct {
+	SellerID      string `json:"seller_id"`
+	SellerName    string `json:"seller_name"`
+	FollowerCount int    `json:"follower_count"`
+	Following     bool   `json:"following"`
+	*model.ItemPage
+}
+
+// FollowUsecase: 出品者のショップ、フォロー、フォロー中の出品者の新着フィードを担当
+type FollowUsecase struct {
+	FollowDAO *dao.FollowDAO
+	ItemDAO   *dao.ItemDAO
+	UserDAO   *dao.UserDAO
+}
+
+func NewFollowUsecase(followDAO *dao.FollowDAO, itemDAO *dao.ItemDAO, userDAO *dao.UserDAO) *FollowUsecase {
+	return &FollowUsecase{FollowDAO: followDAO, ItemDAO: itemDAO, UserDAO: userDAO}
+}
+
+// Toggle: フォローを付けたり外したりする（フォロー中になったら true）
+func (uc *FollowUsecase) Toggle(followerID, sellerID string) (bool, error) {
+	if followerID == "" {
+		return false, ErrNotAllowed
+	}
+	if followerID == sellerID {
+		return false, ErrCannotFollowSelf
+	}
+	seller, err := uc.UserDAO.GetUserByID(sellerID)
+	if err != nil {
+		return false, err
+	}
+	if seller == nil {
+		return false, ErrUserNotFound
+	}
+	return uc.FollowDAO.ToggleFollow(followerID, sellerID)
+}
+
+// Storefront: 出品者のショップの商品を新着順に返す（viewerID が分かればフォロー中かどうかも返す）
+func (uc *FollowUsecase) Storefront(sellerID, viewerID string, tab ShopTab, cursor string, limit int) (*Storefront, error) {
+	status := model.ItemPublished
+	switch tab {
+	case ShopTabActive, "":
+	case ShopTabSold:
+		status = model.ItemSold
+	default:
+		return nil, ErrInvalidShopTab
+	}
+	after, err := model.ParseItemCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	seller, err := uc.UserDAO.GetVisibleUserByID(sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if seller == nil {
+		return nil, ErrUserNotFound
+	}
+
+	items, err := uc.ItemDAO.ListStorefront(seller.ID, status, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := uc.FollowDAO.CountFollowers(seller.ID)
+	if err != nil {
+		return nil, err
+	}
+	following := false
+	if viewerID != "" && viewerID != seller.ID {
+		if following, err = uc.FollowDAO.IsFollowing(viewerID, seller.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Storefront{
+		SellerID:      seller.ID,
+		SellerName:    seller.Name,
+		FollowerCount: followers,
+		Following:     following,
+		ItemPage:      model.NewItemPage(items, limit),
+	}, nil
+}
+
+// Feed: フォロー中の出品者の新着商品（1ページ分）
+func (uc *FollowUsecase) Feed(userID, cursor string, limit int) (*model.ItemPage, error) {
+	if userID == "" {
+		return nil, ErrNotAllowed
+	}
+	after, err := model.ParseItemCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	items, err := uc.ItemDAO.ListFeed(userID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewItemPage(items, limit), nil
+}
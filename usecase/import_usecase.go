@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"io"
+	"time"
+)
+
+// ImportUsecase: CSV / JSONL からの一括出品を担当
+// 外部 SKU が登録済みの行は登録し直さないので、同じファイルを何度取り込んでもよい
+type ImportUsecase struct {
+	ItemDAO *dao.ItemDAO
+	// MaxRows: 1回で取り込める行数
+	MaxRows int
+}
+
+func NewImportUsecase(itemDAO *dao.ItemDAO, maxRows int) *ImportUsecase {
+	return &ImportUsecase{ItemDAO: itemDAO, MaxRows: maxRows}
+}
+
+// Import: ファイルを読み、正しい行を出品して行ごとの結果を返す（dryRun なら検証だけ）
+func (uc *ImportUsecase) Import(sellerID string, r io.Reader, format model.ImportFormat, dryRun bool) (*model.ImportReport, error) {
+	if sellerID == "" {
+		return nil, ErrNotAllowed
+	}
+	rows, err := model.ParseImport(r, format, uc.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	var skus []string
+	for _, row := range rows {
+		if row.SKU != "" {
+			skus = append(skus, row.SKU)
+		}
+	}
+	existing, err := uc.ItemDAO.FindBySKUs(sellerID, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ImportReport{DryRun: dryRun, Rows: []*model.ImportRowResult{}}
+	seen := map[string]int{} // SKU → 最初に出てきた行
+	for _, row := range rows {
+		report.Add(uc.importRow(sellerID, row, existing, seen, dryRun))
+	}
+	return report, nil
+}
+
+// importRow: 1行を検証して出品する
+func (uc *ImportUsecase) importRow(sellerID string, row *model.ImportRow, existing map[string]string, seen map[string]int, dryRun bool) *model.ImportRowResult {
+	res := &model.ImportRowResult{Line: row.Line, SKU: row.SKU}
+	if err := row.Validate(); err != nil {
+		res.Status, res.Error = model.ImportInvalid, err.Error()
+		return res
+	}
+	if row.SKU != "" {
+		if line, ok := seen[row.SKU]; ok {
+			res.Status, res.Error = model.ImportInvalid, fmt.Sprintf("duplicate sku (same as line %d)", line)
+			return res
+		}
+		seen[row.SKU] = row.Line
+		if id, ok := existing[row.SKU]; ok {
+			res.Status, res.ItemID = model.ImportSkipped, id
+			return res
+		}
+	}
+	if dryRun {
+		res.Status = model.ImportWouldCreate
+		return res
+	}
+
+	now := time.Now()
+	item := &model.Item{
+		ID:          model.NewID(),
+		Name:        row.Name,
+		Price:       row.Price,
+		Description: row.Description,
+		ImageURL:    row.ImageURL,
+		SellerID:    sellerID,
+		ExternalSKU: row.SKU,
+		Status:      model.ItemPublished,
+		CreatedAt:   &now,
+		PublishedAt: &now,
+	}
+	err := uc.ItemDAO.Insert(item)
+	switch {
+	case errors.Is(err, dao.ErrDuplicateSKU): // 同時に取り込まれた
+		res.Status = model.ImportSkipped
+	case err != nil:
+		res.Status, res.Error = model.ImportFailed, err.Error()
+	default:
+		res.Status, res.ItemID = model.ImportCreated, item.ID
+	}
+	return res
+}
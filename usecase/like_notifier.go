@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"fmt"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"log"
+	"time"
+)
+
+// LikeNotifier: いいねしたユーザーへ、値下げや売り切れを知らせる
+type LikeNotifier struct {
+	LikeDAO         *dao.LikeDAO
+	NotificationDAO *dao.NotificationDAO
+}
+
+func NewLikeNotifier(likeDAO *dao.LikeDAO, notificationDAO *dao.NotificationDAO) *LikeNotifier {
+	return &LikeNotifier{LikeDAO: likeDAO, NotificationDAO: notificationDAO}
+}
+
+// PriceDropped: 値下げを通知する（同じ価格への値下げは一度だけ）
+func (n *LikeNotifier) PriceDropped(item *model.Item, oldPrice int) (int, error) {
+	return n.fanOut(item, "", func() *model.Notification {
+		return &model.Notification{
+			Type:     model.NotifyPriceDrop,
+			Message:  fmt.Sprintf("いいねした「%s」が ¥%d → ¥%d に値下げされました", item.Name, oldPrice, item.Price),
+			DedupKey: fmt.Sprintf("price_drop:%s:%d", item.ID, item.Price),
+		}
+	})
+}
+
+// Sold: 売り切れを通知する（購入者本人には送らない）
+func (n *LikeNotifier) Sold(item *model.Item, order *model.Order) (int, error) {
+	return n.fanOut(item, order.BuyerID, func() *model.Notification {
+		return &model.Notification{
+			Type:     model.NotifyItemSold,
+			Message:  fmt.Sprintf("いいねした「%s」は売り切れました", item.Name),
+			DedupKey: "sold:" + order.ID,
+		}
+	})
+}
+
+// fanOut: いいねしたユーザー全員に通知を作る（exclude と出品者は除く）
+func (n *LikeNotifier) fanOut(item *model.Item, exclude string, build func() *model.Notification) (int, error) {
+	userIDs, err := n.LikeDAO.GetLikerIDs(item.ID)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	sent := 0
+	for _, userID := range userIDs {
+		if userID == exclude || userID == item.SellerID {
+			continue
+		}
+		notification := build()
+		notification.ID = model.NewID()
+		notification.UserID = userID
+		notification.ItemID = item.ID
+		notification.SourceID = item.ID
+		notification.CreatedAt = now
+		created, err := n.NotificationDAO.Create(notification)
+		if err != nil {
+			return sent, err
+		}
+		if created {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// notifyAsync: 通知の失敗で本来の処理を失敗させないよう、別の goroutine で送ってログだけ残す
+func notifyAsync(name string, fn func() (int, error)) {
+	go func() {
+		if n, err := fn(); err != nil {
+			log.Printf("fail: notify %s, %v\n", name, err)
+		} else if n > 0 {
+			log.Printf("Sent %d %s notifications", n, name)
+		}
+	}()
+}
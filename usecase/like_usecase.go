@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+)
+
+// LikeUsecase: いいねの切り替えを担当
+type LikeUsecase struct {
+	LikeDAO *dao.LikeDAO
+	ItemDAO *dao.ItemDAO
+	// Blocks: ブロックのある出品者の商品には新しくいいねさせない（解除はできる）
+	Blocks *BlockUsecase
+}
+
+func NewLikeUsecase(likeDAO *dao.LikeDAO, itemDAO *dao.ItemDAO, blocks *BlockUsecase) *LikeUsecase {
+	return &LikeUsecase{LikeDAO: likeDAO, ItemDAO: itemDAO, Blocks: blocks}
+}
+
+// Toggle: いいねを付けたり外したりする（付いたかを返す）
+// 同時に押されても、付ける・外すのどちらかに決めてから SetLiked で反映するので数はずれない
+func (uc *LikeUsecase) Toggle(userID, itemID string) (bool, error) {
+	if userID == "" {
+		return false, ErrNotAllowed
+	}
+	liked, err := uc.LikeDAO.IsLiked(userID, itemID)
+	if err != nil {
+		return false, err
+	}
+	return !liked, uc.SetLiked(userID, itemID, !liked)
+}
+
+// SetLiked: いいねを付ける・外すを指定して反映する（何度呼んでも同じ結果になる）
+func (uc *LikeUsecase) SetLiked(userID, itemID string, liked bool) error {
+	if userID == "" {
+		return ErrNotAllowed
+	}
+	if !liked {
+		return uc.LikeDAO.Unlike(userID, itemID)
+	}
+
+	item, err := uc.ItemDAO.GetByID(itemID)
+	if err != nil {
+		return err
+	}
+	if item == nil || item.Hidden || item.Status == model.ItemDraft {
+		return ErrItemNotFound
+	}
+	if err := uc.Blocks.CheckItem(userID, item); err != nil {
+		return err
+	}
+	return uc.LikeDAO.Like(userID, itemID)
+}
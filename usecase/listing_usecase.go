@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"log"
+	"time"
+)
+
+var (
+	// ErrNotDraft: 下書き以外は下書きとして編集・削除・公開できない
+	ErrNotDraft = errors.New("item is not a draft")
+	// ErrItemNotEditable: 公開中・取り下げ中以外の商品は編集できない
+	ErrItemNotEditable = errors.New("only published or withdrawn items can be edited")
+)
+
+// ListingUsecase: 出品の下書き・公開・予約公開・取り下げを担当
+type ListingUsecase struct {
+	ItemDAO  *dao.ItemDAO
+	Notifier *LikeNotifier
+	// Categories: 入力されたカテゴリ名をカテゴリの木の slug に対応付ける
+	Categories *CategoryUsecase
+}
+
+func NewListingUsecase(itemDAO *dao.ItemDAO, notifier *LikeNotifier, categories *CategoryUsecase) *ListingUsecase {
+	return &ListingUsecase{ItemDAO: itemDAO, Notifier: notifier, Categories: categories}
+}
+
+// CreateDraft: 下書きを保存する（未入力の項目があってもよい）
+func (uc *ListingUsecase) CreateDraft(item *model.Item) error {
+	if item.SellerID == "" {
+		return ErrNotAllowed
+	}
+	if item.PublishAt != nil {
+		if err := item.ValidateForPublish(); err != nil {
+			return err
+		}
+	}
+	uc.mapCategory(item)
+	now := time.Now()
+	item.ID = model.NewID()
+	item.Status = model.ItemDraft
+	item.CreatedAt = &now
+	item.PublishedAt = nil
+	return uc.ItemDAO.Insert(item)
+}
+
+// ListDrafts: 出品者の下書き一覧
+func (uc *ListingUsecase) ListDrafts(sellerID string) ([]*model.Item, error) {
+	if sellerID == "" {
+		return nil, ErrNotAllowed
+	}
+	return uc.ItemDAO.ListBySeller(sellerID, model.ItemDraft)
+}
+
+// UpdateDraft: 下書きの内容と予約公開日時を更新する
+func (uc *ListingUsecase) UpdateDraft(item *model.Item) (*model.Item, error) {
+	current, err := uc.ownDraft(item.ID, item.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if item.PublishAt != nil {
+		if err := item.ValidateForPublish(); err != nil {
+			return nil, err
+		}
+	}
+	uc.mapCategory(item)
+	if err := uc.ItemDAO.UpdateDraft(item); err != nil {
+		return nil, err
+	}
+	current.Name, current.Price, current.Description, current.ImageURL, current.PublishAt =
+		item.Name, item.Price, item.Description, item.ImageURL, item.PublishAt
+	current.Category, current.Tags = item.Category, item.Tags
+	return current, nil
+}
+
+// DeleteDraft: 下書きを削除する
+func (uc *ListingUsecase) DeleteDraft(id, sellerID string) error {
+	if _, err := uc.ownDraft(id, sellerID); err != nil {
+		return err
+	}
+	return uc.ItemDAO.DeleteDraft(id, sellerID)
+}
+
+// Publish: 下書きを公開する（publishAt が未来なら予約公開にする）
+func (uc *ListingUsecase) Publish(id, sellerID string, publishAt *time.Time) (*model.Item, error) {
+	item, err := uc.ownDraft(id, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := item.ValidateForPublish(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if publishAt != nil && publishAt.After(now) {
+		item.PublishAt = publishAt
+		if err := uc.ItemDAO.UpdateDraft(item); err != nil {
+			return nil, err
+		}
+		return item, nil
+	}
+
+	if err := uc.ItemDAO.SetStatus(item.ID, model.ItemDraft, model.ItemPublished, now); err != nil {
+		return nil, err
+	}
+	item.Status, item.PublishAt, item.PublishedAt = model.ItemPublished, nil, &now
+	return item, nil
+}
+
+// Update: 公開中・取り下げ中の商品を編集する（値下げしたら、いいねしたユーザーに通知する）
+func (uc *ListingUsecase) Update(item *model.Item) (*model.Item, error) {
+	current, err := uc.ItemDAO.GetByID(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrItemNotFound
+	}
+	if item.SellerID == "" || current.SellerID != item.SellerID {
+		return nil, ErrNotAllowed
+	}
+	if current.Status != model.ItemPublished && current.Status != model.ItemWithdrawn {
+		return nil, ErrItemNotEditable
+	}
+	if err := item.ValidateForPublish(); err != nil {
+		return nil, err
+	}
+	uc.mapCategory(item)
+	if err := uc.ItemDAO.UpdateListing(item, current.Price, time.Now()); err != nil {
+		return nil, err
+	}
+
+	oldPrice := current.Price
+	current.Name, current.Price, current.Description, current.ImageURL = item.Name, item.Price, item.Description, item.ImageURL
+	current.Category, current.Tags = item.Category, item.Tags
+	if current.Price < oldPrice && current.Status == model.ItemPublished && uc.Notifier != nil {
+		notifyAsync("price drop", func() (int, error) { return uc.Notifier.PriceDropped(current, oldPrice) })
+	}
+	return current, nil
+}
+
+// Withdraw: 公開中の商品を取り下げる
+func (uc *ListingUsecase) Withdraw(id, sellerID string) (*model.Item, error) {
+	item, err := uc.ItemDAO.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	if sellerID == "" || item.SellerID != sellerID {
+		return nil, ErrNotAllowed
+	}
+	if err := uc.ItemDAO.SetStatus(item.ID, model.ItemPublished, model.ItemWithdrawn, time.Now()); err != nil {
+		return nil, err
+	}
+	item.Status = model.ItemWithdrawn
+	return item, nil
+}
+
+// PublishDue: 予約公開日時を過ぎた下書きを公開する（定期実行用）
+func (uc *ListingUsecase) PublishDue() ([]*model.Item, error) {
+	now := time.Now()
+	due, err := uc.ItemDAO.ListDueDrafts(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var published []*model.Item
+	for _, item := range due {
+		// 予約後に必須項目が消された下書きは、予約を外して下書きのまま残す
+		if err := item.ValidateForPublish(); err != nil {
+			log.Printf("skip scheduled publish %s: %v\n", item.ID, err)
+			item.PublishAt = nil
+			if err := uc.ItemDAO.UpdateDraft(item); err != nil && !errors.Is(err, dao.ErrConflict) {
+				return published, err
+			}
+			continue
+		}
+		err := uc.ItemDAO.SetStatus(item.ID, model.ItemDraft, model.ItemPublished, now)
+		if errors.Is(err, dao.ErrConflict) {
+			continue // 同時に手動で公開・削除された
+		}
+		if err != nil {
+			return published, err
+		}
+		item.Status, item.PublishAt, item.PublishedAt = model.ItemPublished, nil, &now
+		published = append(published, item)
+	}
+	return published, nil
+}
+
+// mapCategory: 自由入力のカテゴリ名を、最も近いカテゴリの slug に置き換える
+func (uc *ListingUsecase) mapCategory(item *model.Item) {
+	if uc.Categories != nil {
+		item.Category = uc.Categories.Match(item.Category)
+	}
+}
+
+// ownDraft: 出品者本人の下書きを取得する
+func (uc *ListingUsecase) ownDraft(id, sellerID string) (*model.Item, error) {
+	item, err := uc.ItemDAO.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	if sellerID == "" || item.SellerID != sellerID {
+		return nil, ErrNotAllowed
+	}
+	if item.Status != model.ItemDraft {
+		return nil, ErrNotDraft
+	}
+	return item, nil
+}
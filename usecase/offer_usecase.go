@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"time"
+)
+
+var (
+	ErrOfferNotFound = errors.New("offer not found")
+	ErrOfferExpired  = errors.New("offer has expired")
+)
+
+// OfferUsecase: 値下げ交渉（オファー・逆提案・承諾・辞退・期限切れ）を担当
+type OfferUsecase struct {
+	OfferDAO *dao.OfferDAO
+	ItemDAO  *dao.ItemDAO
+	// TTL: 相手の返答を待つ期限
+	TTL time.Duration
+	// ReservationTTL: 合意後、購入者のために商品を確保しておく期間
+	ReservationTTL time.Duration
+	// Blocks: ブロックのある相手とは交渉させない（辞退だけはできる）
+	Blocks *BlockUsecase
+}
+
+func NewOfferUsecase(offerDAO *dao.OfferDAO, itemDAO *dao.ItemDAO, ttl, reservationTTL time.Duration, blocks *BlockUsecase) *OfferUsecase {
+	return &OfferUsecase{OfferDAO: offerDAO, ItemDAO: itemDAO, TTL: ttl, ReservationTTL: reservationTTL, Blocks: blocks}
+}
+
+// MakeOffer: 購入者が出品価格より安い金額を提示する
+func (uc *OfferUsecase) MakeOffer(itemID, buyerID string, amount int) (*model.Offer, error) {
+	item, err := uc.ItemDAO.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.Hidden || item.Status == model.ItemDraft || item.Status == model.ItemWithdrawn {
+		return nil, ErrItemNotFound
+	}
+	if buyerID == "" {
+		return nil, ErrNotAllowed
+	}
+	if item.SellerID == buyerID {
+		return nil, ErrOwnItem
+	}
+	if err := uc.Blocks.CheckItem(buyerID, item); err != nil {
+		return nil, err
+	}
+	if item.SoldOut {
+		return nil, dao.ErrItemUnavailable
+	}
+	if amount <= 0 || amount > item.Price {
+		return nil, model.ErrInvalidOfferAmount
+	}
+
+	now := time.Now()
+	offer := &model.Offer{
+		ID:        model.NewID(),
+		ItemID:    item.ID,
+		BuyerID:   buyerID,
+		SellerID:  item.SellerID,
+		Amount:    amount,
+		Status:    model.OfferPending,
+		ExpiresAt: now.Add(uc.TTL),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := uc.OfferDAO.Create(offer); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// List: 出品者にはすべてのオファー、それ以外には自分のオファーだけを返す
+func (uc *OfferUsecase) List(itemID, userID string) ([]*model.Offer, error) {
+	item, err := uc.ItemDAO.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	if userID == "" {
+		return nil, ErrNotAllowed
+	}
+	if item.SellerID == userID {
+		return uc.OfferDAO.ListByItem(itemID, "")
+	}
+	return uc.OfferDAO.ListByItem(itemID, userID)
+}
+
+// Act: 逆提案・承諾・辞退を行う（amount は逆提案のときだけ使う）
+func (uc *OfferUsecase) Act(offerID, userID string, action model.OfferAction, amount int) (*model.Offer, error) {
+	offer, err := uc.OfferDAO.GetByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer == nil {
+		return nil, ErrOfferNotFound
+	}
+
+	var role model.OrderActor
+	switch {
+	case userID != "" && userID == offer.BuyerID:
+		role = model.OrderActorBuyer
+	case userID != "" && userID == offer.SellerID:
+		role = model.OrderActorSeller
+	default:
+		return nil, ErrNotAllowed
+	}
+	if action != model.OfferActionDecline {
+		if err := uc.Blocks.CheckBetween(offer.BuyerID, offer.SellerID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if offer.Status.IsActive() && now.After(offer.ExpiresAt) {
+		return nil, ErrOfferExpired
+	}
+	next, err := model.NextOfferStatus(offer.Status, action, role)
+	if err != nil {
+		return nil, err
+	}
+
+	from := offer.Status
+	offer.Status = next
+	offer.UpdatedAt = now
+	offer.ExpiresAt = now.Add(uc.TTL)
+
+	switch action {
+	case model.OfferActionCounter:
+		item, err := uc.ItemDAO.GetByID(offer.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, ErrItemNotFound
+		}
+		if amount <= 0 || amount > item.Price {
+			return nil, model.ErrInvalidOfferAmount
+		}
+		offer.Amount = amount
+	case model.OfferActionAccept:
+		// 合意した価格で、購入者のために商品を確保する
+		offer.ExpiresAt = now.Add(uc.ReservationTTL)
+		if err := uc.OfferDAO.Accept(offer, from); err != nil {
+			return nil, err
+		}
+		return offer, nil
+	}
+
+	if err := uc.OfferDAO.Update(offer, from); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// ExpireDue: 期限切れのオファーと確保を失効させる（定期実行用）
+func (uc *OfferUsecase) ExpireDue() (int64, error) {
+	return uc.OfferDAO.ExpireDue(time.Now())
+}
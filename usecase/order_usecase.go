@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/payment"
+	"log"
+	"time"
+)
+
+// Currency: 決済通貨（円はゼロ小数点通貨なので金額をそのまま渡す）
+const Currency = "jpy"
+
+var (
+	ErrItemNotFound  = errors.New("item not found")
+	ErrOrderNotFound = errors.New("order not found")
+	ErrOwnItem       = errors.New("cannot purchase your own item")
+	ErrNotAllowed    = errors.New("not allowed")
+	ErrPaymentFailed = errors.New("payment failed")
+	ErrItemReserved  = errors.New("item is reserved for another buyer")
+	// ErrNoSeller: 出品者を記録する前の商品は、発送や売上の受け取りができないので購入できない
+	ErrNoSeller = errors.New("item has no seller and cannot be purchased")
+)
+
+// OrderUsecase: 購入から取引完了までの状態遷移を担当
+// 購入代金は受取確認まで預かり (エスクロー)、その後出品者へ解放する
+// 預かり金の動きはすべて台帳 (ledger_entries) に仕訳として記録する
+type OrderUsecase struct {
+	OrderDAO *dao.OrderDAO
+	ItemDAO  *dao.ItemDAO
+	Payments payment.Provider
+	// FeeRateBps: 販売手数料率（ベーシスポイント, 1000 = 10%）
+	FeeRateBps int
+	// Notifier: 売れたことを、いいねしたユーザーに知らせる
+	Notifier *LikeNotifier
+	// Coupons: 購入時のクーポン割引
+	Coupons *CouponUsecase
+	// Blocks: ブロックした（された）出品者の商品は購入させない
+	Blocks *BlockUsecase
+}
+
+func NewOrderUsecase(orderDAO *dao.OrderDAO, itemDAO *dao.ItemDAO, payments payment.Provider, feeRateBps int, notifier *LikeNotifier, coupons *CouponUsecase, blocks *BlockUsecase) *OrderUsecase {
+	return &OrderUsecase{OrderDAO: orderDAO, ItemDAO: itemDAO, Payments: payments, FeeRateBps: feeRateBps, Notifier: notifier, Coupons: coupons, Blocks: blocks}
+}
+
+// Checkout: 購入前の支払額の内訳
+type Checkout struct {
+	ItemID     string `json:"item_id"`
+	Price      int    `json:"price"`
+	Discount   int    `json:"discount"`
+	CouponCode string `json:"coupon_code,omitempty"`
+	Total      int    `json:"total"`
+}
+
+// Quote: 購入した場合の支払額（クーポンを使う場合は割引後）
+func (uc *OrderUsecase) Quote(itemID, buyerID, couponCode string) (*Checkout, error) {
+	_, checkout, err := uc.checkout(itemID, buyerID, couponCode, time.Now())
+	return checkout, err
+}
+
+// checkPurchasable: buyerID が item を購入できる状態か（ブロックの確認は別）
+func checkPurchasable(item *model.Item, buyerID string) error {
+	switch {
+	case item == nil || item.Hidden || item.Status == model.ItemDraft || item.Status == model.ItemWithdrawn:
+		return ErrItemNotFound
+	case item.SellerID == "":
+		return ErrNoSeller
+	case item.SellerID == buyerID:
+		return ErrOwnItem
+	case item.SoldOut:
+		return dao.ErrItemUnavailable
+	}
+	return nil
+}
+
+// checkout: 購入できるかを確認し、支払額を計算する
+func (uc *OrderUsecase) checkout(itemID, buyerID, couponCode string, now time.Time) (*model.Item, *Checkout, error) {
+	item, err := uc.ItemDAO.GetByID(itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkPurchasable(item, buyerID); err != nil {
+		return nil, nil, err
+	}
+	if err := uc.Blocks.CheckItem(buyerID, item); err != nil {
+		return nil, nil, err
+	}
+
+	// 値下げ交渉で合意済みなら合意価格、他の購入者のための確保中なら購入不可
+	price := item.Price
+	if item.ReservedAt(now) {
+		if item.ReservedFor != buyerID {
+			return nil, nil, ErrItemReserved
+		}
+		price = item.ReservedPrice
+	}
+
+	checkout := &Checkout{ItemID: item.ID, Price: price, Total: price}
+	if couponCode != "" {
+		if uc.Coupons == nil {
+			return nil, nil, dao.ErrCouponNotFound
+		}
+		coupon, discount, err := uc.Coupons.Quote(couponCode, item, buyerID, price)
+		if err != nil {
+			return nil, nil, err
+		}
+		checkout.CouponCode, checkout.Discount, checkout.Total = coupon.Code, discount, price-discount
+	}
+	return item, checkout, nil
+}
+
+// Purchase: 代金を決済して「支払い済み」の注文を作る（couponCode が空ならクーポンなし）
+func (uc *OrderUsecase) Purchase(itemID, buyerID, couponCode string) (*model.Order, error) {
+	now := time.Now()
+	item, checkout, err := uc.checkout(itemID, buyerID, couponCode, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	orderID := model.NewID()
+
+	// 1. 与信を確保
+	pay, err := uc.Payments.Authorize(ctx, payment.AuthorizeRequest{Amount: checkout.Total, Currency: Currency, OrderID: orderID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+
+	// 2. 注文を作成（先に売れてしまった・クーポンの上限に達した場合は与信を取り消す）
+	order := &model.Order{
+		ID:           orderID,
+		ItemID:       item.ID,
+		BuyerID:      buyerID,
+		SellerID:     item.SellerID,
+		Price:        checkout.Price,
+		Discount:     checkout.Discount,
+		CouponCode:   checkout.CouponCode,
+		Status:       model.OrderStatusPaid,
+		PaymentID:    pay.ID,
+		EscrowStatus: model.EscrowAuthorized,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		PaidAt:       &now,
+	}
+	if err := uc.OrderDAO.Create(order); err != nil {
+		if cerr := uc.Payments.Cancel(ctx, pay.ID); cerr != nil {
+			log.Printf("fail: cancel authorization %s, %v\n", pay.ID, cerr)
+		}
+		return nil, err
+	}
+
+	// 3. 売上を確定してプラットフォームで預かる
+	if _, err := uc.Payments.Capture(ctx, pay.ID); err != nil {
+		log.Printf("fail: capture payment %s, %v\n", pay.ID, err)
+		if _, terr := uc.systemTransition(order, model.OrderStatusCancelled); terr != nil {
+			log.Printf("fail: cancel order %s after capture failure, %v\n", order.ID, terr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+	if err := uc.updateEscrow(order, model.EscrowHeld); err != nil {
+		return nil, err
+	}
+
+	if uc.Notifier != nil {
+		notifyAsync("item sold", func() (int, error) { return uc.Notifier.Sold(item, order) })
+	}
+	return order, nil
+}
+
+// Get: 取引の当事者であれば注文と監査ログを返す
+func (uc *OrderUsecase) Get(orderID, userID string) (*model.Order, []*model.OrderEvent, error) {
+	order, err := uc.OrderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if order == nil {
+		return nil, nil, ErrOrderNotFound
+	}
+	if order.RoleOf(userID) == "" {
+		return nil, nil, ErrNotAllowed
+	}
+
+	events, err := uc.OrderDAO.ListEvents(order.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, events, nil
+}
+
+// Transition: actorID のユーザーとして注文を to の状態に進める（購入者・出品者のみ。system は名乗れない）
+func (uc *OrderUsecase) Transition(orderID, actorID string, to model.OrderStatus) (*model.Order, error) {
+	if actorID == model.SystemActorID {
+		return nil, ErrNotAllowed
+	}
+	order, err := uc.OrderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	role := order.RoleOf(actorID)
+	if role == "" {
+		return nil, ErrNotAllowed
+	}
+	return uc.transition(order, actorID, role, to)
+}
+
+// systemTransition: 自動処理（決済失敗・自動完了など）として注文を進める。HTTP からは呼ばない
+func (uc *OrderUsecase) systemTransition(order *model.Order, to model.OrderStatus) (*model.Order, error) {
+	return uc.transition(order, model.SystemActorID, model.OrderActorSystem, to)
+}
+
+func (uc *OrderUsecase) transition(order *model.Order, actorID string, role model.OrderActor, to model.OrderStatus) (*model.Order, error) {
+	if err := model.CanTransition(order.Status, to, role); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := uc.OrderDAO.Transition(order, to, actorID, role, now); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.OrderDAO.GetByID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 預かり金の処理に失敗しても遷移自体は確定しているので、残りは SettleEscrows に任せる
+	if err := uc.settleEscrow(updated); err != nil {
+		log.Printf("fail: settle escrow for order %s, %v\n", updated.ID, err)
+	}
+	return updated, nil
+}
+
+// settleEscrow: 注文の状態に合わせて預かり金を解放・返金する
+func (uc *OrderUsecase) settleEscrow(order *model.Order) error {
+	ctx := context.Background()
+
+	switch {
+	case order.Status == model.OrderStatusCancelled && order.EscrowStatus == model.EscrowHeld:
+		// 返金するのは実際に請求した金額（クーポンの割引分は請求していない）
+		if _, err := uc.Payments.Refund(ctx, order.PaymentID, order.ChargedAmount()); err != nil {
+			return err
+		}
+		return uc.updateEscrow(order, model.EscrowRefunded)
+
+	case order.Status == model.OrderStatusCancelled && order.EscrowStatus == model.EscrowAuthorized:
+		if err := uc.Payments.Cancel(ctx, order.PaymentID); err != nil {
+			return err
+		}
+		return uc.updateEscrow(order, model.EscrowVoided)
+
+	case (order.Status == model.OrderStatusReceived || order.Status == model.OrderStatusCompleted) && order.EscrowStatus == model.EscrowHeld:
+		// 受取確認（または自動完了）で出品者へ解放する
+		return uc.updateEscrow(order, model.EscrowReleased)
+	}
+	return nil
+}
+
+// updateEscrow: 預かり状態を進め、対応する仕訳を記録する
+func (uc *OrderUsecase) updateEscrow(order *model.Order, to model.EscrowStatus) error {
+	now := time.Now()
+	var entries []*model.LedgerEntry
+	switch to {
+	case model.EscrowHeld:
+		entries = model.PaymentEntries(order, now)
+	case model.EscrowReleased:
+		entries = model.SaleEntries(order, uc.FeeRateBps, now)
+	case model.EscrowRefunded:
+		entries = model.RefundEntries(order, now)
+	}
+
+	if err := uc.OrderDAO.UpdateEscrow(order.ID, order.EscrowStatus, to, entries); err != nil {
+		return err
+	}
+	order.EscrowStatus = to
+	return nil
+}
+
+// SettleEscrows: 処理が残っている預かり金をまとめて処理する（定期実行用）
+func (uc *OrderUsecase) SettleEscrows() error {
+	orders, err := uc.OrderDAO.ListUnsettled()
+	if err != nil {
+		return err
+	}
+	for _, o := range orders {
+		err := uc.settleEscrow(o)
+		if errors.Is(err, payment.ErrPaymentForgotten) {
+			// 開発用の決済プロバイダは支払いをメモリにしか持たないので、再起動前の支払いは処理できない
+			log.Printf("skip escrow settlement for order %s: %v", o.ID, err)
+			continue
+		}
+		if err != nil {
+			log.Printf("fail: settle escrow for order %s, %v\n", o.ID, err)
+		}
+	}
+	return nil
+}
+
+// HandlePaymentEvent: 決済プロバイダからの Webhook イベントを注文に反映する
+func (uc *OrderUsecase) HandlePaymentEvent(ev *payment.Event) error {
+	order, err := uc.OrderDAO.GetByPaymentID(ev.PaymentID)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		// 他システムの決済など、関係ないイベントは無視する
+		log.Printf("Payment webhook: no order for payment %s (%s)", ev.PaymentID, ev.Type)
+		return nil
+	}
+
+	switch ev.Type {
+	case payment.EventChargeRefunded:
+		// ダッシュボードからの手動返金など
+		if order.EscrowStatus == model.EscrowHeld {
+			return uc.updateEscrow(order, model.EscrowRefunded)
+		}
+	case payment.EventPaymentFailed, payment.EventPaymentCanceled:
+		if order.EscrowStatus == model.EscrowAuthorized {
+			if err := uc.updateEscrow(order, model.EscrowVoided); err != nil {
+				return err
+			}
+		}
+		if order.Status == model.OrderStatusPaid {
+			_, err := uc.systemTransition(order, model.OrderStatusCancelled)
+			return err
+		}
+	}
+	return nil
+}
+
+// AutoComplete: 発送・受取後に after 以上動きがない注文を自動で完了にする
+func (uc *OrderUsecase) AutoComplete(after time.Duration) (int, error) {
+	since := time.Now().Add(-after)
+	completed := 0
+	for _, status := range []model.OrderStatus{model.OrderStatusShipped, model.OrderStatusReceived} {
+		orders, err := uc.OrderDAO.ListStale(status, since)
+		if err != nil {
+			return completed, err
+		}
+		for _, o := range orders {
+			if _, err := uc.systemTransition(o, model.OrderStatusCompleted); err != nil {
+				// 直前にユーザーが操作した場合などは次回に回す
+				log.Printf("fail: auto-complete order %s, %v\n", o.ID, err)
+				continue
+			}
+			completed++
+		}
+	}
+	return completed, nil
+}
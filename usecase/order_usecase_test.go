@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/payment"
+	"testing"
+)
+
+// errStopAfterRefund: 返金の呼び出しを確認したら、台帳の更新（DB）に進ませずに止める
+var errStopAfterRefund = errors.New("stop after refund")
+
+// refundRecorder: 返金を開発用プロバイダに渡し、結果を記録する
+type refundRecorder struct {
+	*payment.FakeProvider
+	refunded int
+	err      error
+}
+
+func (p *refundRecorder) Refund(ctx context.Context, paymentID string, amount int) (*payment.Refund, error) {
+	re, err := p.FakeProvider.Refund(ctx, paymentID, amount)
+	p.err = err
+	if err != nil {
+		return nil, err
+	}
+	p.refunded = re.Amount
+	return nil, errStopAfterRefund
+}
+
+// TestSettleEscrow_RefundDiscountedOrder はクーポンで割り引いた注文のキャンセルで、請求した金額だけを返金することを確認する
+func TestSettleEscrow_RefundDiscountedOrder(t *testing.T) {
+	ctx := context.Background()
+	provider := &refundRecorder{FakeProvider: payment.NewFakeProvider("whsec_test")}
+	order := &model.Order{ID: "o1", Price: 3000, Discount: 500, Status: model.OrderStatusCancelled, EscrowStatus: model.EscrowHeld}
+
+	pay, err := provider.Authorize(ctx, payment.AuthorizeRequest{Amount: order.ChargedAmount(), Currency: Currency, OrderID: order.ID})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if _, err := provider.Capture(ctx, pay.ID); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	order.PaymentID = pay.ID
+
+	uc := &OrderUsecase{Payments: provider}
+	if err := uc.settleEscrow(order); !errors.Is(err, errStopAfterRefund) {
+		t.Fatalf("settleEscrow() error = %v (provider error = %v)", err, provider.err)
+	}
+	if provider.refunded != 2500 {
+		t.Errorf("refunded %d, want 2500 (price minus discount)", provider.refunded)
+	}
+}
+
+// TestCheckPurchasable は購入できない商品（出品者が分からない商品を含む）を断ることを確認する
+func TestCheckPurchasable(t *testing.T) {
+	testCases := []struct {
+		name    string
+		item    *model.Item
+		wantErr error
+	}{
+		{name: "公開中", item: &model.Item{ID: "i1", SellerID: "seller", Status: model.ItemPublished}},
+		{name: "存在しない", wantErr: ErrItemNotFound},
+		{name: "非表示", item: &model.Item{ID: "i1", SellerID: "seller", Status: model.ItemPublished, Hidden: true}, wantErr: ErrItemNotFound},
+		{name: "下書き", item: &model.Item{ID: "i1", SellerID: "seller", Status: model.ItemDraft}, wantErr: ErrItemNotFound},
+		{name: "出品者が分からない", item: &model.Item{ID: "i1", Status: model.ItemPublished}, wantErr: ErrNoSeller},
+		{name: "自分の出品", item: &model.Item{ID: "i1", SellerID: "buyer", Status: model.ItemPublished}, wantErr: ErrOwnItem},
+		{name: "売り切れ", item: &model.Item{ID: "i1", SellerID: "seller", Status: model.ItemSold, SoldOut: true}, wantErr: dao.ErrItemUnavailable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkPurchasable(tc.item, "buyer"); !errors.Is(err, tc.wantErr) {
+				t.Errorf("checkPurchasable() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
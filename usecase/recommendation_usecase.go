@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"hackathon-backend/recommend"
+	"log"
+	"sync"
+)
+
+const (
+	// recommendNeighbors: 商品ごとに残す近傍の数
+	recommendNeighbors = 50
+	// recommendMaxLikesPerUser: これより多くいいねしているユーザーは共起の計算から除く
+	recommendMaxLikesPerUser = 500
+)
+
+// RecommendationUsecase: いいね履歴からのおすすめと、似ている商品を担当
+// 類似度と候補の商品一覧は定期的に Refresh で読み直し、リクエスト時はメモリ上の結果を使う
+// （売り切れ・非公開になった商品は次の Refresh まで候補に残ることがある）
+type RecommendationUsecase struct {
+	LikeDAO *dao.LikeDAO
+	ItemDAO *dao.ItemDAO
+	// Blocks: ブロックのある出品者の商品はおすすめしない
+	Blocks *BlockUsecase
+
+	mu    sync.RWMutex
+	index recommend.Index
+	// items: 候補になる公開中・未購入の商品
+	items []*model.Item
+	byID  map[string]*model.Item
+}
+
+func NewRecommendationUsecase(likeDAO *dao.LikeDAO, itemDAO *dao.ItemDAO, blocks *BlockUsecase) *RecommendationUsecase {
+	return &RecommendationUsecase{LikeDAO: likeDAO, ItemDAO: itemDAO, Blocks: blocks, index: recommend.Index{}, byID: map[string]*model.Item{}}
+}
+
+// Refresh: いいね履歴から商品同士の類似度を計算し直し、候補の商品一覧を読み直す（定期実行用）
+func (uc *RecommendationUsecase) Refresh() error {
+	likes, err := uc.LikeDAO.GetAll()
+	if err != nil {
+		return err
+	}
+	all, err := uc.ItemDAO.GetAll()
+	if err != nil {
+		return err
+	}
+	index := recommend.BuildCoLikeIndex(likes, recommendNeighbors, recommendMaxLikesPerUser)
+
+	items := make([]*model.Item, 0, len(all))
+	byID := make(map[string]*model.Item, len(all))
+	for _, item := range all {
+		if item.SoldOut {
+			continue
+		}
+		items = append(items, item)
+		byID[item.ID] = item
+	}
+
+	uc.mu.Lock()
+	uc.index = index
+	uc.items = items
+	uc.byID = byID
+	uc.mu.Unlock()
+	log.Printf("Recommendation index rebuilt: %d likes, %d items, %d candidates", len(likes), len(index), len(items))
+	return nil
+}
+
+// snapshot: Refresh で作った類似度と候補の商品一覧
+func (uc *RecommendationUsecase) snapshot() (recommend.Index, []*model.Item, map[string]*model.Item) {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.index, uc.items, uc.byID
+}
+
+// Recommend: ユーザーへのおすすめ商品（公開中・未購入・自分の出品といいね済み・ブロックのある出品者の商品を除く）
+// 共起によるおすすめが足りない分は人気順で埋める
+func (uc *RecommendationUsecase) Recommend(userID string, limit int) ([]*model.Item, error) {
+	var liked []string
+	if userID != "" {
+		var err error
+		if liked, err = uc.LikeDAO.GetLikedItemIDs(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	visible, err := uc.visibleTo(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	likedSet := make(map[string]bool, len(liked))
+	for _, id := range liked {
+		likedSet[id] = true
+	}
+	index, items, byID := uc.snapshot()
+	picked := map[string]bool{}
+	eligible := func(id string) bool {
+		item := byID[id]
+		return item != nil && !picked[id] && !likedSet[id] && visible(item)
+	}
+
+	result := []*model.Item{}
+	for _, n := range index.Recommend(liked, eligible, limit) {
+		result = append(result, byID[n.ItemID])
+		picked[n.ItemID] = true
+	}
+	if len(result) < limit {
+		result = append(result, recommend.Popular(items, eligible, limit-len(result))...)
+	}
+	return result, nil
+}
+
+// SimilarItems: 商品に似ている公開中の商品（カテゴリ・タグ、商品名・説明文、価格の近さで比べる）
+// userID を指定すると、自分の出品とブロックのある出品者の商品を除く
+func (uc *RecommendationUsecase) SimilarItems(itemID, userID string, limit int) ([]*model.Item, error) {
+	base, err := uc.ItemDAO.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil || base.Hidden {
+		return nil, ErrItemNotFound
+	}
+	visible, err := uc.visibleTo(userID)
+	if err != nil {
+		return nil, err
+	}
+	_, items, byID := uc.snapshot()
+
+	result := []*model.Item{}
+	for _, n := range recommend.Similar(base, items, visible, limit) {
+		result = append(result, byID[n.ItemID])
+	}
+	return result, nil
+}
+
+// visibleTo: userID に見せてよい商品か（自分の出品とブロックのある出品者の商品は除く。userID が空ならすべて）
+func (uc *RecommendationUsecase) visibleTo(userID string) (func(*model.Item) bool, error) {
+	blocked, err := uc.Blocks.BlockedWith(userID)
+	if err != nil {
+		return nil, err
+	}
+	return func(item *model.Item) bool {
+		return (userID == "" || item.SellerID != userID) && !blocked[item.SellerID]
+	}, nil
+}
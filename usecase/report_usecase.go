@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"time"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrTargetNotFound = errors.New("report target not found")
+)
+
+// ReportUsecase: ユーザーからの通報とモデレーションを担当
+type ReportUsecase struct {
+	ReportDAO *dao.ReportDAO
+	// UserDAO: 通報者が実在するユーザーかの確認に使う
+	UserDAO *dao.UserDAO
+	// HideThreshold: この人数から通報されたら確認が終わるまで非表示にする（0 なら自動では隠さない）
+	HideThreshold int
+}
+
+func NewReportUsecase(reportDAO *dao.ReportDAO, userDAO *dao.UserDAO, hideThreshold int) *ReportUsecase {
+	return &ReportUsecase{ReportDAO: reportDAO, UserDAO: userDAO, HideThreshold: hideThreshold}
+}
+
+// Report: 商品・メッセージ・ユーザーを通報する
+// 通報者は実在するユーザーに限る（system は自動審査の通報専用なので名乗れない）
+func (uc *ReportUsecase) Report(r *model.Report) (bool, error) {
+	if r.ReporterID == "" || r.ReporterID == model.SystemActorID {
+		return false, ErrNotAllowed
+	}
+	reporter, err := uc.UserDAO.GetUserByID(r.ReporterID)
+	if err != nil {
+		return false, err
+	}
+	if reporter == nil {
+		return false, ErrNotAllowed
+	}
+	if err := r.Validate(); err != nil {
+		return false, err
+	}
+	exists, err := uc.ReportDAO.TargetExists(r.TargetType, r.TargetID)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, ErrTargetNotFound
+	}
+
+	now := time.Now()
+	r.ID = model.NewID()
+	r.Status = model.ReportOpen
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	return uc.ReportDAO.Create(r, uc.HideThreshold)
+}
+
+// Moderate: モデレーターが通報を担当・解決・却下する
+func (uc *ReportUsecase) Moderate(reportID, moderatorID string, to model.ReportStatus, note string) (*model.Report, error) {
+	report, err := uc.ReportDAO.GetByID(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+	// 他の担当者が確認中の通報は横取りしない（同時に確認を始められた場合は DAO の更新条件で防ぐ）
+	if report.Status == model.ReportClaimed && report.ClaimedBy != moderatorID {
+		return nil, dao.ErrClaimedByOther
+	}
+
+	now := time.Now()
+	switch to {
+	case model.ReportClaimed:
+		err = uc.ReportDAO.Claim(report, moderatorID, note, now)
+	case model.ReportResolved, model.ReportDismissed:
+		err = uc.ReportDAO.Close(report, to, moderatorID, note, now)
+	default:
+		return nil, ErrNotAllowed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.ReportDAO.GetByID(report.ID)
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"errors"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"time"
+)
+
+// ErrOrderNotCompleted: 取引完了前は評価できない
+var ErrOrderNotCompleted = errors.New("order is not completed")
+
+// ReviewUsecase: 取引完了後の相互評価を担当
+type ReviewUsecase struct {
+	ReviewDAO *dao.ReviewDAO
+	OrderDAO  *dao.OrderDAO
+	// RevealAfter: 相手が評価しなくても自分の評価を公開するまでの期間
+	RevealAfter time.Duration
+}
+
+func NewReviewUsecase(reviewDAO *dao.ReviewDAO, orderDAO *dao.OrderDAO, revealAfter time.Duration) *ReviewUsecase {
+	return &ReviewUsecase{ReviewDAO: reviewDAO, OrderDAO: orderDAO, RevealAfter: revealAfter}
+}
+
+// Submit: 取引相手を評価する（取引完了から RevealAfter を過ぎたら締め切り）
+func (uc *ReviewUsecase) Submit(orderID, reviewerID string, rating int, comment string) (*model.Review, error) {
+	order, err := uc.OrderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if order.Status != model.OrderStatusCompleted {
+		return nil, ErrOrderNotCompleted
+	}
+	if !order.ReviewOpen(time.Now(), uc.RevealAfter) {
+		return nil, dao.ErrReviewClosed
+	}
+
+	review := &model.Review{
+		ID:         model.NewID(),
+		OrderID:    order.ID,
+		ReviewerID: reviewerID,
+		Rating:     rating,
+		Comment:    comment,
+		CreatedAt:  time.Now(),
+	}
+	switch order.RoleOf(reviewerID) {
+	case model.OrderActorBuyer:
+		review.ReviewerRole = model.OrderActorBuyer
+		review.RevieweeID = order.SellerID
+	case model.OrderActorSeller:
+		review.ReviewerRole = model.OrderActorSeller
+		review.RevieweeID = order.BuyerID
+	default:
+		return nil, ErrNotAllowed
+	}
+
+	if err := uc.ReviewDAO.Create(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListForOrder: 取引の評価を返す（相手の評価は公開後のみ）
+func (uc *ReviewUsecase) ListForOrder(orderID, userID string) ([]*model.Review, error) {
+	order, err := uc.OrderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if role := order.RoleOf(userID); role != model.OrderActorBuyer && role != model.OrderActorSeller {
+		return nil, ErrNotAllowed
+	}
+
+	reviews, err := uc.ReviewDAO.ListByOrder(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	visible := []*model.Review{}
+	for _, r := range reviews {
+		if r.ReviewerID == userID || r.RevealedAt != nil {
+			visible = append(visible, r)
+		}
+	}
+	return visible, nil
+}
+
+// RevealDue: 公開期限を過ぎた評価を公開する（定期実行用）
+func (uc *ReviewUsecase) RevealDue() (int, error) {
+	return uc.ReviewDAO.RevealDue(time.Now().Add(-uc.RevealAfter))
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"hackathon-backend/dao"
+	"hackathon-backend/model"
+	"time"
+)
+
+var (
+	ErrSavedSearchNotFound  = errors.New("saved search not found")
+	ErrTooManySavedSearches = errors.New("too many saved searches")
+)
+
+// savedSearchCursor: 新着照合ジョブの処理済み時刻の名前
+const savedSearchCursor = "saved_search_alerts"
+
+// savedSearchLookback: 公開時刻と処理時刻の前後で取りこぼさないよう、前回より少し前から照合し直す
+// 重複した通知は DedupKey で弾かれる
+const savedSearchLookback = time.Minute
+
+// SavedSearchUsecase: 保存した検索条件と新着通知を担当
+type SavedSearchUsecase struct {
+	SavedSearchDAO  *dao.SavedSearchDAO
+	NotificationDAO *dao.NotificationDAO
+	ItemDAO         *dao.ItemDAO
+	CursorDAO       *dao.JobCursorDAO
+	// MaxPerUser: 1人が保存できる検索条件の数
+	MaxPerUser int
+	// MaxPerHour: 1つの検索条件から1時間に送る通知の上限（超えた分は送らない）
+	MaxPerHour int
+}
+
+func NewSavedSearchUsecase(savedSearchDAO *dao.SavedSearchDAO, notificationDAO *dao.NotificationDAO, itemDAO *dao.ItemDAO,
+	cursorDAO *dao.JobCursorDAO, maxPerUser, maxPerHour int) *SavedSearchUsecase {
+	return &SavedSearchUsecase{
+		SavedSearchDAO:  savedSearchDAO,
+		NotificationDAO: notificationDAO,
+		ItemDAO:         itemDAO,
+		CursorDAO:       cursorDAO,
+		MaxPerUser:      maxPerUser,
+		MaxPerHour:      maxPerHour,
+	}
+}
+
+// Save: 検索条件を保存する
+func (uc *SavedSearchUsecase) Save(s *model.SavedSearch) error {
+	if s.UserID == "" {
+		return ErrNotAllowed
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	n, err := uc.SavedSearchDAO.CountByUser(s.UserID)
+	if err != nil {
+		return err
+	}
+	if n >= uc.MaxPerUser {
+		return ErrTooManySavedSearches
+	}
+
+	s.ID = model.NewID()
+	s.CreatedAt = time.Now()
+	return uc.SavedSearchDAO.Create(s)
+}
+
+// List: ユーザーの検索条件一覧
+func (uc *SavedSearchUsecase) List(userID string) ([]*model.SavedSearch, error) {
+	if userID == "" {
+		return nil, ErrNotAllowed
+	}
+	return uc.SavedSearchDAO.ListByUser(userID)
+}
+
+// Delete: 検索条件を削除する
+func (uc *SavedSearchUsecase) Delete(id, userID string) error {
+	ok, err := uc.SavedSearchDAO.Delete(id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// NotifyNewListings: 前回以降に公開された商品を検索条件と照合して通知する（定期実行用）
+// 初回は過去の商品を通知しないよう、処理済み時刻の記録だけ行う
+func (uc *SavedSearchUsecase) NotifyNewListings() (int, error) {
+	now := time.Now()
+	since, ok, err := uc.CursorDAO.Get(savedSearchCursor)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, uc.CursorDAO.Set(savedSearchCursor, now)
+	}
+
+	items, err := uc.ItemDAO.ListPublishedSince(since.Add(-savedSearchLookback))
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	if len(items) > 0 {
+		searches, err := uc.SavedSearchDAO.ListAll()
+		if err != nil {
+			return 0, err
+		}
+		for _, s := range searches {
+			n, err := uc.notifyMatches(s, items, now)
+			sent += n
+			if err != nil {
+				return sent, err
+			}
+		}
+	}
+	return sent, uc.CursorDAO.Set(savedSearchCursor, now)
+}
+
+// notifyMatches: 1つの検索条件について、一致した商品を上限まで通知する
+func (uc *SavedSearchUsecase) notifyMatches(s *model.SavedSearch, items []*model.Item, now time.Time) (int, error) {
+	budget := -1 // 一致があるまで送信数を数えない
+	sent := 0
+	for _, item := range items {
+		if !s.Matches(item) {
+			continue
+		}
+		if budget < 0 {
+			recent, err := uc.NotificationDAO.CountBySourceSince(s.ID, now.Add(-time.Hour))
+			if err != nil {
+				return sent, err
+			}
+			budget = uc.MaxPerHour - recent
+		}
+		if budget <= 0 {
+			break
+		}
+
+		label := s.Keyword
+		if label == "" {
+			label = fmt.Sprintf("¥%d〜¥%d", s.MinPrice, s.MaxPrice)
+		}
+		created, err := uc.NotificationDAO.Create(&model.Notification{
+			ID:        model.NewID(),
+			UserID:    s.UserID,
+			Type:      model.NotifySavedSearch,
+			ItemID:    item.ID,
+			SourceID:  s.ID,
+			Message:   fmt.Sprintf("「%s」に一致する商品が出品されました: %s", label, item.Name),
+			DedupKey:  "item:" + item.ID, // 複数の検索条件に一致しても通知は1件
+			CreatedAt: now,
+		})
+		if err != nil {
+			return sent, err
+		}
+		if created {
+			sent++
+			budget--
+		}
+	}
+	return sent, nil
+}